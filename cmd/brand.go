@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"ecommerce/config"
+	BrandRepository "ecommerce/internal/domain/brand/repository"
+	BrandUseCase "ecommerce/internal/domain/brand/usecase"
+	"ecommerce/pkg/searchindex"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var brandCmd = &cobra.Command{
+	Use:   "brand",
+	Short: "Brand maintenance commands",
+}
+
+var brandDuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Report brands sharing the same normalized name",
+	Long:  "Report non-deleted brands whose names collide once trimmed and case-folded. These must be resolved before the unique name index can be created.",
+	Run:   runBrandDuplicatesCommand,
+}
+
+func init() {
+	brandCmd.AddCommand(brandDuplicatesCmd)
+	rootCmd.AddCommand(brandCmd)
+}
+
+func runBrandDuplicatesCommand(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	config.InitializeDatabase(config.AppConfig, logger)
+
+	// the report only reads brands, so the search index is left closed
+	repository := BrandRepository.NewBrandRepository(ctx, config.DatabaseProvider, logger)
+	useCase := BrandUseCase.NewBrandUseCase(repository, searchindex.NopIndex{}, nil)
+	duplicates, err := useCase.FindDuplicateNames()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if len(duplicates) == 0 {
+		fmt.Println("✅ No duplicate brand names found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NORMALIZED NAME\tCOUNT\tBRAND IDS")
+	for _, d := range duplicates {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", d.NormalizedName, d.Total, strings.Join(d.Ids, ", "))
+	}
+	w.Flush()
+}
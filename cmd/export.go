@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"ecommerce/config"
+	ProductDeps "ecommerce/internal/domain/product"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/export"
+	ValidatorUtils "ecommerce/pkg/validator"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Data export commands",
+}
+
+var exportProductsCmd = &cobra.Command{
+	Use:   "products <file>",
+	Short: "Export products to a CSV, NDJSON or XLSX file",
+	Long:  "Write every product with its brand name and tags to a file the same way as GET /products/export. The format follows the file extension unless --format is given, and --query takes the filter and sort params of the product list, e.g. --query 'brand_id=2&price[gte]=1000&sort=name'.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runExportProductsCommand,
+}
+
+var (
+	exportFormat string
+	exportQuery  string
+)
+
+func init() {
+	exportProductsCmd.Flags().StringVar(&exportFormat, "format", "", "csv, ndjson or xlsx (default from the file extension)")
+	exportProductsCmd.Flags().StringVar(&exportQuery, "query", "", "filter and sort params of the product list as a query string")
+	exportCmd.AddCommand(exportProductsCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportProductsCommand(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	path := args[0]
+	format := exportFormat
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	values, err := url.ParseQuery(exportQuery)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	params, err := dto.ProductQuery(values)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if errs := ValidatorUtils.NewRequestValidator().Fields(&params.ProductFilterDTO); errs != nil {
+		logger.Error("invalid query", "errors", errs)
+		os.Exit(1)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	writer, err := export.NewWriter(format, file)
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	config.InitializeDatabase(config.AppConfig, logger)
+
+	total, err := ProductDeps.NewProductUseCase(ctx, config.DatabaseProvider, logger).Export(params, writer)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Exported %d products to %s\n", total, path)
+}
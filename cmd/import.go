@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/constants"
+	ProductDeps "ecommerce/internal/domain/product"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/bulk"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Data import commands",
+}
+
+var importProductsCmd = &cobra.Command{
+	Use:   "products <file.csv>",
+	Short: "Import products from a CSV file",
+	Long:  "Create and update products from a CSV file the same way as POST /products/import. Columns named after a product field are imported and --map renames other columns; rows with an id update that product and the others are created. The imported products are written to the search index at SEARCH_INDEX_PATH, which the API holds an exclusive lock on, so stop the API first; to import while the API runs, use POST /products/import instead.",
+	Args:  cobra.ExactArgs(1),
+	Run:   runImportProductsCommand,
+}
+
+var (
+	importDryRun       bool
+	importCreateBrands bool
+	importMode         string
+	importMapping      []string
+)
+
+func init() {
+	importProductsCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "validate the rows without writing them")
+	importProductsCmd.Flags().BoolVar(&importCreateBrands, "create-brands", false, "create brands that are not found by name")
+	importProductsCmd.Flags().StringVar(&importMode, "mode", "", "atomic or best_effort (default from BULK_DEFAULT_MODE)")
+	importProductsCmd.Flags().StringArrayVar(&importMapping, "map", nil, "column mapping as header=field, e.g. --map Title=name")
+	importCmd.AddCommand(importProductsCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImportProductsCommand(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	payload := &dto.ImportProductsDTO{
+		Mode:         importMode,
+		DryRun:       importDryRun,
+		CreateBrands: importCreateBrands,
+		Mapping:      make(map[string]string),
+	}
+
+	if payload.Mode == "" {
+		payload.Mode = config.AppConfig.BulkDefaultMode
+	}
+	if payload.Mode == "" {
+		payload.Mode = constants.DefaultBulkMode
+	}
+	if payload.Mode != bulk.ModeAtomic && payload.Mode != bulk.ModeBestEffort {
+		logger.Error("mode must be atomic or best_effort")
+		os.Exit(1)
+	}
+
+	for _, pair := range importMapping {
+		column, field, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(column) == "" {
+			logger.Error("map must be given as header=field", "map", pair)
+			os.Exit(1)
+		}
+		payload.Mapping[strings.TrimSpace(column)] = strings.TrimSpace(field)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	defer file.Close()
+	payload.File = file
+
+	config.InitializeDatabase(config.AppConfig, logger)
+	// while the API holds the index the import goes through the API instead,
+	// so that the index does not miss the imported products
+	if err := config.InitializeSearchIndex(config.AppConfig, logger); err != nil {
+		logger.Error("search index is in use, import with POST /products/import instead", "error", err.Error())
+		os.Exit(1)
+	}
+	defer config.SearchIndexProvider.Close()
+
+	report, err := ProductDeps.NewProductUseCase(ctx, config.DatabaseProvider, logger).Import(payload)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if report.Failed > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LINE\tOP\tSTATUS\tERRORS")
+		for _, result := range report.Results {
+			if result.Status == bulk.StatusFailed {
+				errs, _ := json.Marshal(result.Errors)
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", result.Line, result.Op, result.Status, errs)
+			}
+		}
+		w.Flush()
+	}
+
+	if len(report.NewBrands) > 0 {
+		fmt.Printf("New brands: %s\n", strings.Join(report.NewBrands, ", "))
+	}
+
+	if report.DryRun {
+		fmt.Printf("Dry run: %d valid, %d failed\n", report.Valid, report.Failed)
+	} else {
+		fmt.Printf("Imported %d products, %d failed\n", report.Succeeded, report.Failed)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
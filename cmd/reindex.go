@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"ecommerce/config"
+	BrandRepository "ecommerce/internal/domain/brand/repository"
+	BrandUseCase "ecommerce/internal/domain/brand/usecase"
+	ProductDeps "ecommerce/internal/domain/product"
+	"ecommerce/pkg/searchindex"
+	"fmt"
+	"github.com/spf13/cobra"
+	"log/slog"
+	"os"
+)
+
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Rebuild the search index from the database",
+	Long:  "Rebuild the embedded search index at SEARCH_INDEX_PATH from the brands and products in the database. The index is built next to the current one and swapped in once complete. The API holds an exclusive lock on the index, so stop it first; to reindex while the API runs, queue the job with POST /search/reindex instead.",
+	Run:   runReindexCommand,
+}
+
+func init() {
+	rootCmd.AddCommand(reindexCmd)
+}
+
+func runReindexCommand(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+
+	path := config.AppConfig.SearchIndexPath
+	if path == "" {
+		logger.Error("SEARCH_INDEX_PATH is not set")
+		os.Exit(1)
+	}
+
+	// opening the current index fails while another process holds its lock
+	if _, err := os.Stat(path); err == nil {
+		current, err := searchindex.OpenBleveIndex(path, logger)
+		if err != nil {
+			logger.Error("search index is in use, stop the API first", "error", err.Error())
+			os.Exit(1)
+		}
+		current.Close()
+	}
+
+	config.InitializeDatabase(config.AppConfig, logger)
+
+	rebuildPath := path + ".rebuild"
+	index, err := searchindex.CreateBleveIndex(rebuildPath, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	config.SearchIndexProvider = index
+
+	brandUseCase := BrandUseCase.NewBrandUseCase(BrandRepository.NewBrandRepository(ctx, config.DatabaseProvider, logger), index, nil)
+	brands, err := brandUseCase.Reindex()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	productUseCase := ProductDeps.NewProductUseCase(ctx, config.DatabaseProvider, logger)
+	products, err := productUseCase.Reindex()
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if err := index.Close(); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if err := os.Rename(rebuildPath, path); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Indexed %d brands and %d products\n", brands, products)
+}
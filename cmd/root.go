@@ -6,10 +6,12 @@ import (
 	"ecommerce/common"
 	"ecommerce/config"
 	"ecommerce/docs"
+	JobDeps "ecommerce/internal/domain/job"
+	ProductDeps "ecommerce/internal/domain/product"
+	SearchDeps "ecommerce/internal/domain/search"
 	ValidatorUtils "ecommerce/pkg/validator"
 	"errors"
 	"fmt"
-	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/spf13/cobra"
@@ -43,8 +45,13 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	config.InitializeDatabase(config.AppConfig, logger)
+	if err := config.InitializeSearchIndex(config.AppConfig, logger); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 	e := echo.New()
-	e.Validator = &ValidatorUtils.RequestValidator{Validator: validator.New()}
+	e.Validator = ValidatorUtils.NewRequestValidator()
+	e.HTTPErrorHandler = common.NewHTTPErrorHandler(logger)
 
 	e.Use(middleware.Recover())
 	e.Use(middleware.Logger())
@@ -63,6 +70,11 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 	e.GET("/docs/*", echoSwagger.WrapHandler)
 	common.RegisterRoute(e, ctx, config.DatabaseProvider, logger)
 
+	worker := JobDeps.NewJobWorker(ctx, config.DatabaseProvider, logger)
+	ProductDeps.RegisterJobs(worker, config.DatabaseProvider, logger)
+	SearchDeps.RegisterJobs(worker, config.DatabaseProvider, logger)
+	worker.Start()
+
 	go func() {
 		if err := e.Start(":8080"); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			e.Logger.Fatal("❌ Echo start error:", err)
@@ -81,6 +93,13 @@ func runRootCommand(cmd *cobra.Command, args []string) {
 		e.Logger.Fatal("❌ Shutdown error:", err)
 	}
 
+	// running jobs are put back in the queue for the next start
+	worker.Stop()
+
+	if err := config.SearchIndexProvider.Close(); err != nil {
+		logger.Error(err.Error())
+	}
+
 	fmt.Println("✅ Server stopped cleanly")
 }
 
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/jsonapi"
+	HttpResponser "ecommerce/pkg/response"
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var kindStatus = map[apperror.Kind]int{
+	apperror.KindInternal:             http.StatusInternalServerError,
+	apperror.KindValidation:           http.StatusBadRequest,
+	apperror.KindForbidden:            http.StatusForbidden,
+	apperror.KindNotFound:             http.StatusNotFound,
+	apperror.KindConflict:             http.StatusConflict,
+	apperror.KindGone:                 http.StatusGone,
+	apperror.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperror.KindUnprocessable:        http.StatusUnprocessableEntity,
+	apperror.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperror.KindUnavailable:          http.StatusServiceUnavailable,
+	apperror.KindNotAcceptable:        http.StatusNotAcceptable,
+}
+
+// NewHTTPErrorHandler renders the errors returned by handlers and middleware
+// as application/problem+json. Domain errors keep their code, Echo errors are
+// coded after their status and database errors are translated; anything else
+// is a 500 whose cause is logged but not sent.
+func NewHTTPErrorHandler(logger *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		problem := toProblem(err)
+		problem.Instance = c.Request().URL.Path
+		if problem.Status >= http.StatusInternalServerError {
+			logger.Error(err.Error(), "method", c.Request().Method, "path", problem.Instance)
+		}
+
+		// clients that asked for JSON:API get its error objects instead
+		var body interface{} = problem
+		contentType := HttpResponser.MIMEApplicationProblemJSON
+		if accepted, _ := jsonapi.Accepts(c.Request().Header.Get(echo.HeaderAccept)); accepted {
+			body = jsonapi.NewErrorDocument(&jsonapi.Error{
+				Status: strconv.Itoa(problem.Status),
+				Code:   problem.Code,
+				Title:  problem.Title,
+				Detail: problem.Detail,
+				Meta:   problem.Details,
+			})
+			contentType = jsonapi.MediaType
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, contentType)
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(problem.Status)
+		} else {
+			err = c.JSON(problem.Status, body)
+		}
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+func toProblem(err error) *HttpResponser.Problem {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		// a domain error may be wrapped with more context for the client
+		detail := err.Error()
+		if appErr.Kind == apperror.KindInternal {
+			detail = appErr.Message
+		}
+		return newProblem(appErr, detail)
+	}
+
+	if dbErr := apperror.FromDatabase(err); dbErr != nil {
+		return newProblem(dbErr, dbErr.Message)
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		detail := fmt.Sprint(httpErr.Message)
+		if httpErr.Code >= http.StatusInternalServerError {
+			detail = ""
+		}
+		return HttpResponser.NewProblem(httpErr.Code, statusCode(httpErr.Code), detail)
+	}
+
+	return HttpResponser.NewProblem(http.StatusInternalServerError, apperror.CodeInternal, "")
+}
+
+func newProblem(appErr *apperror.Error, detail string) *HttpResponser.Problem {
+	problem := HttpResponser.NewProblem(kindStatus[appErr.Kind], appErr.Code, detail)
+	problem.Details = appErr.Details()
+	return problem
+}
+
+// statusCode names an error code after a status, e.g. method_not_allowed.
+func statusCode(status int) string {
+	return strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
@@ -3,41 +3,130 @@ package common
 import (
 	"context"
 	"ecommerce/config"
+	"ecommerce/constants"
+	"ecommerce/pkg/apiversion"
 	"github.com/labstack/echo/v4"
 	"log/slog"
+	"time"
 
 	BrandDeps "ecommerce/internal/domain/brand"
 	Brand "ecommerce/internal/domain/brand/presenter"
 
+	CollectionDeps "ecommerce/internal/domain/collection"
+	Collection "ecommerce/internal/domain/collection/presenter"
+
+	IdempotencyDeps "ecommerce/internal/domain/idempotency"
+
+	JobDeps "ecommerce/internal/domain/job"
+	Job "ecommerce/internal/domain/job/presenter"
+
 	ProductDeps "ecommerce/internal/domain/product"
 	Product "ecommerce/internal/domain/product/presenter"
+
+	SearchDeps "ecommerce/internal/domain/search"
+	Search "ecommerce/internal/domain/search/presenter"
+
+	TaxDeps "ecommerce/internal/domain/tax"
+	Tax "ecommerce/internal/domain/tax/presenter"
 )
 
 var (
-	brandPresenter   Brand.IBrandPresenter
-	productPresenter Product.IProductPresenter
+	brandPresenter      Brand.IBrandPresenter
+	productPresenter    Product.IProductPresenter
+	collectionPresenter Collection.ICollectionPresenter
+	taxPresenter        Tax.ITaxPresenter
+	searchPresenter     Search.ISearchPresenter
+	jobPresenter        Job.IJobPresenter
 )
 
 func RegisterRoute(c *echo.Echo, ctx context.Context, databaseProvider *config.DatabaseConfiguration, logger *slog.Logger) {
 	initializePresenter(ctx, databaseProvider, logger)
-	api := c.Group("api/v1")
+	idempotency := IdempotencyDeps.NewIdempotencyDependency(ctx, databaseProvider, logger)
+
+	// both versions share the presenters; they differ in the envelope the
+	// responses are written in, see response.Success
+	c.Pre(apiversion.Negotiate(constants.APIPath, []string{apiversion.V1, apiversion.V2}, apiversion.Latest))
+
+	v1 := c.Group(constants.APIPath + "/" + apiversion.V1)
+	v1.Use(
+		apiversion.Use(apiversion.V1),
+		apiversion.Deprecate(apiversion.V1, apiversion.V2, apiDate(config.AppConfig.APIV1DeprecatedAt, constants.DefaultAPIV1DeprecatedAt), apiDate(config.AppConfig.APIV1Sunset, constants.DefaultAPIV1Sunset)),
+		apiversion.RenameParams(map[string]string{"PerPage": "per_page", "Page": "page", "Search": "search"}),
+		idempotency,
+	)
+	registerResources(v1)
+
+	v2 := c.Group(constants.APIPath + "/" + apiversion.V2)
+	v2.Use(apiversion.Use(apiversion.V2), idempotency)
+	registerResources(v2)
+}
 
+func registerResources(api *echo.Group) {
 	brandRoute := api.Group("/brands")
 	brandRoute.GET("", brandPresenter.GetAll)
 	brandRoute.GET("/:id", brandPresenter.Get)
+	brandRoute.GET("/:id/children", brandPresenter.GetChildren)
 	brandRoute.POST("", brandPresenter.Create)
+	brandRoute.POST("/bulk", brandPresenter.Bulk)
 	brandRoute.PATCH("/:id", brandPresenter.Update)
 	brandRoute.DELETE("/:id", brandPresenter.Delete)
 
 	productRoute := api.Group("/products")
 	productRoute.GET("", productPresenter.GetAll)
+	productRoute.GET("/facets", productPresenter.GetFacets)
+	productRoute.GET("/search", productPresenter.Search)
+	productRoute.GET("/export", productPresenter.Export)
+	productRoute.POST("/export", productPresenter.ExportAsync)
+	productRoute.GET("/by-barcode/:code", productPresenter.GetByBarcode)
 	productRoute.GET("/:id", productPresenter.Get)
 	productRoute.POST("", productPresenter.Create)
+	productRoute.POST("/bulk", productPresenter.Bulk)
+	productRoute.POST("/import", productPresenter.Import)
 	productRoute.PATCH("/:id", productPresenter.Update)
 	productRoute.DELETE("/:id", productPresenter.Delete)
+	productRoute.POST("/:id/files", productPresenter.UploadFile)
+	productRoute.POST("/:id/files/:fileId/download-link", productPresenter.CreateDownloadLink)
+
+	api.GET("/downloads/:fileId", productPresenter.Download)
+
+	collectionRoute := api.Group("/collections")
+	collectionRoute.GET("", collectionPresenter.GetAll)
+	collectionRoute.GET("/:id", collectionPresenter.Get)
+	collectionRoute.GET("/:id/products", collectionPresenter.GetProducts)
+	collectionRoute.POST("", collectionPresenter.Create)
+	collectionRoute.PATCH("/:id", collectionPresenter.Update)
+	collectionRoute.DELETE("/:id", collectionPresenter.Delete)
+
+	taxRoute := api.Group("/tax-classes")
+	taxRoute.GET("", taxPresenter.GetAll)
+	taxRoute.POST("", taxPresenter.Create)
+	taxRoute.PUT("/:id/rates/:region", taxPresenter.UpsertRate)
+
+	searchRoute := api.Group("/search")
+	searchRoute.GET("/suggest", searchPresenter.Suggest)
+	searchRoute.POST("/reindex", searchPresenter.Reindex)
+
+	jobRoute := api.Group("/jobs")
+	jobRoute.GET("/:id", jobPresenter.Get)
+	jobRoute.POST("/:id/cancel", jobPresenter.Cancel)
+	jobRoute.GET("/:id/result", jobPresenter.GetResult)
 }
 
 func initializePresenter(ctx context.Context, databaseProvider *config.DatabaseConfiguration, logger *slog.Logger) {
 	brandPresenter = BrandDeps.NewBrandDependency(ctx, databaseProvider, logger)
 	productPresenter = ProductDeps.NewProductDependency(ctx, databaseProvider, logger)
+	collectionPresenter = CollectionDeps.NewCollectionDependency(ctx, databaseProvider, logger)
+	taxPresenter = TaxDeps.NewTaxDependency(ctx, databaseProvider, logger)
+	searchPresenter = SearchDeps.NewSearchDependency(ctx, databaseProvider, logger)
+	jobPresenter = JobDeps.NewJobDependency(ctx, databaseProvider, logger)
+}
+
+// apiDate parses a configured YYYY-MM-DD date, falling back to fallback when
+// it is not set or does not parse.
+func apiDate(value string, fallback string) time.Time {
+	if date, err := time.Parse(constants.APIDateLayout, value); err == nil {
+		return date
+	}
+	date, _ := time.Parse(constants.APIDateLayout, fallback)
+	return date
 }
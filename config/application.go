@@ -3,12 +3,57 @@ package config
 import (
 	"fmt"
 	"github.com/spf13/viper"
+	"time"
 )
 
 type ApplicationConfiguration struct {
 	PostgresDsn     string `mapstructure:"POSTGRES_DSN"`
 	ApplicationHost string `mapstructure:"APPLICATION_HOST"`
 	ApplicationPort string `mapstructure:"APPLICATION_PORT"`
+
+	// TaxDefaultRegion is used when a request does not select a tax region.
+	TaxDefaultRegion string `mapstructure:"TAX_DEFAULT_REGION"`
+	// PricesIncludeTax tells whether stored product prices are gross (VAT
+	// inclusive) or net amounts.
+	PricesIncludeTax bool `mapstructure:"PRICES_INCLUDE_TAX"`
+
+	// StorageLocalPath is the directory downloadable product files are kept in.
+	StorageLocalPath string `mapstructure:"STORAGE_LOCAL_PATH"`
+	// DownloadSigningSecret signs expiring download links.
+	DownloadSigningSecret string        `mapstructure:"DOWNLOAD_SIGNING_SECRET"`
+	DownloadLinkTTL       time.Duration `mapstructure:"DOWNLOAD_LINK_TTL"`
+
+	// SearchSuggestTimeout is the latency budget of a search suggestion lookup.
+	SearchSuggestTimeout time.Duration `mapstructure:"SEARCH_SUGGEST_TIMEOUT"`
+	// SearchIndexPath is the directory of the embedded search index; the index
+	// is disabled when empty.
+	SearchIndexPath string `mapstructure:"SEARCH_INDEX_PATH"`
+
+	// BulkDefaultMode is the mode, atomic or best_effort, of bulk requests that
+	// do not choose one.
+	BulkDefaultMode string `mapstructure:"BULK_DEFAULT_MODE"`
+
+	// RequireIfMatch refuses updates and deletes of products and brands that
+	// do not send the ETag they were read with in If-Match.
+	RequireIfMatch bool `mapstructure:"REQUIRE_IF_MATCH"`
+
+	// IdempotencyKeyTTL is how long the response of a write sent with an
+	// Idempotency-Key is replayed to retries.
+	IdempotencyKeyTTL time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+
+	// JobWorkers is the number of background jobs run at the same time.
+	JobWorkers int `mapstructure:"JOB_WORKERS"`
+	// JobPollInterval is how often an idle worker looks for a due job.
+	JobPollInterval time.Duration `mapstructure:"JOB_POLL_INTERVAL"`
+	// JobMaxAttempts and JobRetryBackoff bound the retries of a failing job;
+	// the backoff doubles on every retry.
+	JobMaxAttempts  int           `mapstructure:"JOB_MAX_ATTEMPTS"`
+	JobRetryBackoff time.Duration `mapstructure:"JOB_RETRY_BACKOFF"`
+
+	// APIV1DeprecatedAt and APIV1Sunset date the deprecation and removal of
+	// v1 as YYYY-MM-DD, announced in the headers of every v1 response.
+	APIV1DeprecatedAt string `mapstructure:"API_V1_DEPRECATED_AT"`
+	APIV1Sunset       string `mapstructure:"API_V1_SUNSET"`
 }
 
 var AppConfig *ApplicationConfiguration
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"ecommerce/pkg/searchindex"
+	"fmt"
+	"log/slog"
+)
+
+// SearchIndexProvider is a NopIndex until InitializeSearchIndex opens the
+// index configured by SEARCH_INDEX_PATH.
+var SearchIndexProvider searchindex.IIndex = searchindex.NopIndex{}
+
+// InitializeSearchIndex opens the configured index. A configured index that
+// cannot be opened, e.g. because another process holds its lock, is an error
+// rather than a silently disabled search.
+func InitializeSearchIndex(appConfig *ApplicationConfiguration, slog *slog.Logger) error {
+	if appConfig.SearchIndexPath == "" {
+		return nil
+	}
+
+	index, err := searchindex.OpenBleveIndex(appConfig.SearchIndexPath, slog)
+	if err != nil {
+		return fmt.Errorf("open search index %s: %w", appConfig.SearchIndexPath, err)
+	}
+
+	SearchIndexProvider = index
+	return nil
+}
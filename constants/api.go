@@ -0,0 +1,25 @@
+package constants
+
+const (
+	// APIPath is the path every version of the API is mounted under, e.g.
+	// /api/v2.
+	APIPath = "/api"
+
+	// LatestAPIPath is where links handed out in responses point, so they
+	// outlive deprecated versions. Files and jobs are served the same way by
+	// every version.
+	LatestAPIPath = APIPath + "/v2"
+
+	// DefaultAPIV1DeprecatedAt is when v1 was deprecated in favour of v2 when
+	// API_V1_DEPRECATED_AT is not set.
+	DefaultAPIV1DeprecatedAt = "2026-10-19"
+
+	// DefaultAPIV1Sunset is when v1 is removed when API_V1_SUNSET is not set.
+	DefaultAPIV1Sunset = "2027-10-19"
+
+	// APIDateLayout is the layout of the API_V1_* dates.
+	APIDateLayout = "2006-01-02"
+)
+
+// DownloadsPath is the API path signed download links point at.
+const DownloadsPath = LatestAPIPath + "/downloads"
@@ -0,0 +1,7 @@
+package constants
+
+const (
+	// MaxBrandDepth bounds recursive brand hierarchy queries so a corrupted
+	// parent chain can never loop forever.
+	MaxBrandDepth int = 32
+)
@@ -0,0 +1,5 @@
+package constants
+
+// DefaultBulkMode applies to bulk requests without a mode when
+// BULK_DEFAULT_MODE is not set; bulk requests are all-or-nothing.
+const DefaultBulkMode = "atomic"
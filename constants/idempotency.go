@@ -0,0 +1,33 @@
+package constants
+
+import "time"
+
+const (
+	// DefaultIdempotencyKeyTTL is how long a stored response is replayed when
+	// IDEMPOTENCY_KEY_TTL is not set.
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+	// IdempotencyLockTimeout is how long a request may go without renewing
+	// the lock of its key before a retry takes it over, e.g. after the
+	// instance handling it went down.
+	IdempotencyLockTimeout = time.Minute
+
+	// IdempotencyHeartbeatInterval is how often a running request renews the
+	// lock of its key.
+	IdempotencyHeartbeatInterval = IdempotencyLockTimeout / 4
+
+	// IdempotencyPurgeInterval is how often expired keys are deleted.
+	IdempotencyPurgeInterval = time.Hour
+
+	// IdempotencyKeyMaxLength bounds the Idempotency-Key header.
+	IdempotencyKeyMaxLength = 255
+
+	// IdempotencyMaxBodySize bounds the body of a request sent with an
+	// Idempotency-Key, which is read in full to fingerprint it: the largest
+	// product file and room for its multipart envelope.
+	IdempotencyMaxBodySize = MaxProductFileSize + 1<<20
+
+	// IdempotencyBodyMemory is how much of a fingerprinted body is kept in
+	// memory; the rest is spooled to a temporary file.
+	IdempotencyBodyMemory = 1 << 20
+)
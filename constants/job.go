@@ -0,0 +1,34 @@
+package constants
+
+import "time"
+
+const (
+	// DefaultJobWorkers is the number of jobs run at the same time when
+	// JOB_WORKERS is not set.
+	DefaultJobWorkers int = 2
+
+	// DefaultJobPollInterval is how often an idle worker looks for a due job
+	// when JOB_POLL_INTERVAL is not set.
+	DefaultJobPollInterval = time.Second
+
+	// DefaultJobMaxAttempts is how often a failing job is run when
+	// JOB_MAX_ATTEMPTS is not set.
+	DefaultJobMaxAttempts int = 3
+
+	// DefaultJobRetryBackoff is the delay before the first retry of a failed
+	// job when JOB_RETRY_BACKOFF is not set; it doubles on every retry.
+	DefaultJobRetryBackoff = 10 * time.Second
+)
+
+const (
+	// JobHeartbeatInterval is how often a running job saves its progress and
+	// checks whether it was cancelled.
+	JobHeartbeatInterval = 2 * time.Second
+
+	// JobLeaseTimeout is how long a running job may go without a heartbeat
+	// before it is considered abandoned and queued again.
+	JobLeaseTimeout = time.Minute
+)
+
+// JobsPath is the API path job results are linked under.
+const JobsPath = LatestAPIPath + "/jobs"
@@ -0,0 +1,6 @@
+package constants
+
+const (
+	// DefaultCursorLimit applies to cursor pages requested without a limit.
+	DefaultCursorLimit int64 = 10
+)
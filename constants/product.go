@@ -0,0 +1,28 @@
+package constants
+
+import "time"
+
+const (
+	// VolumetricDivisor is the courier divisor (cm³ per kg) used to derive
+	// volumetric weight from package dimensions.
+	VolumetricDivisor float64 = 5000
+
+	// MeasurementPrecision is the number of decimals measurements are
+	// rendered with.
+	MeasurementPrecision int = 3
+)
+
+const (
+	// MaxProductFileSize caps a single downloadable product file upload.
+	MaxProductFileSize int64 = 512 << 20
+
+	// DefaultDownloadLinkTTL applies when DOWNLOAD_LINK_TTL is not set.
+	DefaultDownloadLinkTTL = 15 * time.Minute
+)
+
+// MaxImportRows caps the rows of a single product import.
+const MaxImportRows int = 10000
+
+// PriceFacetBuckets is the number of equal-width buckets the price histogram
+// of the product facets is split into.
+const PriceFacetBuckets int = 5
@@ -0,0 +1,29 @@
+package constants
+
+import "time"
+
+const (
+	// SuggestMinQueryLength is the shortest query, in characters, suggestions
+	// are looked up for.
+	SuggestMinQueryLength int = 2
+
+	// DefaultSuggestLimit and MaxSuggestLimit bound the number of suggestions
+	// returned for a query.
+	DefaultSuggestLimit int = 8
+	MaxSuggestLimit     int = 20
+
+	// SuggestSimilarityThreshold is the minimum pg_trgm word similarity for a
+	// name that does not start with the query.
+	SuggestSimilarityThreshold float64 = 0.4
+
+	// DefaultSuggestTimeout applies when SEARCH_SUGGEST_TIMEOUT is not set.
+	DefaultSuggestTimeout = 150 * time.Millisecond
+)
+
+// ReindexBatchSize is the number of records read and indexed at a time when
+// the search index is rebuilt.
+const ReindexBatchSize int = 500
+
+// SearchFacetSize is the number of most frequent values counted per facet of
+// a product search.
+const SearchFacetSize int = 20
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS brands_normalized_name_unique;
@@ -0,0 +1,7 @@
+UPDATE brands
+SET name = btrim(name)
+WHERE name <> btrim(name);
+
+CREATE UNIQUE INDEX brands_normalized_name_unique
+    ON brands (lower(btrim(name)))
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS brands_parent_id_index;
+
+ALTER TABLE brands
+    DROP CONSTRAINT IF EXISTS chk_brand_parent_not_self,
+    DROP CONSTRAINT IF EXISTS fk_brand_parent,
+    DROP COLUMN IF EXISTS parent_id;
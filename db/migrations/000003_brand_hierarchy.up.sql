@@ -0,0 +1,6 @@
+ALTER TABLE brands
+    ADD COLUMN parent_id INTEGER DEFAULT NULL,
+    ADD CONSTRAINT fk_brand_parent FOREIGN KEY (parent_id) REFERENCES brands (id),
+    ADD CONSTRAINT chk_brand_parent_not_self CHECK (parent_id IS NULL OR parent_id <> id);
+
+CREATE INDEX brands_parent_id_index ON brands (parent_id) WHERE deleted_at IS NULL;
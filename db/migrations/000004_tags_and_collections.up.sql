@@ -0,0 +1,34 @@
+CREATE TABLE product_tags
+(
+    product_id INTEGER     NOT NULL,
+    tag        varchar(50) NOT NULL,
+    PRIMARY KEY (product_id, tag),
+    CONSTRAINT fk_product_tag_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX product_tags_tag_index ON product_tags (tag);
+
+CREATE TABLE collections
+(
+    id         serial PRIMARY KEY,
+    name       varchar(100) NOT NULL,
+    type       varchar(10)  NOT NULL,
+    rule       jsonb     default null,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    deleted_at timestamp default null,
+    CONSTRAINT chk_collection_type CHECK (type IN ('manual', 'smart')),
+    CONSTRAINT chk_collection_smart_rule CHECK (type <> 'smart' OR rule IS NOT NULL)
+);
+
+CREATE TABLE collection_products
+(
+    collection_id INTEGER NOT NULL,
+    product_id    INTEGER NOT NULL,
+    position      INTEGER NOT NULL,
+    PRIMARY KEY (collection_id, product_id),
+    CONSTRAINT fk_collection_product_collection FOREIGN KEY (collection_id) REFERENCES collections (id) ON DELETE CASCADE,
+    CONSTRAINT fk_collection_product_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+
+CREATE INDEX collection_products_position_index ON collection_products (collection_id, position);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS products_barcode_unique;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS barcode;
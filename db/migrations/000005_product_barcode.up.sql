@@ -0,0 +1,6 @@
+ALTER TABLE products
+    ADD COLUMN barcode varchar(14) DEFAULT NULL;
+
+CREATE UNIQUE INDEX products_barcode_unique
+    ON products (lpad(barcode, 14, '0'))
+    WHERE barcode IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS chk_product_content_unit,
+    DROP COLUMN IF EXISTS content_unit,
+    DROP COLUMN IF EXISTS content_amount,
+    DROP COLUMN IF EXISTS height_cm,
+    DROP COLUMN IF EXISTS width_cm,
+    DROP COLUMN IF EXISTS length_cm,
+    DROP COLUMN IF EXISTS weight_grams;
@@ -0,0 +1,8 @@
+ALTER TABLE products
+    ADD COLUMN weight_grams   numeric(12, 3) DEFAULT NULL,
+    ADD COLUMN length_cm      numeric(10, 2) DEFAULT NULL,
+    ADD COLUMN width_cm       numeric(10, 2) DEFAULT NULL,
+    ADD COLUMN height_cm      numeric(10, 2) DEFAULT NULL,
+    ADD COLUMN content_amount numeric(12, 3) DEFAULT NULL,
+    ADD COLUMN content_unit   varchar(2)     DEFAULT NULL,
+    ADD CONSTRAINT chk_product_content_unit CHECK (content_unit IS NULL OR content_unit IN ('g', 'ml'));
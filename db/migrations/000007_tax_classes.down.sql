@@ -0,0 +1,6 @@
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS fk_product_tax_class,
+    DROP COLUMN IF EXISTS tax_class_id;
+
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS tax_classes;
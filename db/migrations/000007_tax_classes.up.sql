@@ -0,0 +1,36 @@
+CREATE TABLE tax_classes
+(
+    id         serial PRIMARY KEY,
+    code       varchar(50)  NOT NULL,
+    name       varchar(100) NOT NULL,
+    created_at timestamp not null,
+    updated_at timestamp not null,
+    deleted_at timestamp default null
+);
+
+CREATE UNIQUE INDEX tax_classes_code_unique ON tax_classes (code) WHERE deleted_at IS NULL;
+
+CREATE TABLE tax_rates
+(
+    id           serial PRIMARY KEY,
+    tax_class_id INTEGER       NOT NULL,
+    region       varchar(10)   NOT NULL,
+    rate         numeric(7, 4) NOT NULL,
+    created_at   timestamp not null,
+    updated_at   timestamp not null,
+    CONSTRAINT fk_tax_rate_tax_class FOREIGN KEY (tax_class_id) REFERENCES tax_classes (id),
+    CONSTRAINT chk_tax_rate_range CHECK (rate >= 0 AND rate < 1),
+    CONSTRAINT tax_rates_class_region_unique UNIQUE (tax_class_id, region)
+);
+
+ALTER TABLE products
+    ADD COLUMN tax_class_id INTEGER DEFAULT NULL,
+    ADD CONSTRAINT fk_product_tax_class FOREIGN KEY (tax_class_id) REFERENCES tax_classes (id);
+
+INSERT INTO tax_classes (code, name, created_at, updated_at)
+VALUES ('standard', 'Standard VAT', now(), now()),
+       ('exempt', 'VAT exempt', now(), now());
+
+INSERT INTO tax_rates (tax_class_id, region, rate, created_at, updated_at)
+SELECT id, 'ID', CASE code WHEN 'standard' THEN 0.11 ELSE 0 END, now(), now()
+FROM tax_classes;
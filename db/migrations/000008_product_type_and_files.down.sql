@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS product_files;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS chk_product_type,
+    DROP COLUMN IF EXISTS type;
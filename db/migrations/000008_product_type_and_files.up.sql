@@ -0,0 +1,19 @@
+ALTER TABLE products
+    ADD COLUMN type varchar(10) NOT NULL DEFAULT 'physical',
+    ADD CONSTRAINT chk_product_type CHECK (type IN ('physical', 'digital', 'service'));
+
+CREATE TABLE product_files
+(
+    id           serial PRIMARY KEY,
+    product_id   INTEGER      NOT NULL,
+    file_name    varchar(255) NOT NULL,
+    storage_key  varchar(512) NOT NULL,
+    content_type varchar(255) NOT NULL,
+    size_bytes   BIGINT       NOT NULL,
+    created_at   timestamp not null,
+    updated_at   timestamp not null,
+    deleted_at   timestamp default null,
+    CONSTRAINT fk_product_file_product FOREIGN KEY (product_id) REFERENCES products (id)
+);
+
+CREATE INDEX product_files_product_id_index ON product_files (product_id) WHERE deleted_at IS NULL;
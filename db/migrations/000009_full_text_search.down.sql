@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS brands_search_vector_index;
+DROP INDEX IF EXISTS products_search_vector_index;
+
+DROP TRIGGER IF EXISTS brands_products_search_vector_trigger ON brands;
+DROP TRIGGER IF EXISTS brands_search_vector_trigger ON brands;
+DROP TRIGGER IF EXISTS products_search_vector_trigger ON products;
+
+DROP FUNCTION IF EXISTS brands_products_search_vector_refresh();
+DROP FUNCTION IF EXISTS brands_search_vector_refresh();
+DROP FUNCTION IF EXISTS products_search_vector_refresh();
+
+ALTER TABLE brands
+    DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS description;
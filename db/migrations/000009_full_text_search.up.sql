@@ -0,0 +1,60 @@
+ALTER TABLE products
+    ADD COLUMN description   text     DEFAULT NULL,
+    ADD COLUMN search_vector tsvector DEFAULT NULL;
+
+ALTER TABLE brands
+    ADD COLUMN search_vector tsvector DEFAULT NULL;
+
+-- products are weighted by name (A), brand name (B) and description (C)
+CREATE FUNCTION products_search_vector_refresh() RETURNS trigger AS
+$$
+BEGIN
+    NEW.search_vector :=
+            setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce((SELECT name FROM brands WHERE id = NEW.brand_id), '')), 'B') ||
+            setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name, description, brand_id
+    ON products
+    FOR EACH ROW
+EXECUTE FUNCTION products_search_vector_refresh();
+
+CREATE FUNCTION brands_search_vector_refresh() RETURNS trigger AS
+$$
+BEGIN
+    NEW.search_vector := setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brands_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF name
+    ON brands
+    FOR EACH ROW
+EXECUTE FUNCTION brands_search_vector_refresh();
+
+-- renaming a brand re-weights the products carrying its name
+CREATE FUNCTION brands_products_search_vector_refresh() RETURNS trigger AS
+$$
+BEGIN
+    UPDATE products SET name = name WHERE brand_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brands_products_search_vector_trigger
+    AFTER UPDATE OF name
+    ON brands
+    FOR EACH ROW
+    WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION brands_products_search_vector_refresh();
+
+UPDATE brands SET name = name;
+UPDATE products SET name = name;
+
+CREATE INDEX products_search_vector_index ON products USING gin (search_vector);
+CREATE INDEX brands_search_vector_index ON brands USING gin (search_vector);
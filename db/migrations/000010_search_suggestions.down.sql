@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS brands_name_prefix_index;
+DROP INDEX IF EXISTS brands_name_trgm_index;
+
+DROP INDEX IF EXISTS products_name_prefix_index;
+DROP INDEX IF EXISTS products_name_trgm_index;
@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram indexes back the typo tolerant word_similarity match, the
+-- text_pattern_ops indexes back the prefix match
+CREATE INDEX products_name_trgm_index ON products USING gin (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX products_name_prefix_index ON products (lower(name) text_pattern_ops) WHERE deleted_at IS NULL;
+
+CREATE INDEX brands_name_trgm_index ON brands USING gin (lower(name) gin_trgm_ops) WHERE deleted_at IS NULL;
+CREATE INDEX brands_name_prefix_index ON brands (lower(name) text_pattern_ops) WHERE deleted_at IS NULL;
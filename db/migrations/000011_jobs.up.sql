@@ -0,0 +1,29 @@
+CREATE TABLE jobs
+(
+    id                  bigserial PRIMARY KEY,
+    type                varchar(50)  NOT NULL,
+    status              varchar(20)  NOT NULL DEFAULT 'queued',
+    payload             jsonb        NOT NULL DEFAULT '{}',
+    input_key           varchar(512) default null,
+    result              jsonb        default null,
+    result_key          varchar(512) default null,
+    result_content_type varchar(255) default null,
+    error               text         default null,
+    progress            INTEGER      NOT NULL DEFAULT 0,
+    total               INTEGER      default null,
+    attempts            INTEGER      NOT NULL DEFAULT 0,
+    max_attempts        INTEGER      NOT NULL,
+    cancel_requested    boolean      NOT NULL DEFAULT false,
+    run_at              timestamp    not null,
+    locked_at           timestamp    default null,
+    started_at          timestamp    default null,
+    finished_at         timestamp    default null,
+    created_at          timestamp    not null,
+    updated_at          timestamp    not null,
+    CONSTRAINT chk_job_status CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled'))
+);
+
+-- workers claim the oldest due job and reclaim running jobs whose worker
+-- stopped sending heartbeats
+CREATE INDEX jobs_queued_index ON jobs (run_at, id) WHERE status = 'queued';
+CREATE INDEX jobs_running_index ON jobs (locked_at) WHERE status = 'running';
@@ -0,0 +1,5 @@
+ALTER TABLE products
+    ADD COLUMN version integer NOT NULL DEFAULT 1;
+
+ALTER TABLE brands
+    ADD COLUMN version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    key              varchar(255) PRIMARY KEY,
+    fingerprint      char(64)  NOT NULL,
+    status_code      integer            DEFAULT NULL,
+    response_headers jsonb              DEFAULT NULL,
+    response_body    bytea              DEFAULT NULL,
+    locked_at        timestamp          DEFAULT NULL,
+    expires_at       timestamp NOT NULL,
+    created_at       timestamp NOT NULL,
+    updated_at       timestamp NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_index
+    ON idempotency_keys (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE idempotency_keys
+    ADD COLUMN owner char(32) NOT NULL DEFAULT '';
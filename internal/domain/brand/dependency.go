@@ -3,9 +3,11 @@ package brand
 import (
 	"context"
 	"ecommerce/config"
+	"ecommerce/constants"
 	"ecommerce/internal/domain/brand/presenter"
 	brandReposiotry "ecommerce/internal/domain/brand/repository"
 	brandUseCase "ecommerce/internal/domain/brand/usecase"
+	ProductDeps "ecommerce/internal/domain/product"
 	"log/slog"
 )
 
@@ -15,6 +17,12 @@ func NewBrandDependency(
 	logger *slog.Logger,
 ) presenter.IBrandPresenter {
 	repository := brandReposiotry.NewBrandRepository(ctx, dbProvider, logger)
-	useCase := brandUseCase.NewBrandUseCase(repository)
-	return presenter.NewBrandPresenter(useCase)
+	useCase := brandUseCase.NewBrandUseCase(repository, config.SearchIndexProvider, ProductDeps.NewProductUseCase(ctx, dbProvider, logger))
+
+	bulkMode := config.AppConfig.BulkDefaultMode
+	if bulkMode == "" {
+		bulkMode = constants.DefaultBulkMode
+	}
+
+	return presenter.NewBrandPresenter(useCase, bulkMode, config.AppConfig.RequireIfMatch)
 }
@@ -1,29 +1,130 @@
 package dto
 
+import (
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/jsonapi"
+	"ecommerce/pkg/ordering"
+)
+
 type CreateBrandDTO struct {
-	Name string `json:"name" form:"name" validate:"required"`
+	Name     string `json:"name" form:"name" validate:"required"`
+	ParentId *int64 `json:"parent_id" form:"parent_id" validate:"omitempty,gt=0"`
 }
 
+// UpdateBrandDTO keeps the current parent when ParentId is omitted; sending
+// parent_id 0 detaches the brand from its parent.
 type UpdateBrandDTO struct {
-	ID   int64  `json:"id" form:"id" param:"id" query:"id" swaggerignore:"true"`
-	Name string `json:"name" form:"name"`
+	ID       int64  `json:"id" form:"id" param:"id" query:"id" swaggerignore:"true"`
+	Name     string `json:"name" form:"name"`
+	ParentId *int64 `json:"parent_id" form:"parent_id" validate:"omitempty,gte=0"`
+	IfMatch  string `json:"-" swaggerignore:"true"`
+}
+
+// BulkBrandDTO carries the bulk operations that passed validation; Index
+// points back into the request.
+type BulkBrandDTO struct {
+	Mode       string
+	Operations []*BulkBrandOperationDTO
+}
+
+type BulkBrandOperationDTO struct {
+	Index  int
+	Op     string
+	ID     int64
+	Create *CreateBrandDTO
+	Update *UpdateBrandDTO
 }
 
 type BrandWithIdDTO struct {
-	ID int64 `json:"id" form:"id" param:"id" query:"id"`
+	ID        int64             `json:"id" form:"id" param:"id" query:"id"`
+	Selection *fields.Selection `json:"-" swaggerignore:"true"`
+	IfMatch   string            `json:"-" swaggerignore:"true"`
 }
 
 type FindBrandDTO struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int64           `json:"id"`
+	Name      string          `json:"name"`
+	ParentId  *int64          `json:"parent_id"`
+	Path      []*BrandPathDTO `json:"path,omitempty"`
+	Version   int             `json:"version"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
 }
 
+// BrandPathDTO is one ancestor in a brand's path, ordered from the root brand
+// down to the direct parent.
+type BrandPathDTO struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// BrandPaginationDTO pages by PerPage and Page, or by cursor once Limit is
+// set. WithCount asks a cursor page for the total, which offset pages always
+// carry.
 type BrandPaginationDTO struct {
-	PerPage int64  `json:"per_page" query:"per_page" validate:"required,number"`
-	Page    int64  `json:"page" query:"page" validate:"required,number"`
-	Sort    string `json:"sort" query:"sort" validate:"required,oneof=asc desc"`
-	SortBy  string `json:"sort_by" query:"sort_by"`
-	Search  string `json:"search" query:"search"`
+	PerPage   int64          `json:"per_page" query:"per_page" validate:"required_without=Limit,number"`
+	Page      int64          `json:"page" query:"page" validate:"required_without=Limit,number"`
+	Limit     int64          `json:"limit" query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Cursor    *cursor.Cursor `json:"-" swaggerignore:"true"`
+	WithCount bool           `json:"count" query:"count"`
+	Search    string         `json:"search" query:"search" validate:"max=200"`
+
+	OrderBy   []ordering.Key     `json:"-" swaggerignore:"true"`
+	Filters   *filter.Expression `json:"-" swaggerignore:"true"`
+	Selection *fields.Selection  `json:"-" swaggerignore:"true"`
+}
+
+// BrandSortFields lists the names the brand list can be sorted by; relevance
+// ranks full text search matches.
+var BrandSortFields = ordering.Registry{
+	"id":         {Table: "brands", Name: "id"},
+	"name":       {Table: "brands", Name: "name"},
+	"created_at": {Table: "brands", Name: "created_at"},
+	"updated_at": {Table: "brands", Name: "updated_at"},
+	"relevance":  {},
+}
+
+// BrandFields maps the fields a brand response can be narrowed to with
+// ?fields= to their columns.
+var BrandFields = fields.Registry{
+	"id":         {"id"},
+	"name":       {"name"},
+	"parent_id":  {"parent_id"},
+	"version":    {"version"},
+	"created_at": {"created_at"},
+	"updated_at": {"updated_at"},
+}
+
+// BrandIncludes lists the relations ?include= can load on a single brand; the
+// path is resolved from the brand's ancestors.
+var BrandIncludes = fields.Registry{
+	"path": {},
+}
+
+// BrandResource describes brands to JSON:API clients; the parent and the
+// ancestors in path are related brands.
+var BrandResource = &jsonapi.Schema{
+	Type: "brands",
+	Relations: []*jsonapi.Relation{
+		{Name: "parent", Key: "parent_id", Type: "brands"},
+		{Name: "path", Key: "path", Type: "brands", Embedded: true},
+	},
+}
+
+// BrandFilterFields whitelists the field[operator] filters of the brand list,
+// e.g. parent_id[null]=true for top-level brands.
+var BrandFilterFields = filter.Whitelist{
+	"id":         {Table: "brands", Column: "id", Type: filter.Int, Operators: filter.Equality},
+	"name":       {Table: "brands", Column: "name", Type: filter.String, Operators: filter.Equality},
+	"parent_id":  {Table: "brands", Column: "parent_id", Type: filter.Int, Operators: []filter.Operator{filter.Eq, filter.Ne, filter.In, filter.Nin, filter.Null}},
+	"created_at": {Table: "brands", Column: "created_at", Type: filter.Time, Operators: filter.Comparable},
+	"updated_at": {Table: "brands", Column: "updated_at", Type: filter.Time, Operators: filter.Comparable},
+}
+
+type BrandDuplicateDTO struct {
+	NormalizedName string   `json:"normalized_name"`
+	Ids            []string `json:"ids"`
+	Total          int64    `json:"total"`
 }
@@ -11,6 +11,8 @@ type Brand struct {
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt
 	Name      string
+	ParentId  *uint
+	Version   int
 }
 
 func (Brand) TableName() string {
@@ -19,6 +21,9 @@ func (Brand) TableName() string {
 
 func (b *Brand) BeforeCreate(tx *gorm.DB) error {
 	b.CreatedAt = time.Now()
+	if b.Version == 0 {
+		b.Version = 1
+	}
 	return nil
 }
 
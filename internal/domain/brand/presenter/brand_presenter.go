@@ -1,8 +1,18 @@
 package presenter
 
 import (
+	"ecommerce/constants"
 	"ecommerce/internal/domain/brand/dto"
 	"ecommerce/internal/domain/brand/usecase"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/jsonapi"
+	"ecommerce/pkg/ordering"
+	"encoding/json"
 	"github.com/labstack/echo/v4"
 	"net/http"
 	"strconv"
@@ -16,72 +26,139 @@ type IBrandPresenter interface {
 	Create(c echo.Context) error
 	Update(c echo.Context) error
 	Delete(c echo.Context) error
+	GetChildren(c echo.Context) error
+	Bulk(c echo.Context) error
 }
 
 type BrandPresenter struct {
-	useCase usecase.IBrandUseCase
+	useCase        usecase.IBrandUseCase
+	bulkMode       string
+	requireIfMatch bool
 }
 
-func NewBrandPresenter(useCase usecase.IBrandUseCase) *BrandPresenter {
+func NewBrandPresenter(useCase usecase.IBrandUseCase, bulkMode string, requireIfMatch bool) *BrandPresenter {
 	return &BrandPresenter{
-		useCase: useCase,
+		useCase:        useCase,
+		bulkMode:       bulkMode,
+		requireIfMatch: requireIfMatch,
 	}
 }
 
 // GetAll godoc
 // @Summary      Get All brand
-// @Description  Get All brand data
+// @Description  Get All brand data. Filter with field[operator]=value params on id, name, parent_id, created_at and updated_at, e.g. parent_id[null]=true; operators are eq, ne, gt, gte, lt, lte, in, nin and null. Passing limit or cursor pages by cursor instead of PerPage and Page and responds with limit, next_cursor, prev_cursor and, when count=true, total_items
 // @Tags         brand
 // @Accept       json
-// @Produce      json
-// @Param 		 PerPage query int true "item per page count"
-// @Param 		 Page query int true "page"
-// @Param 		 Sort query string true "sorting order (desc, asc)"
-// @Param 		 SortBy query string true "sorting fields (default created_at)"
-// @Param 		 Search query string false "brand param query"
+// @Produce      json,application/vnd.api+json
+// @Param 		 per_page query int false "item per page count, required without limit"
+// @Param 		 page query int false "page, required without limit"
+// @Param 		 limit query int false "cursor page size (1-100, default 10); switches to cursor pagination"
+// @Param 		 cursor query string false "next_cursor or prev_cursor of a previous cursor page"
+// @Param 		 count query bool false "include total_items in a cursor page"
+// @Param 		 sort query string false "comma separated sort fields (id, name, created_at, updated_at, relevance), prefixed by - for descending, e.g. name,-created_at (default -created_at, -relevance when searching)"
+// @Param 		 SortBy query string false "deprecated, use sort"
+// @Param 		 Sort query string false "deprecated, direction of SortBy (desc, asc)"
+// @Param 		 search query string false "full text search over brand name"
+// @Param 		 fields query string false "comma separated fields to return (id, name, parent_id, version, created_at, updated_at; default all)"
 // @Success      200  {object}  response.PaginationResponse{data=[]dto.FindBrandDTO}
 // @Router       /brands [get]
 func (presenter *BrandPresenter) GetAll(c echo.Context) error {
 	params := &dto.BrandPaginationDTO{}
-	perPageParam := c.QueryParam("PerPage")
-	pageParam := c.QueryParam("Page")
-	sortParam := c.QueryParam("Sort")
-	sortByParam := c.QueryParam("SortBy")
-	searchParam := c.QueryParam("Search")
+	searchParam := c.QueryParam("search")
+	cursorMode := c.QueryParams().Has("cursor") || c.QueryParams().Has("limit")
+
+	if cursorMode {
+		if err := bindCursor(c, params); err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+	} else {
+		perPage, err := strconv.ParseInt(c.QueryParam("per_page"), 10, 64)
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+
+		page, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+
+		params.PerPage = perPage
+		params.Page = page
+	}
 
-	perPage, err := strconv.ParseInt(perPageParam, 10, 64)
+	var err error
+	params.Filters, err = filter.Parse(c.QueryParams(), dto.BrandFilterFields)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.InvalidFields(err)
 	}
 
-	page, err := strconv.ParseInt(pageParam, 10, 64)
+	params.OrderBy, err = dto.BrandSortFields.Parse(ordering.FromQuery(c.QueryParams()))
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.InvalidFields(map[string]interface{}{"sort": err})
+	}
+
+	params.Selection, err = fields.FromQuery(c.QueryParams(), dto.BrandFields, nil)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
 	}
 
-	params.Sort = sortParam
-	params.SortBy = sortByParam
 	params.Search = searchParam
-	params.PerPage = perPage
-	params.Page = page
 
 	if err := c.Validate(params); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
+	}
+
+	if cursorMode {
+		brands, page, err := presenter.useCase.FindPage(params)
+		if err != nil {
+			c.Logger().Error(err)
+			return err
+		}
+
+		return HttpResponser.CursorPagination(c, page.Next, page.Prev, int(params.Limit), page.Total, jsonapi.Of(dto.BrandResource, params.Selection, brands))
 	}
 
 	count, totalPage, brands, err := presenter.useCase.FindAll(params)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	return HttpResponser.Pagination(c, count, totalPage, int(params.PerPage), int(params.Page), jsonapi.Of(dto.BrandResource, params.Selection, brands))
+}
+
+// bindCursor reads the cursor, limit and count query params of a cursor page.
+func bindCursor(c echo.Context, params *dto.BrandPaginationDTO) error {
+	limit, err := cursor.ParseLimit(c.QueryParam("limit"), constants.DefaultCursorLimit)
+	if err != nil {
+		return err
+	}
+	params.Limit = limit
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		next, err := cursor.Decode(raw)
+		if err != nil {
+			return err
+		}
+		params.Cursor = next
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewPaginationResponse(count, totalPage, int(params.PerPage), int(params.Page), brands))
+	if raw := c.QueryParam("count"); raw != "" {
+		withCount, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		params.WithCount = withCount
+	}
+
+	return nil
 }
 
 // Get godoc
@@ -89,29 +166,76 @@ func (presenter *BrandPresenter) GetAll(c echo.Context) error {
 // @Description  Get brand data
 // @Tags         brand
 // @Accept       json
-// @Produce      json
+// @Produce      json,application/vnd.api+json
 // @Param 		 id path int true "brand id"
+// @Param 		 fields query string false "comma separated fields to return (id, name, parent_id, version, created_at, updated_at; default all)"
+// @Param 		 include query string false "comma separated relations to return (path; default all unless fields is set)"
 // @Success      200  {object}  response.SuccessResponse{data=dto.FindBrandDTO}
+// @Header       200  {string}  ETag "version of the brand, to send back in If-Match"
 // @Router       /brands/{id} [get]
 func (presenter *BrandPresenter) Get(c echo.Context) error {
 	paramId := c.Param("id")
 	id, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.Invalid(err)
 	}
 
 	payload := &dto.BrandWithIdDTO{
 		ID: id,
 	}
 
+	payload.Selection, err = fields.FromQuery(c.QueryParams(), dto.BrandFields, dto.BrandIncludes)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
 	brand, err := presenter.useCase.FindById(payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	c.Response().Header().Set(etag.HeaderETag, etag.Format(brand.Version))
+	return HttpResponser.Success(c, http.StatusOK, "Get brand success", jsonapi.Of(dto.BrandResource, payload.Selection, brand))
+}
+
+// GetChildren godoc
+// @Summary      Get brand children
+// @Description  Get direct sub-brands of a brand
+// @Tags         brand
+// @Accept       json
+// @Produce      json,application/vnd.api+json
+// @Param 		 id path int true "brand id"
+// @Param 		 fields query string false "comma separated fields to return (id, name, parent_id, version, created_at, updated_at; default all)"
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.FindBrandDTO}
+// @Router       /brands/{id}/children [get]
+func (presenter *BrandPresenter) GetChildren(c echo.Context) error {
+	paramId := c.Param("id")
+	id, err := strconv.ParseInt(paramId, 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	payload := &dto.BrandWithIdDTO{
+		ID: id,
+	}
+
+	payload.Selection, err = fields.FromQuery(c.QueryParams(), dto.BrandFields, nil)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Get brand success", brand))
+	children, err := presenter.useCase.FindChildren(payload)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get brand children success", jsonapi.Of(dto.BrandResource, payload.Selection, children))
 }
 
 // Create godoc
@@ -122,30 +246,28 @@ func (presenter *BrandPresenter) Get(c echo.Context) error {
 // @Produce      json
 // @Param 		 request body dto.CreateBrandDTO true "request body"
 // @Success      201  {object}  response.SuccessResponse{data=nil}
+// @Failure      409  {object}  response.Problem
 // @Router       /brands [post]
 func (presenter *BrandPresenter) Create(c echo.Context) error {
 	payload := dto.CreateBrandDTO{}
 	if err := c.Bind(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
 	err := c.Validate(&payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
 	}
 
 	err = presenter.useCase.CreateBrand(&payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, HttpResponser.NewSuccessResponse("Brand created", nil))
+	return HttpResponser.Success(c, http.StatusCreated, "Brand created", nil)
 }
 
 // Update godoc
@@ -155,39 +277,45 @@ func (presenter *BrandPresenter) Create(c echo.Context) error {
 // @Accept       json
 // @Produce      json
 // @Param 		 id path int true "brand id"
+// @Param 		 If-Match header string false "ETag the brand was read with; required when REQUIRE_IF_MATCH is set"
 // @Param 		 request body dto.CreateBrandDTO true "request body"
 // @Success      200  {object}  response.SuccessResponse{data=nil}
+// @Failure      409  {object}  response.Problem
+// @Failure      412  {object}  response.Problem
+// @Failure      428  {object}  response.Problem
 // @Router       /brands/{id} [patch]
 func (presenter *BrandPresenter) Update(c echo.Context) error {
 	paramId := c.Param("id")
 	id, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.Invalid(err)
 	}
 
 	payload := dto.UpdateBrandDTO{}
 	if err := c.Bind(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
 	payload.ID = id
+	payload.IfMatch = c.Request().Header.Get(etag.HeaderIfMatch)
+
+	if payload.IfMatch == "" && presenter.requireIfMatch {
+		return etag.ErrPreconditionRequired
+	}
 
 	if err := c.Validate(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
 	}
 
 	if err := presenter.useCase.UpdateBrand(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Brand updated", nil))
+	return HttpResponser.Success(c, http.StatusOK, "Brand updated", nil)
 }
 
 // Delete godoc
@@ -197,32 +325,111 @@ func (presenter *BrandPresenter) Update(c echo.Context) error {
 // @Accept       json
 // @Produce      json
 // @Param 		 id path int true "brand id"
+// @Param 		 If-Match header string false "ETag the brand was read with; required when REQUIRE_IF_MATCH is set"
 // @Success      200  {object}  response.SuccessResponse{data=nil}
+// @Failure      412  {object}  response.Problem
+// @Failure      428  {object}  response.Problem
 // @Router       /brands/{id} [delete]
 func (presenter *BrandPresenter) Delete(c echo.Context) error {
 	paramId := c.Param("id")
 	id, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.Invalid(err)
 	}
 
 	payload := dto.BrandWithIdDTO{
-		ID: id,
+		ID:      id,
+		IfMatch: c.Request().Header.Get(etag.HeaderIfMatch),
+	}
+
+	if payload.IfMatch == "" && presenter.requireIfMatch {
+		return etag.ErrPreconditionRequired
 	}
 
 	if err := c.Validate(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
 	}
 
 	if err := presenter.useCase.DeleteBrand(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Brand deleted", nil)
+}
+
+// Bulk godoc
+// @Summary      Bulk write brands
+// @Description  Create, update and delete brands in one request. Each operation names its op (create, update, delete), the brand id for update and delete, and data holding the body of the single create or update endpoint. Atomic mode (default from BULK_DEFAULT_MODE) applies every operation or none, best_effort applies each operation that succeeds. Responds 200 when every operation succeeded and 207 with the status and validation errors of each item otherwise
+// @Tags         brand
+// @Accept       json
+// @Produce      json
+// @Param 		 request body bulk.Request true "request body"
+// @Success      200  {object}  response.SuccessResponse{data=bulk.Report}
+// @Success      207  {object}  response.SuccessResponse{data=bulk.Report}
+// @Router       /brands/bulk [post]
+func (presenter *BrandPresenter) Bulk(c echo.Context) error {
+	request := &bulk.Request{}
+	if err := c.Bind(request); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := c.Validate(request); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if request.Mode == "" {
+		request.Mode = presenter.bulkMode
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Brand deleted", nil))
+	report := bulk.NewReport(request)
+	payload := &dto.BulkBrandDTO{Mode: request.Mode}
+	for i, op := range request.Operations {
+		if err := c.Validate(op); err != nil {
+			report.Reject(i, err.(*apperror.Error).Details()["errors"])
+			continue
+		}
+
+		item := &dto.BulkBrandOperationDTO{Index: i, Op: op.Op, ID: op.ID}
+		var data interface{}
+		switch op.Op {
+		case bulk.OpCreate:
+			item.Create = &dto.CreateBrandDTO{}
+			data = item.Create
+		case bulk.OpUpdate:
+			item.Update = &dto.UpdateBrandDTO{}
+			data = item.Update
+		}
+
+		if data != nil {
+			if len(op.Data) == 0 {
+				report.Reject(i, map[string]string{"data": "Data is required"})
+				continue
+			}
+
+			if err := json.Unmarshal(op.Data, data); err != nil {
+				report.Reject(i, map[string]string{"data": err.Error()})
+				continue
+			}
+
+			if err := c.Validate(data); err != nil {
+				report.Reject(i, err.(*apperror.Error).Details()["errors"])
+				continue
+			}
+		}
+
+		payload.Operations = append(payload.Operations, item)
+	}
+
+	presenter.useCase.BulkWrite(payload, report)
+
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return HttpResponser.Success(c, status, "Bulk operations processed", report)
 }
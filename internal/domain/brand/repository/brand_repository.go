@@ -3,20 +3,47 @@ package repository
 import (
 	"context"
 	"ecommerce/config"
+	"ecommerce/constants"
 	"ecommerce/internal/domain/brand/dto"
 	"ecommerce/internal/domain/brand/entity"
-	"fmt"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/fulltext"
+	"ecommerce/pkg/ordering"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log/slog"
+	"slices"
+	"strings"
 )
 
+const uniqueViolationCode = "23505"
+
+var ErrBrandNameTaken = errors.New("brand name already exists")
+
+var brandIdColumn = clause.Column{Table: "brands", Name: "id"}
+
 //go:generate mockgen -source=brand_repository.go -destination=mocks/brand_repository_mock.go -package=mocks
 type IBrandRepository interface {
-	Count() (int64, error)
+	Count(params *dto.BrandPaginationDTO) (int64, error)
 	FindAll(params *dto.BrandPaginationDTO) ([]*entity.Brand, error)
+	FindPage(params *dto.BrandPaginationDTO) ([]*entity.Brand, bool, error)
 	FindById(id uint) (*entity.Brand, error)
+	FindByIds(ids []uint) ([]*entity.Brand, error)
+	FindByIdWithFields(id uint, selection *fields.Selection) (*entity.Brand, error)
+	FindByName(name string) (*entity.Brand, error)
+	FindDuplicateNames() ([]*dto.BrandDuplicateDTO, error)
+	FindChildren(id uint, selection *fields.Selection) ([]*entity.Brand, error)
+	FindAncestors(id uint) ([]*entity.Brand, error)
+	FindDescendantIds(id uint) ([]int64, error)
 	Create(brand *entity.Brand) error
 	Update(brand *entity.Brand) error
 	Delete(brand *entity.Brand) error
+	FindExistingIds(ids []uint) ([]uint, error)
+	WithTransaction(fn func(repository IBrandRepository) error) error
 }
 
 type BrandRepository struct {
@@ -33,9 +60,10 @@ func NewBrandRepository(ctx context.Context, dbProvider *config.DatabaseConfigur
 	}
 }
 
-func (repo *BrandRepository) Count() (int64, error) {
+func (repo *BrandRepository) Count(params *dto.BrandPaginationDTO) (int64, error) {
 	var count int64
-	if err := repo.dbProvider.WithContext(repo.ctx).Model(&entity.Brand{}).Count(&count).Error; err != nil {
+	qw := repo.filter(repo.dbProvider.WithContext(repo.ctx).Model(&entity.Brand{}), params)
+	if err := qw.Count(&count).Error; err != nil {
 		return 0, err
 	}
 
@@ -44,11 +72,11 @@ func (repo *BrandRepository) Count() (int64, error) {
 
 func (repo *BrandRepository) FindAll(params *dto.BrandPaginationDTO) ([]*entity.Brand, error) {
 	brands := make([]*entity.Brand, 0)
-	qw := repo.dbProvider.WithContext(repo.ctx).
-		Model(&brands).
+	qw := selectFields(repo.filter(repo.dbProvider.WithContext(repo.ctx).Model(&brands), params), params.Selection).
 		Limit(int(params.PerPage)).
-		Offset(int(params.PerPage * (params.Page - 1))).
-		Order(fmt.Sprintf("%s %s", params.SortBy, params.Sort))
+		Offset(int(params.PerPage * (params.Page - 1)))
+
+	qw = qw.Order(ordering.OrderBy(params.OrderBy, brandIdColumn, computedKeys(params.Search)))
 
 	if err := qw.Find(&brands).Error; err != nil {
 		return make([]*entity.Brand, 0), err
@@ -57,44 +85,287 @@ func (repo *BrandRepository) FindAll(params *dto.BrandPaginationDTO) ([]*entity.
 	return brands, nil
 }
 
+// FindPage returns up to params.Limit brands following params.Cursor in sort
+// order, or preceding it for a backward cursor, and whether more brands lie
+// beyond the page in that direction.
+func (repo *BrandRepository) FindPage(params *dto.BrandPaginationDTO) ([]*entity.Brand, bool, error) {
+	brands := make([]*entity.Brand, 0)
+	computed := computedKeys(params.Search)
+
+	keys := ordering.WithTieBreaker(params.OrderBy, "id", brandIdColumn)
+	backward := params.Cursor != nil && params.Cursor.Backward
+	if backward {
+		keys = ordering.Reverse(keys)
+	}
+
+	qw := selectFields(repo.filter(repo.dbProvider.WithContext(repo.ctx).Model(&brands), params), params.Selection).
+		Order(ordering.OrderBy(keys, brandIdColumn, computed)).
+		Limit(int(params.Limit) + 1)
+
+	if params.Cursor != nil {
+		qw = qw.Where(ordering.Keyset(keys, computed, "brands", params.Cursor.ID))
+	}
+
+	if err := qw.Find(&brands).Error; err != nil {
+		return make([]*entity.Brand, 0), false, err
+	}
+
+	more := len(brands) > int(params.Limit)
+	if more {
+		brands = brands[:params.Limit]
+	}
+
+	if backward {
+		slices.Reverse(brands)
+	}
+
+	return brands, more, nil
+}
+
+// selectFields narrows a brand query to the columns of the selection; a nil
+// selection loads every column.
+func selectFields(qw *gorm.DB, selection *fields.Selection) *gorm.DB {
+	if columns := selection.Columns("brands"); columns != nil {
+		// the version is always loaded for the ETag
+		qw = qw.Select(append(columns, "brands.version"))
+	}
+	return qw
+}
+
+// computedKeys supplies the relevance sort key while searching.
+func computedKeys(search string) map[string]clause.Expr {
+	computed := make(map[string]clause.Expr)
+	if query := fulltext.PrefixQuery(search); query != "" {
+		computed["relevance"] = clause.Expr{
+			SQL:  "ts_rank(brands.search_vector, to_tsquery(?, ?))",
+			Vars: []interface{}{fulltext.Config, query},
+		}
+	}
+	return computed
+}
+
+// filter narrows a brand query to the search term and the field filters so
+// that FindAll and Count always agree.
+func (repo *BrandRepository) filter(qw *gorm.DB, params *dto.BrandPaginationDTO) *gorm.DB {
+	if query := fulltext.PrefixQuery(params.Search); query != "" {
+		qw = qw.Where("brands.search_vector @@ to_tsquery(?, ?)", fulltext.Config, query)
+	}
+
+	return filter.Apply(qw, params.Filters)
+}
+
 func (repo *BrandRepository) FindById(id uint) (*entity.Brand, error) {
+	return repo.FindByIdWithFields(id, nil)
+}
+
+// FindByIds loads the non-deleted brands with the given ids in no particular
+// order; ids without a brand are left out.
+func (repo *BrandRepository) FindByIds(ids []uint) ([]*entity.Brand, error) {
+	brands := make([]*entity.Brand, 0, len(ids))
+	if len(ids) == 0 {
+		return brands, nil
+	}
+
+	if err := repo.dbProvider.WithContext(repo.ctx).Where("id IN ?", ids).Find(&brands).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return make([]*entity.Brand, 0), err
+	}
+	return brands, nil
+}
+
+func (repo *BrandRepository) FindByIdWithFields(id uint, selection *fields.Selection) (*entity.Brand, error) {
 	var brand *entity.Brand
-	if err := repo.dbProvider.WithContext(repo.ctx).First(&brand, "id = ?", id).Error; err != nil {
+	if err := selectFields(repo.dbProvider.WithContext(repo.ctx), selection).First(&brand, "id = ?", id).Error; err != nil {
 		repo.logger.Error(err.Error())
 		return nil, err
 	}
 	return brand, nil
 }
 
-func (repo *BrandRepository) Create(brand *entity.Brand) error {
-	tx := repo.dbProvider.Begin()
-	if err := tx.WithContext(repo.ctx).Create(brand).Error; err != nil {
-		tx.Rollback()
+// FindByName looks up a non-deleted brand using the same normalization as the
+// brands_normalized_name_unique index (trimmed and case-folded).
+func (repo *BrandRepository) FindByName(name string) (*entity.Brand, error) {
+	var brand *entity.Brand
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		First(&brand, "lower(btrim(name)) = lower(btrim(?))", name).Error; err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+func (repo *BrandRepository) FindDuplicateNames() ([]*dto.BrandDuplicateDTO, error) {
+	rows := make([]struct {
+		NormalizedName string
+		Ids            string
+		Total          int64
+	}, 0)
+
+	err := repo.dbProvider.WithContext(repo.ctx).
+		Model(&entity.Brand{}).
+		Select("lower(btrim(name)) AS normalized_name, string_agg(id::text, ',' ORDER BY id) AS ids, count(*) AS total").
+		Group("lower(btrim(name))").
+		Having("count(*) > 1").
+		Order("normalized_name").
+		Scan(&rows).Error
+	if err != nil {
 		repo.logger.Error(err.Error())
-		return err
+		return nil, err
 	}
-	tx.Commit()
-	return nil
+
+	duplicates := make([]*dto.BrandDuplicateDTO, 0, len(rows))
+	for _, row := range rows {
+		duplicates = append(duplicates, &dto.BrandDuplicateDTO{
+			NormalizedName: row.NormalizedName,
+			Ids:            strings.Split(row.Ids, ","),
+			Total:          row.Total,
+		})
+	}
+
+	return duplicates, nil
 }
 
-func (repo *BrandRepository) Update(brand *entity.Brand) error {
-	tx := repo.dbProvider.Begin()
-	if err := tx.WithContext(repo.ctx).Save(brand).Where("id = ?", brand.ID).Error; err != nil {
-		tx.Rollback()
+func (repo *BrandRepository) FindChildren(id uint, selection *fields.Selection) ([]*entity.Brand, error) {
+	brands := make([]*entity.Brand, 0)
+	if err := selectFields(repo.dbProvider.WithContext(repo.ctx), selection).
+		Where("parent_id = ?", id).
+		Order("name asc").
+		Find(&brands).Error; err != nil {
 		repo.logger.Error(err.Error())
-		return err
+		return make([]*entity.Brand, 0), err
 	}
-	tx.Commit()
-	return nil
+	return brands, nil
 }
 
-func (repo *BrandRepository) Delete(brand *entity.Brand) error {
-	tx := repo.dbProvider.Begin()
-	if err := tx.WithContext(repo.ctx).Delete(brand).Error; err != nil {
-		tx.Rollback()
+// FindAncestors returns the parent chain of a brand ordered from the root
+// brand down to the direct parent. The brand itself is not included.
+func (repo *BrandRepository) FindAncestors(id uint) ([]*entity.Brand, error) {
+	brands := make([]*entity.Brand, 0)
+	err := repo.dbProvider.WithContext(repo.ctx).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, name, parent_id, version, created_at, updated_at, 0 AS depth
+			FROM brands
+			WHERE id = ?
+			UNION ALL
+			SELECT b.id, b.name, b.parent_id, b.version, b.created_at, b.updated_at, a.depth + 1
+			FROM brands b
+			JOIN ancestors a ON b.id = a.parent_id
+			WHERE b.deleted_at IS NULL AND a.depth < ?
+		)
+		SELECT id, name, parent_id, version, created_at, updated_at
+		FROM ancestors
+		WHERE depth > 0
+		ORDER BY depth DESC`, id, constants.MaxBrandDepth).
+		Scan(&brands).Error
+	if err != nil {
+		repo.logger.Error(err.Error())
+		return make([]*entity.Brand, 0), err
+	}
+	return brands, nil
+}
+
+// FindDescendantIds returns the id of the brand together with the ids of all
+// of its non-deleted sub-brands at any depth.
+func (repo *BrandRepository) FindDescendantIds(id uint) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := repo.dbProvider.WithContext(repo.ctx).Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id, 0 AS depth
+			FROM brands
+			WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT b.id, t.depth + 1
+			FROM brands b
+			JOIN tree t ON b.parent_id = t.id
+			WHERE b.deleted_at IS NULL AND t.depth < ?
+		)
+		SELECT id FROM tree`, id, constants.MaxBrandDepth).
+		Scan(&ids).Error
+	if err != nil {
 		repo.logger.Error(err.Error())
-		return err
+		return make([]int64, 0), err
+	}
+	return ids, nil
+}
+
+func (repo *BrandRepository) Create(brand *entity.Brand) error {
+	return repo.dbProvider.WithContext(repo.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(brand).Error; err != nil {
+			repo.logger.Error(err.Error())
+			return translateError(err)
+		}
+		return nil
+	})
+}
+
+// Update writes the brand only when its stored version is still
+// brand.Version, the version the update is based on, and bumps the version.
+// It returns etag.ErrPreconditionFailed when another write came first.
+func (repo *BrandRepository) Update(brand *entity.Brand) error {
+	return repo.dbProvider.WithContext(repo.ctx).Transaction(func(tx *gorm.DB) error {
+		version := brand.Version
+		brand.Version++
+
+		qw := tx.Model(brand).
+			Where("version = ?", version).
+			Select("*").
+			Omit("ID", "CreatedAt", "DeletedAt").
+			Updates(brand)
+		if qw.Error != nil {
+			brand.Version = version
+			repo.logger.Error(qw.Error.Error())
+			return translateError(qw.Error)
+		}
+
+		if qw.RowsAffected == 0 {
+			brand.Version = version
+			return etag.ErrPreconditionFailed
+		}
+		return nil
+	})
+}
+
+// Delete deletes the brand only when its stored version is still
+// brand.Version and returns etag.ErrPreconditionFailed otherwise.
+func (repo *BrandRepository) Delete(brand *entity.Brand) error {
+	return repo.dbProvider.WithContext(repo.ctx).Transaction(func(tx *gorm.DB) error {
+		qw := tx.Where("version = ?", brand.Version).Delete(brand)
+		if qw.Error != nil {
+			repo.logger.Error(qw.Error.Error())
+			return qw.Error
+		}
+
+		if qw.RowsAffected == 0 {
+			return etag.ErrPreconditionFailed
+		}
+		return nil
+	})
+}
+
+// FindExistingIds returns the ids out of ids that belong to a record.
+func (repo *BrandRepository) FindExistingIds(ids []uint) ([]uint, error) {
+	existing := make([]uint, 0, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
+	}
+
+	err := repo.dbProvider.WithContext(repo.ctx).Model(&entity.Brand{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+// WithTransaction runs fn against a repository bound to a single transaction
+// that is committed when fn returns nil and rolled back otherwise.
+// Create, Update and Delete use Transaction rather than Begin so that they
+// nest as savepoints inside it.
+func (repo *BrandRepository) WithTransaction(fn func(repository IBrandRepository) error) error {
+	return repo.dbProvider.WithContext(repo.ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewBrandRepository(repo.ctx, &config.DatabaseConfiguration{DB: tx}, repo.logger))
+	})
+}
+
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrBrandNameTaken
 	}
-	tx.Commit()
-	return nil
+	return err
 }
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/brand/dto"
+	"ecommerce/internal/domain/brand/entity"
+	"ecommerce/pkg/ordering"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statements records the SQL gorm runs, or would run in dry run mode.
+type statements struct {
+	logger.Interface
+	sql []string
+}
+
+func (s *statements) LogMode(logger.LogLevel) logger.Interface {
+	return s
+}
+
+func (s *statements) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	s.sql = append(s.sql, sql)
+}
+
+// dryRun returns a repository that only records the SQL it would run.
+func dryRun(t *testing.T) (*BrandRepository, *statements) {
+	t.Helper()
+
+	recorder := &statements{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBrandRepository(context.Background(), &config.DatabaseConfiguration{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil))), recorder
+}
+
+var selectList = regexp.MustCompile(`(?s)SELECT\s+(.*?)\s+FROM`)
+
+// columns returns the select list of the last SELECT in sql, or of the first
+// when first is set.
+func columns(t *testing.T, sql string, first bool) []string {
+	t.Helper()
+
+	matches := selectList.FindAllStringSubmatch(sql, -1)
+	if len(matches) == 0 {
+		t.Fatalf("no SELECT in %s", sql)
+	}
+	match := matches[len(matches)-1]
+	if first {
+		match = matches[0]
+	}
+
+	list := strings.Split(match[1], ",")
+	for i, column := range list {
+		list[i] = strings.TrimSpace(column)
+	}
+	return list
+}
+
+// TestRecursiveQueries checks that both sides of the UNION ALL of every
+// recursive query select the same columns, which Postgres requires.
+func TestRecursiveQueries(t *testing.T) {
+	repo, recorder := dryRun(t)
+
+	tests := []struct {
+		name string
+		run  func()
+	}{
+		{name: "FindAncestors", run: func() { _, _ = repo.FindAncestors(3) }},
+		{name: "FindDescendantIds", run: func() { _, _ = repo.FindDescendantIds(3) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.sql = nil
+			tt.run()
+			if len(recorder.sql) != 1 {
+				t.Fatalf("ran %d statements, want 1", len(recorder.sql))
+			}
+
+			anchor, recursive, ok := strings.Cut(recorder.sql[0], "UNION ALL")
+			if !ok {
+				t.Fatalf("no UNION ALL in %s", recorder.sql[0])
+			}
+
+			anchorColumns := columns(t, anchor, false)
+			recursiveColumns := columns(t, recursive, true)
+			if len(anchorColumns) != len(recursiveColumns) {
+				t.Fatalf("anchor selects %v, recursive part selects %v", anchorColumns, recursiveColumns)
+			}
+
+			// plain columns must line up; computed ones such as the depth
+			// only need to be in the same place
+			for i, column := range anchorColumns {
+				_, name, qualified := strings.Cut(recursiveColumns[i], ".")
+				if qualified && !strings.ContainsAny(name, " +") && !strings.ContainsAny(column, " ") && name != column {
+					t.Errorf("column %d is %s in the anchor and %s in the recursive part", i+1, column, recursiveColumns[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	repo, recorder := dryRun(t)
+	const match = "brands.search_vector @@ to_tsquery('simple', 'gal:* & s2:*')"
+
+	tests := []struct {
+		name   string
+		count  bool
+		params dto.BrandPaginationDTO
+		want   []string
+		not    []string
+	}{
+		{
+			name:   "count",
+			count:  true,
+			params: dto.BrandPaginationDTO{Search: "Gal S2"},
+			want:   []string{"SELECT count(*)", match},
+		},
+		{
+			name:   "list by relevance",
+			params: dto.BrandPaginationDTO{PerPage: 10, Page: 1, Search: "gal-s2", OrderBy: []ordering.Key{{Name: "relevance", Desc: true}}},
+			want:   []string{match, "ORDER BY ts_rank(brands.search_vector, to_tsquery('simple', 'gal:* & s2:*')) DESC"},
+		},
+		{
+			name:   "operators are not passed through",
+			params: dto.BrandPaginationDTO{PerPage: 10, Page: 1, Search: "gal | !s2"},
+			want:   []string{match},
+		},
+		{
+			name:   "nothing searchable",
+			params: dto.BrandPaginationDTO{PerPage: 10, Page: 1, Search: " & "},
+			not:    []string{"search_vector", "ts_rank"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.sql = nil
+			if tt.count {
+				_, _ = repo.Count(&tt.params)
+			} else {
+				_, _ = repo.FindAll(&tt.params)
+			}
+			if len(recorder.sql) != 1 {
+				t.Fatalf("ran %d statements, want 1", len(recorder.sql))
+			}
+
+			for _, part := range tt.want {
+				if !strings.Contains(recorder.sql[0], part) {
+					t.Errorf("%s\nlacks %s", recorder.sql[0], part)
+				}
+			}
+			for _, part := range tt.not {
+				if strings.Contains(recorder.sql[0], part) {
+					t.Errorf("%s\ncontains %s", recorder.sql[0], part)
+				}
+			}
+		})
+	}
+}
+
+// TestFindAncestors runs the query against the database of POSTGRES_DSN, on a
+// temporary brands table that is dropped with the transaction.
+func TestFindAncestors(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	if err := tx.Exec(`
+		CREATE TEMPORARY TABLE brands (
+			id         bigserial PRIMARY KEY,
+			name       varchar(255) NOT NULL,
+			parent_id  bigint,
+			version    integer   NOT NULL DEFAULT 1,
+			created_at timestamp NOT NULL DEFAULT now(),
+			updated_at timestamp NOT NULL DEFAULT now(),
+			deleted_at timestamp
+		) ON COMMIT DROP`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	root := &entity.Brand{Name: "Root"}
+	if err := tx.Create(root).Error; err != nil {
+		t.Fatal(err)
+	}
+	child := &entity.Brand{Name: "Child", ParentId: &root.ID}
+	if err := tx.Create(child).Error; err != nil {
+		t.Fatal(err)
+	}
+	grandchild := &entity.Brand{Name: "Grandchild", ParentId: &child.ID}
+	if err := tx.Create(grandchild).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewBrandRepository(context.Background(), &config.DatabaseConfiguration{DB: tx}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		name string
+		id   uint
+		want []string
+	}{
+		{name: "root", id: root.ID, want: []string{}},
+		{name: "child", id: child.ID, want: []string{"Root"}},
+		{name: "grandchild", id: grandchild.ID, want: []string{"Root", "Child"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ancestors, err := repo.FindAncestors(tt.id)
+			if err != nil {
+				t.Fatalf("FindAncestors() error = %v", err)
+			}
+
+			names := make([]string, 0, len(ancestors))
+			for _, ancestor := range ancestors {
+				names = append(names, ancestor.Name)
+				if ancestor.Version != 1 {
+					t.Errorf("%s version = %d, want 1", ancestor.Name, ancestor.Version)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FindAncestors() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
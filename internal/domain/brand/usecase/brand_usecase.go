@@ -4,61 +4,124 @@ import (
 	"ecommerce/internal/domain/brand/dto"
 	"ecommerce/internal/domain/brand/entity"
 	"ecommerce/internal/domain/brand/repository"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/ordering"
+	"ecommerce/pkg/searchindex"
 	"errors"
 	"math"
+	"strconv"
+	"strings"
 )
 
 type IBrandUseCase interface {
 	FindAll(params *dto.BrandPaginationDTO) (int, int, []*dto.FindBrandDTO, error)
+	FindPage(params *dto.BrandPaginationDTO) ([]*dto.FindBrandDTO, *cursor.Page, error)
 	FindById(payload *dto.BrandWithIdDTO) (*dto.FindBrandDTO, error)
 	CreateBrand(payload *dto.CreateBrandDTO) error
 	UpdateBrand(payload *dto.UpdateBrandDTO) error
 	DeleteBrand(payload *dto.BrandWithIdDTO) error
+	FindDuplicateNames() ([]*dto.BrandDuplicateDTO, error)
+	FindChildren(payload *dto.BrandWithIdDTO) ([]*dto.FindBrandDTO, error)
+	Reindex() (int, error)
+	PruneIndex() (int, error)
+	BulkWrite(payload *dto.BulkBrandDTO, report *bulk.Report)
 }
 
+// ProductIndexer rewrites the search documents of the products of a brand,
+// which carry the brand name.
+type ProductIndexer interface {
+	IndexBrandProducts(brandId uint) error
+}
+
+// BrandUseCase mirrors brand writes into the search index. The database stays
+// the source of truth: a failed index write is logged by the index and
+// repaired by the reindex command. Renaming a brand rewrites the documents of
+// its products through products, which may be nil where brands are never
+// renamed.
 type BrandUseCase struct {
 	repository repository.IBrandRepository
+	index      searchindex.IIndex
+	products   ProductIndexer
 }
 
-func NewBrandUseCase(repository repository.IBrandRepository) *BrandUseCase {
+func NewBrandUseCase(repository repository.IBrandRepository, index searchindex.IIndex, products ProductIndexer) *BrandUseCase {
 	return &BrandUseCase{
 		repository: repository,
+		index:      index,
+		products:   products,
 	}
 }
 
 func (uc *BrandUseCase) CreateBrand(payload *dto.CreateBrandDTO) error {
+	_, err := uc.createBrand(payload)
+	return err
+}
+
+// createBrand creates the brand and returns its id.
+func (uc *BrandUseCase) createBrand(payload *dto.CreateBrandDTO) (uint, error) {
 	brand := &entity.Brand{
-		Name: payload.Name,
+		Name: strings.TrimSpace(payload.Name),
+	}
+
+	if payload.ParentId != nil {
+		parent, err := uc.repository.FindById(uint(*payload.ParentId))
+		if err != nil {
+			return 0, apperror.RecordNotFound(err, ErrParentBrandNotFound)
+		}
+
+		brand.ParentId = &parent.ID
 	}
 
 	err := uc.repository.Create(brand)
 	if err != nil {
-		return err
+		return 0, uc.conflictError(brand.Name, err)
 	}
 
-	return nil
+	_ = uc.index.Index(toSearchDocument(brand))
+	return brand.ID, nil
 }
 
 func (uc *BrandUseCase) UpdateBrand(payload *dto.UpdateBrandDTO) error {
 	brand := &entity.Brand{
-		Name: payload.Name,
+		Name: strings.TrimSpace(payload.Name),
 		ID:   uint(payload.ID),
 	}
 
 	brandExist, err := uc.repository.FindById(brand.ID)
 	if err != nil {
-		return err
+		return apperror.RecordNotFound(err, ErrBrandNotFound)
 	}
 
-	if brandExist == nil {
-		return errors.New("brand not found")
+	if !etag.Match(payload.IfMatch, brandExist.Version) {
+		return etag.ErrPreconditionFailed
+	}
+	brand.Version = brandExist.Version
+
+	// a PATCH that only moves the brand keeps its name
+	if brand.Name == "" {
+		brand.Name = brandExist.Name
+	}
+
+	brand.ParentId = brandExist.ParentId
+	if payload.ParentId != nil {
+		brand.ParentId, err = uc.resolveParent(brand.ID, uint(*payload.ParentId))
+		if err != nil {
+			return err
+		}
 	}
 
 	err = uc.repository.Update(brand)
 	if err != nil {
-		return err
+		return uc.conflictError(brand.Name, err)
 	}
 
+	_ = uc.index.Index(toSearchDocument(brand))
+	if brand.Name != brandExist.Name && uc.products != nil {
+		_ = uc.products.IndexBrandProducts(brand.ID)
+	}
 	return nil
 }
 
@@ -68,12 +131,22 @@ func (uc *BrandUseCase) DeleteBrand(payload *dto.BrandWithIdDTO) error {
 	}
 
 	brandExist, err := uc.repository.FindById(brand.ID)
+	if err != nil {
+		return apperror.RecordNotFound(err, ErrBrandNotFound)
+	}
+
+	if !etag.Match(payload.IfMatch, brandExist.Version) {
+		return etag.ErrPreconditionFailed
+	}
+	brand.Version = brandExist.Version
+
+	children, err := uc.repository.FindChildren(brand.ID, nil)
 	if err != nil {
 		return err
 	}
 
-	if brandExist == nil {
-		return errors.New("brand not found")
+	if len(children) > 0 {
+		return ErrBrandHasChildren
 	}
 
 	err = uc.repository.Delete(brand)
@@ -81,25 +154,54 @@ func (uc *BrandUseCase) DeleteBrand(payload *dto.BrandWithIdDTO) error {
 		return err
 	}
 
+	_ = uc.index.Delete(SearchDocumentType, strconv.FormatUint(uint64(brand.ID), 10))
 	return nil
 }
 
 func (uc *BrandUseCase) FindById(payload *dto.BrandWithIdDTO) (*dto.FindBrandDTO, error) {
+	brand, err := uc.repository.FindByIdWithFields(uint(payload.ID), payload.Selection)
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrBrandNotFound)
+	}
+
+	brandDto := toFindBrandDTO(brand)
+	if !payload.Selection.Include("path") {
+		return brandDto, nil
+	}
+
+	ancestors, err := uc.repository.FindAncestors(brand.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	brandDto.Path = make([]*dto.BrandPathDTO, 0, len(ancestors))
+	for _, a := range ancestors {
+		brandDto.Path = append(brandDto.Path, &dto.BrandPathDTO{
+			ID:   int64(a.ID),
+			Name: a.Name,
+		})
+	}
+
+	return brandDto, nil
+}
+
+func (uc *BrandUseCase) FindChildren(payload *dto.BrandWithIdDTO) ([]*dto.FindBrandDTO, error) {
 	brand, err := uc.repository.FindById(uint(payload.ID))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrBrandNotFound)
+	}
+
+	children, err := uc.repository.FindChildren(brand.ID, payload.Selection)
 	if err != nil {
 		return nil, err
 	}
 
-	if brand == nil {
-		return nil, errors.New("brand not found")
+	childrenDto := make([]*dto.FindBrandDTO, 0, len(children))
+	for _, child := range children {
+		childrenDto = append(childrenDto, toFindBrandDTO(child))
 	}
 
-	return &dto.FindBrandDTO{
-		ID:        int64(brand.ID),
-		Name:      brand.Name,
-		CreatedAt: brand.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: brand.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}, nil
+	return childrenDto, nil
 }
 
 func (uc *BrandUseCase) FindAll(params *dto.BrandPaginationDTO) (int, int, []*dto.FindBrandDTO, error) {
@@ -113,13 +215,7 @@ func (uc *BrandUseCase) FindAll(params *dto.BrandPaginationDTO) (int, int, []*dt
 		params.PerPage = 10
 	}
 
-	if params.Sort == "" {
-		params.Sort = "desc"
-	}
-
-	if params.SortBy == "" {
-		params.SortBy = "created_at"
-	}
+	defaultOrder(params)
 
 	brands, err := uc.repository.FindAll(params)
 	if err != nil {
@@ -128,17 +224,12 @@ func (uc *BrandUseCase) FindAll(params *dto.BrandPaginationDTO) (int, int, []*dt
 
 	if len(brands) > 0 {
 		for _, b := range brands {
-			brandsDto = append(brandsDto, &dto.FindBrandDTO{
-				ID:        int64(b.ID),
-				Name:      b.Name,
-				CreatedAt: b.CreatedAt.Format("2006-01-02 15:04:05"),
-				UpdatedAt: b.UpdatedAt.Format("2006-01-02 15:04:05"),
-			})
+			brandsDto = append(brandsDto, toFindBrandDTO(b))
 		}
 	}
 
 	totalPage := 0.0
-	count, err := uc.repository.Count()
+	count, err := uc.repository.Count(params)
 	if err != nil {
 		return 0, 0, brandsDto, err
 	}
@@ -146,3 +237,126 @@ func (uc *BrandUseCase) FindAll(params *dto.BrandPaginationDTO) (int, int, []*dt
 	totalPage = math.Ceil(float64(count) / float64(params.PerPage))
 	return int(count), int(totalPage), brandsDto, nil
 }
+
+// FindPage returns the page of brands around params.Cursor. The total is only
+// counted when params.WithCount is set.
+func (uc *BrandUseCase) FindPage(params *dto.BrandPaginationDTO) ([]*dto.FindBrandDTO, *cursor.Page, error) {
+	brandsDto := make([]*dto.FindBrandDTO, 0)
+	defaultOrder(params)
+
+	sort := ordering.Format(params.OrderBy)
+	if params.Cursor != nil && params.Cursor.Sort != sort {
+		return brandsDto, nil, cursor.ErrSortMismatch
+	}
+
+	brands, more, err := uc.repository.FindPage(params)
+	if err != nil {
+		return brandsDto, nil, err
+	}
+
+	for _, b := range brands {
+		brandsDto = append(brandsDto, toFindBrandDTO(b))
+	}
+
+	page := &cursor.Page{}
+	if len(brands) > 0 {
+		backward := params.Cursor != nil && params.Cursor.Backward
+		if more || backward {
+			page.Next = (&cursor.Cursor{ID: brands[len(brands)-1].ID, Sort: sort}).Encode()
+		}
+		if params.Cursor != nil && (more || !backward) {
+			page.Prev = (&cursor.Cursor{ID: brands[0].ID, Backward: true, Sort: sort}).Encode()
+		}
+	}
+
+	if params.WithCount {
+		count, err := uc.repository.Count(params)
+		if err != nil {
+			return brandsDto, nil, err
+		}
+		total := int(count)
+		page.Total = &total
+	}
+
+	return brandsDto, page, nil
+}
+
+func (uc *BrandUseCase) FindDuplicateNames() ([]*dto.BrandDuplicateDTO, error) {
+	return uc.repository.FindDuplicateNames()
+}
+
+// resolveParent validates a new parent for the brand. A parent id of 0 detaches
+// the brand; otherwise the parent must exist and must not be the brand itself
+// or one of its sub-brands.
+func (uc *BrandUseCase) resolveParent(id uint, parentId uint) (*uint, error) {
+	if parentId == 0 {
+		return nil, nil
+	}
+
+	if parentId == id {
+		return nil, ErrInvalidParent
+	}
+
+	parent, err := uc.repository.FindById(parentId)
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrParentBrandNotFound)
+	}
+
+	ancestors, err := uc.repository.FindAncestors(parent.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range ancestors {
+		if a.ID == id {
+			return nil, ErrInvalidParent
+		}
+	}
+
+	return &parent.ID, nil
+}
+
+// conflictError resolves a unique violation into ErrBrandNameTaken carrying
+// the id of the brand that already owns the name, or without it when that
+// brand cannot be looked up.
+func (uc *BrandUseCase) conflictError(name string, err error) error {
+	if !errors.Is(err, repository.ErrBrandNameTaken) {
+		return err
+	}
+
+	existing, findErr := uc.repository.FindByName(name)
+	if findErr != nil {
+		return ErrBrandNameTaken.Wrap(err)
+	}
+
+	return ErrBrandNameTaken.WithDetails(map[string]interface{}{"existing_id": existing.ID})
+}
+
+// defaultOrder sorts by relevance while searching and by newest first
+// otherwise.
+func defaultOrder(params *dto.BrandPaginationDTO) {
+	if len(params.OrderBy) == 0 && params.Search != "" {
+		params.OrderBy = []ordering.Key{dto.BrandSortFields.Key("relevance", true)}
+	}
+
+	if len(params.OrderBy) == 0 {
+		params.OrderBy = []ordering.Key{dto.BrandSortFields.Key("created_at", true)}
+	}
+}
+
+func toFindBrandDTO(brand *entity.Brand) *dto.FindBrandDTO {
+	var parentId *int64
+	if brand.ParentId != nil {
+		id := int64(*brand.ParentId)
+		parentId = &id
+	}
+
+	return &dto.FindBrandDTO{
+		ID:        int64(brand.ID),
+		Name:      brand.Name,
+		ParentId:  parentId,
+		Version:   brand.Version,
+		CreatedAt: brand.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: brand.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/brand/dto"
+	"ecommerce/internal/domain/brand/entity"
+	"ecommerce/internal/domain/brand/repository"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/searchindex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// brandTree keeps brands in memory and records the last update.
+type brandTree struct {
+	repository.IBrandRepository
+	brands  map[uint]*entity.Brand
+	updated *entity.Brand
+}
+
+func (t *brandTree) FindById(id uint) (*entity.Brand, error) {
+	brand, ok := t.brands[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	found := *brand
+	return &found, nil
+}
+
+func (t *brandTree) FindAncestors(id uint) ([]*entity.Brand, error) {
+	ancestors := make([]*entity.Brand, 0)
+	for brand := t.brands[id]; brand != nil; {
+		ancestors = append(ancestors, brand)
+		if brand.ParentId == nil {
+			break
+		}
+		brand = t.brands[*brand.ParentId]
+	}
+	return ancestors, nil
+}
+
+func (t *brandTree) Update(brand *entity.Brand) error {
+	t.updated = brand
+	return nil
+}
+
+func TestUpdateBrandParent(t *testing.T) {
+	parentOf := func(id uint) *uint { return &id }
+	moveTo := func(id int64) *int64 { return &id }
+
+	tests := []struct {
+		name       string
+		payload    dto.UpdateBrandDTO
+		wantErr    error
+		wantName   string
+		wantParent *uint
+	}{
+		{name: "move keeps name", payload: dto.UpdateBrandDTO{ID: 3, ParentId: moveTo(2)}, wantName: "Galaxy", wantParent: parentOf(2)},
+		{name: "rename keeps parent", payload: dto.UpdateBrandDTO{ID: 3, Name: " Galaxy S "}, wantName: "Galaxy S", wantParent: parentOf(1)},
+		{name: "zero detaches", payload: dto.UpdateBrandDTO{ID: 3, ParentId: moveTo(0)}, wantName: "Galaxy"},
+		{name: "own parent", payload: dto.UpdateBrandDTO{ID: 3, ParentId: moveTo(3)}, wantErr: ErrInvalidParent},
+		{name: "under sub-brand", payload: dto.UpdateBrandDTO{ID: 1, ParentId: moveTo(3)}, wantErr: ErrInvalidParent},
+		{name: "missing parent", payload: dto.UpdateBrandDTO{ID: 3, ParentId: moveTo(9)}, wantErr: ErrParentBrandNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := &brandTree{brands: map[uint]*entity.Brand{
+				1: {ID: 1, Name: "Samsung", Version: 1},
+				2: {ID: 2, Name: "Apple", Version: 1},
+				3: {ID: 3, Name: "Galaxy", ParentId: parentOf(1), Version: 4},
+			}}
+			uc := NewBrandUseCase(tree, searchindex.NopIndex{}, nil)
+
+			err := uc.UpdateBrand(&tt.payload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("UpdateBrand() error = %v, want %v", err, tt.wantErr)
+				}
+				if tree.updated != nil {
+					t.Errorf("UpdateBrand() wrote %+v after rejecting the parent", tree.updated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateBrand() error = %v", err)
+			}
+
+			got := tree.updated
+			if got.Name != tt.wantName {
+				t.Errorf("name = %q, want %q", got.Name, tt.wantName)
+			}
+			if (got.ParentId == nil) != (tt.wantParent == nil) || got.ParentId != nil && *got.ParentId != *tt.wantParent {
+				t.Errorf("parent = %v, want %v", got.ParentId, tt.wantParent)
+			}
+			if got.Version != 4 {
+				t.Errorf("version = %d, want the stored 4", got.Version)
+			}
+		})
+	}
+}
+
+// unfindableBrands fails every lookup by name, as when the brand holding the
+// name was deleted in the meantime.
+type unfindableBrands struct {
+	*brandTable
+}
+
+func (u unfindableBrands) FindByName(name string) (*entity.Brand, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestCreateBrandNameTaken(t *testing.T) {
+	tests := []struct {
+		name        string
+		repository  func(table *brandTable) repository.IBrandRepository
+		wantDetails map[string]interface{}
+	}{
+		{
+			name:        "existing brand found",
+			repository:  func(table *brandTable) repository.IBrandRepository { return table },
+			wantDetails: map[string]interface{}{"existing_id": uint(1)},
+		},
+		{
+			name:       "existing brand not found",
+			repository: func(table *brandTable) repository.IBrandRepository { return unfindableBrands{table} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &brandTable{nextId: 1, rows: map[uint]entity.Brand{1: {ID: 1, Name: "Sony"}}}
+			uc := NewBrandUseCase(tt.repository(table), searchindex.NopIndex{}, nil)
+
+			err := uc.CreateBrand(&dto.CreateBrandDTO{Name: " Sony "})
+
+			var appErr *apperror.Error
+			if !errors.As(err, &appErr) || appErr.Code != ErrBrandNameTaken.Code || appErr.Kind != apperror.KindConflict {
+				t.Fatalf("CreateBrand() error = %v, want %v", err, ErrBrandNameTaken)
+			}
+			if !reflect.DeepEqual(appErr.Details(), tt.wantDetails) {
+				t.Errorf("details = %v, want %v", appErr.Details(), tt.wantDetails)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/brand/dto"
+	"ecommerce/internal/domain/brand/repository"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/searchindex"
+	"strconv"
+)
+
+// BulkWrite applies the operations in order and records each outcome in the
+// report. Atomic mode runs them in one transaction that is rolled back by the
+// first failure, and does not start when validation already rejected an
+// operation; the search index is only written once the transaction commits.
+// Best effort mode applies every operation that succeeds.
+func (uc *BrandUseCase) BulkWrite(payload *dto.BulkBrandDTO, report *bulk.Report) {
+	if payload.Mode == bulk.ModeBestEffort {
+		for _, op := range payload.Operations {
+			id, err := uc.applyBulk(op)
+			report.Record(op.Index, int64(id), err)
+		}
+		return
+	}
+
+	if report.Failed > 0 {
+		return
+	}
+
+	renamed := &renamedBrands{}
+	err := uc.repository.WithTransaction(func(repository repository.IBrandRepository) error {
+		tx := NewBrandUseCase(repository, searchindex.NopIndex{}, renamed)
+		for _, op := range payload.Operations {
+			id, err := tx.applyBulk(op)
+			report.Record(op.Index, int64(id), err)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		report.RollBack()
+		return
+	}
+
+	for _, result := range report.Results {
+		if result.Op == bulk.OpDelete {
+			_ = uc.index.Delete(SearchDocumentType, strconv.FormatInt(*result.ID, 10))
+			continue
+		}
+
+		brand, err := uc.repository.FindById(uint(*result.ID))
+		if err == nil {
+			_ = uc.index.Index(toSearchDocument(brand))
+		}
+	}
+
+	if uc.products != nil {
+		for _, brandId := range *renamed {
+			_ = uc.products.IndexBrandProducts(brandId)
+		}
+	}
+}
+
+// renamedBrands collects the brands renamed in a transaction, so that the
+// documents of their products are rewritten once it commits.
+type renamedBrands []uint
+
+func (r *renamedBrands) IndexBrandProducts(brandId uint) error {
+	*r = append(*r, brandId)
+	return nil
+}
+
+// applyBulk runs one operation and returns the id of the brand it wrote.
+func (uc *BrandUseCase) applyBulk(op *dto.BulkBrandOperationDTO) (uint, error) {
+	switch op.Op {
+	case bulk.OpCreate:
+		return uc.createBrand(op.Create)
+	case bulk.OpUpdate:
+		op.Update.ID = op.ID
+		return uint(op.ID), uc.UpdateBrand(op.Update)
+	default:
+		return uint(op.ID), uc.DeleteBrand(&dto.BrandWithIdDTO{ID: op.ID})
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/brand/dto"
+	"ecommerce/internal/domain/brand/entity"
+	"ecommerce/internal/domain/brand/repository"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/searchindex"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// brandTable is an in-memory brands table whose transactions only keep their
+// writes when they commit.
+type brandTable struct {
+	repository.IBrandRepository
+	rows   map[uint]entity.Brand
+	nextId uint
+}
+
+func (b *brandTable) FindById(id uint) (*entity.Brand, error) {
+	brand, ok := b.rows[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &brand, nil
+}
+
+func (b *brandTable) FindByName(name string) (*entity.Brand, error) {
+	for _, brand := range b.rows {
+		if brand.Name == name {
+			return &brand, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (b *brandTable) FindChildren(id uint, selection *fields.Selection) ([]*entity.Brand, error) {
+	return make([]*entity.Brand, 0), nil
+}
+
+func (b *brandTable) Create(brand *entity.Brand) error {
+	if _, err := b.FindByName(brand.Name); err == nil {
+		return repository.ErrBrandNameTaken
+	}
+	b.nextId++
+	brand.ID = b.nextId
+	b.rows[brand.ID] = *brand
+	return nil
+}
+
+func (b *brandTable) Update(brand *entity.Brand) error {
+	b.rows[brand.ID] = *brand
+	return nil
+}
+
+func (b *brandTable) Delete(brand *entity.Brand) error {
+	delete(b.rows, brand.ID)
+	return nil
+}
+
+func (b *brandTable) WithTransaction(fn func(repository repository.IBrandRepository) error) error {
+	tx := &brandTable{rows: maps.Clone(b.rows), nextId: b.nextId}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	b.rows, b.nextId = tx.rows, tx.nextId
+	return nil
+}
+
+// indexed records the documents written to and deleted from the index.
+type indexed struct {
+	searchindex.NopIndex
+	written []string
+	deleted []string
+}
+
+func (i *indexed) Index(docs ...*searchindex.Document) error {
+	for _, doc := range docs {
+		i.written = append(i.written, doc.ID)
+	}
+	return nil
+}
+
+func (i *indexed) Delete(docType string, id string) error {
+	i.deleted = append(i.deleted, id)
+	return nil
+}
+
+func TestBulkWrite(t *testing.T) {
+	operations := func() []*dto.BulkBrandOperationDTO {
+		return []*dto.BulkBrandOperationDTO{
+			{Index: 0, Op: bulk.OpCreate, Create: &dto.CreateBrandDTO{Name: "Sony"}},
+			{Index: 1, Op: bulk.OpUpdate, ID: 1, Update: &dto.UpdateBrandDTO{Name: "LG Electronics"}},
+			{Index: 2, Op: bulk.OpCreate, Create: &dto.CreateBrandDTO{Name: "Apple"}},
+			{Index: 3, Op: bulk.OpDelete, ID: 2},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mode        string
+		wantStatus  []string
+		wantBrands  []string
+		wantWritten []string
+		wantDeleted []string
+	}{
+		{
+			name:        "best effort keeps what succeeded",
+			mode:        bulk.ModeBestEffort,
+			wantStatus:  []string{bulk.StatusSucceeded, bulk.StatusSucceeded, bulk.StatusFailed, bulk.StatusSucceeded},
+			wantBrands:  []string{"Apple", "LG Electronics", "Sony"},
+			wantWritten: []string{"4", "1"},
+			wantDeleted: []string{"2"},
+		},
+		{
+			name:       "atomic stops at the first failure",
+			mode:       bulk.ModeAtomic,
+			wantStatus: []string{bulk.StatusRolledBack, bulk.StatusRolledBack, bulk.StatusFailed, bulk.StatusSkipped},
+			wantBrands: []string{"Apple", "LG", "Samsung"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &brandTable{nextId: 3, rows: map[uint]entity.Brand{
+				1: {ID: 1, Name: "LG"},
+				2: {ID: 2, Name: "Samsung"},
+				3: {ID: 3, Name: "Apple"},
+			}}
+			index := &indexed{}
+			uc := NewBrandUseCase(table, index, nil)
+
+			ops := operations()
+			request := &bulk.Request{Mode: tt.mode}
+			for _, op := range ops {
+				request.Operations = append(request.Operations, &bulk.Operation{Op: op.Op, ID: op.ID})
+			}
+			report := bulk.NewReport(request)
+			uc.BulkWrite(&dto.BulkBrandDTO{Mode: tt.mode, Operations: ops}, report)
+
+			status := make([]string, 0, len(report.Results))
+			for _, result := range report.Results {
+				status = append(status, result.Status)
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("statuses = %v, want %v", status, tt.wantStatus)
+			}
+			if code := report.Results[2].Errors.(map[string]interface{})["code"]; code != ErrBrandNameTaken.Code {
+				t.Errorf("duplicate create failed with %v, want %s", code, ErrBrandNameTaken.Code)
+			}
+
+			names := make([]string, 0, len(table.rows))
+			for _, brand := range table.rows {
+				names = append(names, brand.Name)
+			}
+			if slices.Sort(names); !reflect.DeepEqual(names, tt.wantBrands) {
+				t.Errorf("brands = %v, want %v", names, tt.wantBrands)
+			}
+			if !reflect.DeepEqual(index.written, tt.wantWritten) || !reflect.DeepEqual(index.deleted, tt.wantDeleted) {
+				t.Errorf("indexed %v and deleted %v, want %v and %v", index.written, index.deleted, tt.wantWritten, tt.wantDeleted)
+			}
+		})
+	}
+}
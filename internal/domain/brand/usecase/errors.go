@@ -0,0 +1,15 @@
+package usecase
+
+import "ecommerce/pkg/apperror"
+
+var (
+	ErrBrandNotFound       = apperror.NotFound("brand_not_found", "brand not found")
+	ErrParentBrandNotFound = apperror.Unprocessable("parent_brand_not_found", "parent brand not found")
+	// ErrInvalidParent is returned for a parent that is the brand itself or
+	// one of its sub-brands.
+	ErrInvalidParent    = apperror.Validation("invalid_parent_brand", "parent brand would create a cycle")
+	ErrBrandHasChildren = apperror.Conflict("brand_has_sub_brands", "brand still has sub-brands")
+	// ErrBrandNameTaken carries the existing_id of the brand that already
+	// owns the name after normalization.
+	ErrBrandNameTaken = apperror.Conflict("brand_name_taken", "brand already exists")
+)
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/brand/dto"
+	"ecommerce/internal/domain/brand/entity"
+	"ecommerce/pkg/ordering"
+	"ecommerce/pkg/searchindex"
+	"strconv"
+)
+
+const SearchDocumentType = "brand"
+
+func toSearchDocument(brand *entity.Brand) *searchindex.Document {
+	keywords := make(map[string][]string)
+	if brand.ParentId != nil {
+		keywords["parent_id"] = []string{strconv.FormatUint(uint64(*brand.ParentId), 10)}
+	}
+
+	return &searchindex.Document{
+		ID:       strconv.FormatUint(uint64(brand.ID), 10),
+		Type:     SearchDocumentType,
+		Text:     map[string]string{"name": brand.Name},
+		Keywords: keywords,
+	}
+}
+
+// IndexBrand reloads the brand and writes it to the search index, e.g. once
+// the transaction of another use case that created it has committed.
+func (uc *BrandUseCase) IndexBrand(id uint) {
+	brand, err := uc.repository.FindById(id)
+	if err != nil {
+		return
+	}
+	_ = uc.index.Index(toSearchDocument(brand))
+}
+
+// Reindex writes every brand to the search index and returns how many were
+// indexed.
+func (uc *BrandUseCase) Reindex() (int, error) {
+	params := &dto.BrandPaginationDTO{
+		PerPage: int64(constants.ReindexBatchSize),
+		Page:    1,
+		OrderBy: []ordering.Key{dto.BrandSortFields.Key("id", false)},
+	}
+
+	total := 0
+	for {
+		brands, err := uc.repository.FindAll(params)
+		if err != nil {
+			return total, err
+		}
+
+		docs := make([]*searchindex.Document, 0, len(brands))
+		for _, brand := range brands {
+			docs = append(docs, toSearchDocument(brand))
+		}
+
+		if err := uc.index.Index(docs...); err != nil {
+			return total, err
+		}
+
+		total += len(brands)
+		if len(brands) < int(params.PerPage) {
+			return total, nil
+		}
+		params.Page++
+	}
+}
+
+// PruneIndex deletes the documents of brands that no longer exist and returns
+// how many were deleted.
+func (uc *BrandUseCase) PruneIndex() (int, error) {
+	return searchindex.Prune(uc.index, SearchDocumentType, constants.ReindexBatchSize, uc.repository.FindExistingIds)
+}
@@ -0,0 +1,22 @@
+package collection
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/collection/presenter"
+	CollectionRepository "ecommerce/internal/domain/collection/repository"
+	CollectionUseCase "ecommerce/internal/domain/collection/usecase"
+	ProductDeps "ecommerce/internal/domain/product"
+	"log/slog"
+)
+
+func NewCollectionDependency(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) presenter.ICollectionPresenter {
+	collectionRepository := CollectionRepository.NewCollectionRepository(ctx, dbProvider, logger)
+	productUseCase := ProductDeps.NewProductUseCase(ctx, dbProvider, logger)
+	useCase := CollectionUseCase.NewCollectionUseCase(collectionRepository, productUseCase)
+	return presenter.NewCollectionPresenter(useCase)
+}
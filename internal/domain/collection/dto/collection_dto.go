@@ -0,0 +1,58 @@
+package dto
+
+import "ecommerce/pkg/ordering"
+
+type CollectionRuleDTO struct {
+	BrandIds         []int64  `json:"brand_ids" validate:"omitempty,dive,gt=0"`
+	IncludeSubBrands bool     `json:"include_sub_brands"`
+	MinPrice         *int     `json:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice         *int     `json:"max_price" validate:"omitempty,gte=0"`
+	InStock          *bool    `json:"in_stock"`
+	Tags             []string `json:"tags" validate:"omitempty,dive,max=50"`
+	TagMatch         string   `json:"tag_match" validate:"omitempty,oneof=any all"`
+}
+
+type CreateCollectionDTO struct {
+	Name       string             `json:"name" validate:"required,max=100"`
+	Type       string             `json:"type" validate:"required,oneof=manual smart"`
+	Rule       *CollectionRuleDTO `json:"rule" validate:"required_if=Type smart"`
+	ProductIds []int64            `json:"product_ids" validate:"omitempty,dive,gt=0"`
+}
+
+// UpdateCollectionDTO changes only the fields present in the body. ProductIds
+// replaces the ordered product list of a manual collection.
+type UpdateCollectionDTO struct {
+	ID         int64              `json:"id" swaggerignore:"true"`
+	Name       string             `json:"name" validate:"omitempty,max=100"`
+	Type       string             `json:"type" validate:"omitempty,oneof=manual smart"`
+	Rule       *CollectionRuleDTO `json:"rule"`
+	ProductIds []int64            `json:"product_ids" validate:"omitempty,dive,gt=0"`
+}
+
+type CollectionWithIdDTO struct {
+	ID int64 `json:"id" form:"id" param:"id" query:"id"`
+}
+
+type FindCollectionDTO struct {
+	ID         int64              `json:"id"`
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	Rule       *CollectionRuleDTO `json:"rule,omitempty"`
+	ProductIds []int64            `json:"product_ids,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+	UpdatedAt  string             `json:"updated_at"`
+}
+
+type CollectionPaginationDTO struct {
+	PerPage int64          `json:"per_page" query:"per_page" validate:"required,number"`
+	Page    int64          `json:"page" query:"page" validate:"required,number"`
+	OrderBy []ordering.Key `json:"-" swaggerignore:"true"`
+}
+
+// CollectionSortFields lists the names the collection list can be sorted by.
+var CollectionSortFields = ordering.Registry{
+	"id":         {Table: "collections", Name: "id"},
+	"name":       {Table: "collections", Name: "name"},
+	"created_at": {Table: "collections", Name: "created_at"},
+	"updated_at": {Table: "collections", Name: "updated_at"},
+}
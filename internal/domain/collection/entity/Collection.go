@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	CollectionTypeManual = "manual"
+	CollectionTypeSmart  = "smart"
+)
+
+type Collection struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+	Name      string
+	Type      string
+	Rule      *CollectionRule
+	Products  []CollectionProduct `gorm:"foreignKey:CollectionId"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+func (c *Collection) BeforeCreate(tx *gorm.DB) error {
+	c.CreatedAt = time.Now()
+	return nil
+}
+
+func (c *Collection) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// CollectionProduct is an explicit, ordered member of a manual collection.
+type CollectionProduct struct {
+	CollectionId uint `gorm:"primaryKey"`
+	ProductId    uint `gorm:"primaryKey"`
+	Position     int
+}
+
+func (CollectionProduct) TableName() string {
+	return "collection_products"
+}
+
+// CollectionRule is the stored condition of a smart collection. Every set
+// field narrows the result; it is evaluated against products at query time.
+type CollectionRule struct {
+	BrandIds         []int64  `json:"brand_ids,omitempty"`
+	IncludeSubBrands bool     `json:"include_sub_brands,omitempty"`
+	MinPrice         *int     `json:"min_price,omitempty"`
+	MaxPrice         *int     `json:"max_price,omitempty"`
+	InStock          *bool    `json:"in_stock,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	TagMatch         string   `json:"tag_match,omitempty"`
+}
+
+func (r CollectionRule) Value() (driver.Value, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (r *CollectionRule) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return errors.New("unsupported collection rule value")
+	}
+}
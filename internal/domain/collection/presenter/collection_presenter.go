@@ -0,0 +1,256 @@
+package presenter
+
+import (
+	"ecommerce/internal/domain/collection/dto"
+	"ecommerce/internal/domain/collection/usecase"
+	ProductDto "ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/apperror"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+
+	"ecommerce/pkg/ordering"
+	HttpResponser "ecommerce/pkg/response"
+)
+
+type ICollectionPresenter interface {
+	GetAll(c echo.Context) error
+	Get(c echo.Context) error
+	GetProducts(c echo.Context) error
+	Create(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type CollectionPresenter struct {
+	useCase usecase.ICollectionUseCase
+}
+
+func NewCollectionPresenter(useCase usecase.ICollectionUseCase) *CollectionPresenter {
+	return &CollectionPresenter{
+		useCase: useCase,
+	}
+}
+
+// GetAll godoc
+// @Summary      Get All collection
+// @Description  Get All collection data
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 per_page query int true "item per page count"
+// @Param 		 page query int true "page"
+// @Param 		 sort query string false "comma separated sort fields (id, name, created_at, updated_at), prefixed by - for descending, e.g. name,-created_at (default -created_at)"
+// @Param 		 SortBy query string false "deprecated, use sort"
+// @Param 		 Sort query string false "deprecated, direction of SortBy (desc, asc)"
+// @Success      200  {object}  response.PaginationResponse{data=[]dto.FindCollectionDTO}
+// @Router       /collections [get]
+func (presenter *CollectionPresenter) GetAll(c echo.Context) error {
+	params := &dto.CollectionPaginationDTO{}
+	perPage, err := strconv.ParseInt(c.QueryParam("per_page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	page, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	params.OrderBy, err = dto.CollectionSortFields.Parse(ordering.FromQuery(c.QueryParams()))
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(map[string]interface{}{"sort": err})
+	}
+
+	params.PerPage = perPage
+	params.Page = page
+
+	if err := c.Validate(params); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	count, totalPage, collections, err := presenter.useCase.FindAll(params)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Pagination(c, count, totalPage, int(params.PerPage), int(params.Page), collections)
+}
+
+// Get godoc
+// @Summary      Get collection
+// @Description  Get collection data
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "collection id"
+// @Success      200  {object}  response.SuccessResponse{data=dto.FindCollectionDTO}
+// @Router       /collections/{id} [get]
+func (presenter *CollectionPresenter) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	collection, err := presenter.useCase.FindById(&dto.CollectionWithIdDTO{ID: id})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get collection success", collection)
+}
+
+// GetProducts godoc
+// @Summary      Get collection products
+// @Description  Get products of a manual or smart collection
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "collection id"
+// @Param 		 per_page query int true "item per page count"
+// @Param 		 page query int true "page"
+// @Param 		 sort query string false "comma separated sort fields (id, name, price, qty, created_at, updated_at, relevance), prefixed by - for descending, e.g. -price,name (default -created_at, after the collection position)"
+// @Param 		 SortBy query string false "deprecated, use sort"
+// @Param 		 Sort query string false "deprecated, direction of SortBy (desc, asc)"
+// @Success      200  {object}  response.PaginationResponse{data=[]ProductDto.FindProductDTO}
+// @Router       /collections/{id}/products [get]
+func (presenter *CollectionPresenter) GetProducts(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	params := &ProductDto.ProductPaginationDTO{}
+	perPage, err := strconv.ParseInt(c.QueryParam("per_page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	page, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	params.OrderBy, err = ProductDto.ProductSortFields.Parse(ordering.FromQuery(c.QueryParams()))
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(map[string]interface{}{"sort": err})
+	}
+
+	params.PerPage = perPage
+	params.Page = page
+
+	if err := c.Validate(params); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	count, totalPage, products, err := presenter.useCase.FindProducts(&dto.CollectionWithIdDTO{ID: id}, params)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Pagination(c, count, totalPage, int(params.PerPage), int(params.Page), products)
+}
+
+// Create godoc
+// @Summary      Create collection
+// @Description  Create new manual or smart collection
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 request body dto.CreateCollectionDTO true "request body"
+// @Success      201  {object}  response.SuccessResponse{data=nil}
+// @Router       /collections [post]
+func (presenter *CollectionPresenter) Create(c echo.Context) error {
+	payload := dto.CreateCollectionDTO{}
+	if err := c.Bind(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := presenter.useCase.CreateCollection(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusCreated, "Collection created", nil)
+}
+
+// Update godoc
+// @Summary      Update collection
+// @Description  Update collection data
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "collection id"
+// @Param 		 request body dto.UpdateCollectionDTO true "request body"
+// @Success      200  {object}  response.SuccessResponse{data=nil}
+// @Router       /collections/{id} [patch]
+func (presenter *CollectionPresenter) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	payload := dto.UpdateCollectionDTO{}
+	if err := c.Bind(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	payload.ID = id
+
+	if err := c.Validate(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := presenter.useCase.UpdateCollection(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Collection updated", nil)
+}
+
+// Delete godoc
+// @Summary      Delete collection
+// @Description  Delete collection data
+// @Tags         collection
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "collection id"
+// @Success      200  {object}  response.SuccessResponse{data=nil}
+// @Router       /collections/{id} [delete]
+func (presenter *CollectionPresenter) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	if err := presenter.useCase.DeleteCollection(&dto.CollectionWithIdDTO{ID: id}); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Collection deleted", nil)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/collection/dto"
+	"ecommerce/internal/domain/collection/entity"
+	"ecommerce/pkg/ordering"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+//go:generate mockgen -source=collection_repository.go -destination=mocks/collection_repository_mock.go -package=mocks
+type ICollectionRepository interface {
+	Count() (int64, error)
+	FindAll(params *dto.CollectionPaginationDTO) ([]*entity.Collection, error)
+	FindById(id uint) (*entity.Collection, error)
+	Create(collection *entity.Collection) error
+	Update(collection *entity.Collection) error
+	Delete(collection *entity.Collection) error
+}
+
+type CollectionRepository struct {
+	dbProvider *config.DatabaseConfiguration
+	ctx        context.Context
+	logger     *slog.Logger
+}
+
+func NewCollectionRepository(ctx context.Context, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) *CollectionRepository {
+	return &CollectionRepository{
+		dbProvider: dbProvider,
+		ctx:        ctx,
+		logger:     logger,
+	}
+}
+
+func (repo *CollectionRepository) Count() (int64, error) {
+	var count int64
+	if err := repo.dbProvider.WithContext(repo.ctx).Model(&entity.Collection{}).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (repo *CollectionRepository) FindAll(params *dto.CollectionPaginationDTO) ([]*entity.Collection, error) {
+	collections := make([]*entity.Collection, 0)
+	qw := repo.dbProvider.WithContext(repo.ctx).
+		Model(&collections).
+		Limit(int(params.PerPage)).
+		Offset(int(params.PerPage * (params.Page - 1))).
+		Order(ordering.OrderBy(params.OrderBy, clause.Column{Table: "collections", Name: "id"}, nil))
+
+	if err := qw.Find(&collections).Error; err != nil {
+		return make([]*entity.Collection, 0), err
+	}
+
+	return collections, nil
+}
+
+func (repo *CollectionRepository) FindById(id uint) (*entity.Collection, error) {
+	var collection *entity.Collection
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		Preload("Products", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position asc")
+		}).
+		First(&collection, "id = ?", id).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return nil, err
+	}
+	return collection, nil
+}
+
+func (repo *CollectionRepository) Create(collection *entity.Collection) error {
+	tx := repo.dbProvider.Begin()
+	if err := tx.WithContext(repo.ctx).Create(collection).Error; err != nil {
+		tx.Rollback()
+		repo.logger.Error(err.Error())
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+// Update saves the collection and, when Products is not nil, replaces its
+// ordered product list.
+func (repo *CollectionRepository) Update(collection *entity.Collection) error {
+	tx := repo.dbProvider.Begin()
+	if err := tx.WithContext(repo.ctx).Omit("Products").Save(collection).Error; err != nil {
+		tx.Rollback()
+		repo.logger.Error(err.Error())
+		return err
+	}
+
+	if collection.Products != nil {
+		if err := tx.WithContext(repo.ctx).Where("collection_id = ?", collection.ID).Delete(&entity.CollectionProduct{}).Error; err != nil {
+			tx.Rollback()
+			repo.logger.Error(err.Error())
+			return err
+		}
+
+		for i := range collection.Products {
+			collection.Products[i].CollectionId = collection.ID
+		}
+
+		if len(collection.Products) > 0 {
+			if err := tx.WithContext(repo.ctx).Create(&collection.Products).Error; err != nil {
+				tx.Rollback()
+				repo.logger.Error(err.Error())
+				return err
+			}
+		}
+	}
+	tx.Commit()
+	return nil
+}
+
+func (repo *CollectionRepository) Delete(collection *entity.Collection) error {
+	tx := repo.dbProvider.Begin()
+	if err := tx.WithContext(repo.ctx).Delete(collection).Error; err != nil {
+		tx.Rollback()
+		repo.logger.Error(err.Error())
+		return err
+	}
+	tx.Commit()
+	return nil
+}
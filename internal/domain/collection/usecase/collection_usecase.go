@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/collection/dto"
+	"ecommerce/internal/domain/collection/entity"
+	"ecommerce/internal/domain/collection/repository"
+	ProductDto "ecommerce/internal/domain/product/dto"
+	ProductUseCase "ecommerce/internal/domain/product/usecase"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/ordering"
+	"math"
+)
+
+type ICollectionUseCase interface {
+	FindAll(params *dto.CollectionPaginationDTO) (int, int, []*dto.FindCollectionDTO, error)
+	FindById(payload *dto.CollectionWithIdDTO) (*dto.FindCollectionDTO, error)
+	FindProducts(payload *dto.CollectionWithIdDTO, params *ProductDto.ProductPaginationDTO) (int, int, []*ProductDto.FindProductDTO, error)
+	CreateCollection(payload *dto.CreateCollectionDTO) error
+	UpdateCollection(payload *dto.UpdateCollectionDTO) error
+	DeleteCollection(payload *dto.CollectionWithIdDTO) error
+}
+
+type CollectionUseCase struct {
+	repository     repository.ICollectionRepository
+	productUseCase ProductUseCase.IProductUseCase
+}
+
+func NewCollectionUseCase(
+	repository repository.ICollectionRepository,
+	productUseCase ProductUseCase.IProductUseCase,
+) *CollectionUseCase {
+	return &CollectionUseCase{
+		repository:     repository,
+		productUseCase: productUseCase,
+	}
+}
+
+func (uc *CollectionUseCase) FindAll(params *dto.CollectionPaginationDTO) (int, int, []*dto.FindCollectionDTO, error) {
+	collectionsDto := make([]*dto.FindCollectionDTO, 0)
+
+	if params.Page == 0 {
+		params.Page = 1
+	}
+
+	if params.PerPage == 0 {
+		params.PerPage = 10
+	}
+
+	if len(params.OrderBy) == 0 {
+		params.OrderBy = []ordering.Key{dto.CollectionSortFields.Key("created_at", true)}
+	}
+
+	collections, err := uc.repository.FindAll(params)
+	if err != nil {
+		return 0, 0, make([]*dto.FindCollectionDTO, 0), err
+	}
+
+	for _, c := range collections {
+		collectionsDto = append(collectionsDto, toFindCollectionDTO(c))
+	}
+
+	totalPage := 0.0
+	count, err := uc.repository.Count()
+	if err != nil {
+		return 0, 0, collectionsDto, err
+	}
+
+	totalPage = math.Ceil(float64(count) / float64(params.PerPage))
+	return int(count), int(totalPage), collectionsDto, nil
+}
+
+func (uc *CollectionUseCase) FindById(payload *dto.CollectionWithIdDTO) (*dto.FindCollectionDTO, error) {
+	collection, err := uc.repository.FindById(uint(payload.ID))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrCollectionNotFound)
+	}
+
+	return toFindCollectionDTO(collection), nil
+}
+
+// FindProducts lists the products of a collection through the product use
+// case. Manual collections keep their stored order; smart collections
+// translate their rule into product filters at query time.
+func (uc *CollectionUseCase) FindProducts(
+	payload *dto.CollectionWithIdDTO,
+	params *ProductDto.ProductPaginationDTO,
+) (int, int, []*ProductDto.FindProductDTO, error) {
+	collection, err := uc.repository.FindById(uint(payload.ID))
+	if err != nil {
+		return 0, 0, make([]*ProductDto.FindProductDTO, 0), apperror.RecordNotFound(err, ErrCollectionNotFound)
+	}
+
+	switch collection.Type {
+	case entity.CollectionTypeManual:
+		params.CollectionId = int64(collection.ID)
+	case entity.CollectionTypeSmart:
+		rule := collection.Rule
+		if rule == nil {
+			rule = &entity.CollectionRule{}
+		}
+
+		params.BrandIds = rule.BrandIds
+		params.IncludeSubBrands = rule.IncludeSubBrands
+		params.MinPrice = rule.MinPrice
+		params.MaxPrice = rule.MaxPrice
+		params.InStock = rule.InStock
+		params.Tags = rule.Tags
+		params.TagMatch = rule.TagMatch
+	}
+
+	return uc.productUseCase.FindAll(params)
+}
+
+func (uc *CollectionUseCase) CreateCollection(payload *dto.CreateCollectionDTO) error {
+	collection := &entity.Collection{
+		Name: payload.Name,
+		Type: payload.Type,
+	}
+
+	switch payload.Type {
+	case entity.CollectionTypeSmart:
+		if err := validateRule(payload.Rule); err != nil {
+			return err
+		}
+		collection.Rule = toCollectionRule(payload.Rule)
+	case entity.CollectionTypeManual:
+		collection.Products = toCollectionProducts(payload.ProductIds)
+	}
+
+	return uc.repository.Create(collection)
+}
+
+func (uc *CollectionUseCase) UpdateCollection(payload *dto.UpdateCollectionDTO) error {
+	collection, err := uc.repository.FindById(uint(payload.ID))
+	if err != nil {
+		return apperror.RecordNotFound(err, ErrCollectionNotFound)
+	}
+
+	if payload.Name != "" {
+		collection.Name = payload.Name
+	}
+
+	if payload.Type != "" {
+		collection.Type = payload.Type
+	}
+
+	collection.Products = nil
+	switch collection.Type {
+	case entity.CollectionTypeSmart:
+		if payload.Rule != nil {
+			collection.Rule = toCollectionRule(payload.Rule)
+		}
+
+		if collection.Rule == nil {
+			return ErrRuleRequired
+		}
+
+		if err := validateRule(fromCollectionRule(collection.Rule)); err != nil {
+			return err
+		}
+
+		// a smart collection has no explicit members
+		collection.Products = make([]entity.CollectionProduct, 0)
+	case entity.CollectionTypeManual:
+		collection.Rule = nil
+		if payload.ProductIds != nil {
+			collection.Products = toCollectionProducts(payload.ProductIds)
+		}
+	}
+
+	return uc.repository.Update(collection)
+}
+
+func (uc *CollectionUseCase) DeleteCollection(payload *dto.CollectionWithIdDTO) error {
+	collection, err := uc.repository.FindById(uint(payload.ID))
+	if err != nil {
+		return apperror.RecordNotFound(err, ErrCollectionNotFound)
+	}
+
+	return uc.repository.Delete(&entity.Collection{ID: collection.ID})
+}
+
+func validateRule(rule *dto.CollectionRuleDTO) error {
+	if rule == nil {
+		return ErrRuleRequired
+	}
+
+	if rule.MinPrice != nil && rule.MaxPrice != nil && *rule.MinPrice > *rule.MaxPrice {
+		return ErrInvalidRule
+	}
+
+	return nil
+}
+
+func toCollectionRule(rule *dto.CollectionRuleDTO) *entity.CollectionRule {
+	if rule == nil {
+		return nil
+	}
+
+	return &entity.CollectionRule{
+		BrandIds:         rule.BrandIds,
+		IncludeSubBrands: rule.IncludeSubBrands,
+		MinPrice:         rule.MinPrice,
+		MaxPrice:         rule.MaxPrice,
+		InStock:          rule.InStock,
+		Tags:             rule.Tags,
+		TagMatch:         rule.TagMatch,
+	}
+}
+
+func fromCollectionRule(rule *entity.CollectionRule) *dto.CollectionRuleDTO {
+	if rule == nil {
+		return nil
+	}
+
+	return &dto.CollectionRuleDTO{
+		BrandIds:         rule.BrandIds,
+		IncludeSubBrands: rule.IncludeSubBrands,
+		MinPrice:         rule.MinPrice,
+		MaxPrice:         rule.MaxPrice,
+		InStock:          rule.InStock,
+		Tags:             rule.Tags,
+		TagMatch:         rule.TagMatch,
+	}
+}
+
+func toCollectionProducts(productIds []int64) []entity.CollectionProduct {
+	products := make([]entity.CollectionProduct, 0, len(productIds))
+	seen := make(map[int64]bool, len(productIds))
+	for _, id := range productIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		products = append(products, entity.CollectionProduct{
+			ProductId: uint(id),
+			Position:  len(products),
+		})
+	}
+	return products
+}
+
+func toFindCollectionDTO(collection *entity.Collection) *dto.FindCollectionDTO {
+	collectionDto := &dto.FindCollectionDTO{
+		ID:        int64(collection.ID),
+		Name:      collection.Name,
+		Type:      collection.Type,
+		Rule:      fromCollectionRule(collection.Rule),
+		CreatedAt: collection.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: collection.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if collection.Type == entity.CollectionTypeManual {
+		collectionDto.ProductIds = make([]int64, 0, len(collection.Products))
+		for _, p := range collection.Products {
+			collectionDto.ProductIds = append(collectionDto.ProductIds, int64(p.ProductId))
+		}
+	}
+
+	return collectionDto
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/collection/dto"
+	"ecommerce/internal/domain/collection/entity"
+	"ecommerce/internal/domain/collection/repository"
+	ProductDto "ecommerce/internal/domain/product/dto"
+	ProductUseCase "ecommerce/internal/domain/product/usecase"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type storedCollections struct {
+	repository.ICollectionRepository
+	collections map[uint]*entity.Collection
+	created     *entity.Collection
+}
+
+func (s *storedCollections) FindById(id uint) (*entity.Collection, error) {
+	return s.collections[id], nil
+}
+
+func (s *storedCollections) Create(collection *entity.Collection) error {
+	s.created = collection
+	return nil
+}
+
+// productQuery records the pagination a collection hands to the products.
+type productQuery struct {
+	ProductUseCase.IProductUseCase
+	params *ProductDto.ProductPaginationDTO
+}
+
+func (q *productQuery) FindAll(params *ProductDto.ProductPaginationDTO) (int, int, []*ProductDto.FindProductDTO, error) {
+	q.params = params
+	return 0, 0, make([]*ProductDto.FindProductDTO, 0), nil
+}
+
+func TestFindProductsAppliesRule(t *testing.T) {
+	minPrice, inStock := 10000, true
+	collections := &storedCollections{collections: map[uint]*entity.Collection{
+		1: {ID: 1, Type: entity.CollectionTypeManual},
+		2: {ID: 2, Type: entity.CollectionTypeSmart, Rule: &entity.CollectionRule{
+			BrandIds:         []int64{4},
+			IncludeSubBrands: true,
+			MinPrice:         &minPrice,
+			InStock:          &inStock,
+			Tags:             []string{"sale", "new"},
+			TagMatch:         "all",
+		}},
+	}}
+
+	tests := []struct {
+		name string
+		id   int64
+		want ProductDto.ProductFilterDTO
+	}{
+		{name: "manual", id: 1, want: ProductDto.ProductFilterDTO{CollectionId: 1}},
+		{name: "smart", id: 2, want: ProductDto.ProductFilterDTO{
+			BrandIds:         []int64{4},
+			IncludeSubBrands: true,
+			MinPrice:         &minPrice,
+			InStock:          &inStock,
+			Tags:             []string{"sale", "new"},
+			TagMatch:         "all",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := &productQuery{}
+			uc := NewCollectionUseCase(collections, products)
+
+			params := &ProductDto.ProductPaginationDTO{Page: 2}
+			if _, _, _, err := uc.FindProducts(&dto.CollectionWithIdDTO{ID: tt.id}, params); err != nil {
+				t.Fatalf("FindProducts() error = %v", err)
+			}
+			if products.params.Page != 2 {
+				t.Errorf("page = %d, want 2", products.params.Page)
+			}
+			if !reflect.DeepEqual(products.params.ProductFilterDTO, tt.want) {
+				t.Errorf("product filter = %+v, want %+v", products.params.ProductFilterDTO, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateCollection(t *testing.T) {
+	minPrice, maxPrice := 500, 100
+
+	t.Run("manual keeps first position of repeated products", func(t *testing.T) {
+		collections := &storedCollections{}
+		uc := NewCollectionUseCase(collections, nil)
+
+		err := uc.CreateCollection(&dto.CreateCollectionDTO{Name: "Picks", Type: entity.CollectionTypeManual, ProductIds: []int64{5, 3, 5, 8}})
+		if err != nil {
+			t.Fatalf("CreateCollection() error = %v", err)
+		}
+
+		want := []entity.CollectionProduct{{ProductId: 5, Position: 0}, {ProductId: 3, Position: 1}, {ProductId: 8, Position: 2}}
+		if !reflect.DeepEqual(collections.created.Products, want) {
+			t.Errorf("products = %+v, want %+v", collections.created.Products, want)
+		}
+	})
+
+	t.Run("smart without rule", func(t *testing.T) {
+		uc := NewCollectionUseCase(&storedCollections{}, nil)
+		err := uc.CreateCollection(&dto.CreateCollectionDTO{Name: "Deals", Type: entity.CollectionTypeSmart})
+		if !errors.Is(err, ErrRuleRequired) {
+			t.Errorf("CreateCollection() error = %v, want %v", err, ErrRuleRequired)
+		}
+	})
+
+	t.Run("smart with inverted price range", func(t *testing.T) {
+		uc := NewCollectionUseCase(&storedCollections{}, nil)
+		err := uc.CreateCollection(&dto.CreateCollectionDTO{
+			Name: "Deals",
+			Type: entity.CollectionTypeSmart,
+			Rule: &dto.CollectionRuleDTO{MinPrice: &minPrice, MaxPrice: &maxPrice},
+		})
+		if !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("CreateCollection() error = %v, want %v", err, ErrInvalidRule)
+		}
+	})
+}
@@ -0,0 +1,9 @@
+package usecase
+
+import "ecommerce/pkg/apperror"
+
+var (
+	ErrCollectionNotFound = apperror.NotFound("collection_not_found", "collection not found")
+	ErrRuleRequired       = apperror.Validation("collection_rule_required", "smart collection requires a rule")
+	ErrInvalidRule        = apperror.Validation("invalid_collection_rule", "rule min_price must not exceed max_price")
+)
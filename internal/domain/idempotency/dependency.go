@@ -0,0 +1,27 @@
+package idempotency
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/idempotency/middleware"
+	IdempotencyRepository "ecommerce/internal/domain/idempotency/repository"
+	IdempotencyUseCase "ecommerce/internal/domain/idempotency/usecase"
+	"github.com/labstack/echo/v4"
+	"log/slog"
+)
+
+func NewIdempotencyDependency(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) echo.MiddlewareFunc {
+	ttl := config.AppConfig.IdempotencyKeyTTL
+	if ttl <= 0 {
+		ttl = constants.DefaultIdempotencyKeyTTL
+	}
+
+	repository := IdempotencyRepository.NewIdempotencyRepository(ctx, dbProvider, logger)
+	useCase := IdempotencyUseCase.NewIdempotencyUseCase(repository, ttl)
+	return middleware.NewIdempotencyMiddleware(useCase)
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "net/http"
+
+// BeginRequestDTO names a write by its Idempotency-Key and the fingerprint of
+// its method, path and body. Owner is set by Begin when the request locks the
+// key; Extend, Complete and Release only act on a key it still holds.
+type BeginRequestDTO struct {
+	Key         string
+	Fingerprint string
+	Owner       string
+}
+
+// StoredResponseDTO is a response kept for replaying to retries.
+type StoredResponseDTO struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type CompleteRequestDTO struct {
+	Key         string
+	Fingerprint string
+	Owner       string
+	Response    *StoredResponseDTO
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// IdempotencyKey records a write sent with an Idempotency-Key header. While
+// the request runs the key is locked by Owner, a token of that request, and
+// LockedAt is renewed; once it is done the response is stored in StatusCode,
+// ResponseHeaders and ResponseBody and is replayed to retries until
+// ExpiresAt.
+type IdempotencyKey struct {
+	Key             string `gorm:"primary_key"`
+	Fingerprint     string
+	Owner           string
+	StatusCode      *int
+	ResponseHeaders *string
+	ResponseBody    []byte
+	LockedAt        *time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+func (k *IdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	k.CreatedAt = time.Now()
+	k.UpdatedAt = k.CreatedAt
+	return nil
+}
+
+func (k *IdempotencyKey) BeforeUpdate(tx *gorm.DB) error {
+	k.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsCompleted reports whether the response of the request is stored.
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != nil
+}
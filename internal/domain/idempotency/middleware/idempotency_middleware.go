@@ -0,0 +1,262 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/idempotency/dto"
+	"ecommerce/internal/domain/idempotency/usecase"
+	"ecommerce/pkg/apperror"
+	"encoding/hex"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a response replayed from an earlier
+	// request with the same key.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+var ErrKeyTooLong = apperror.Validation("idempotency_key_too_long", "Idempotency-Key is too long")
+
+// NewIdempotencyMiddleware makes writes sent with an Idempotency-Key header
+// safe to retry. The first request with a key runs and its response is
+// stored; a retry with the same method, path and body gets the stored
+// response instead of running again, a different request with the key is
+// refused with 422 and a retry while the first request still runs with 409.
+// Only successful responses are stored; a failed write changed nothing, so a
+// retry after one runs again.
+func NewIdempotencyMiddleware(useCase usecase.IIdempotencyUseCase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" || !isWrite(c.Request().Method) {
+				return next(c)
+			}
+
+			if len(key) > constants.IdempotencyKeyMaxLength {
+				return ErrKeyTooLong
+			}
+
+			fingerprint, cleanup, err := fingerprint(c)
+			if err != nil {
+				c.Logger().Error(err)
+				return err
+			}
+			defer cleanup()
+
+			payload := &dto.BeginRequestDTO{Key: key, Fingerprint: fingerprint}
+			stored, err := useCase.Begin(payload)
+			if err != nil {
+				c.Logger().Error(err)
+				if errors.Is(err, usecase.ErrInProgress) {
+					c.Response().Header().Set("Retry-After", "1")
+				}
+				return err
+			}
+
+			if stored != nil {
+				for name, values := range stored.Header {
+					c.Response().Header()[name] = values
+				}
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				c.Response().WriteHeader(stored.StatusCode)
+				_, err := c.Response().Write(stored.Body)
+				return err
+			}
+
+			return run(c, next, payload, useCase)
+		}
+	}
+}
+
+// run handles the request while recording its response and renewing the
+// lock of its key, then stores a successful response or releases the key.
+func run(c echo.Context, next echo.HandlerFunc, payload *dto.BeginRequestDTO, useCase usecase.IIdempotencyUseCase) error {
+	response := c.Response()
+	recorder := &recorder{ResponseWriter: response.Writer}
+	response.Writer = recorder
+	stop := heartbeat(c, payload, useCase)
+
+	completed := false
+	defer func() {
+		response.Writer = recorder.ResponseWriter
+		if !completed {
+			// a panic on the way to the recover middleware
+			stop()
+			_ = useCase.Release(payload)
+		}
+	}()
+
+	// errors are rendered here rather than by the caller so that the
+	// response is recorded
+	if err := next(c); err != nil {
+		c.Error(err)
+	}
+
+	stop()
+	completed = true
+	if !response.Committed || response.Status < http.StatusOK || response.Status >= http.StatusMultipleChoices {
+		if err := useCase.Release(payload); err != nil {
+			c.Logger().Error(err)
+		}
+		return nil
+	}
+
+	if err := useCase.Complete(&dto.CompleteRequestDTO{
+		Key:         payload.Key,
+		Fingerprint: payload.Fingerprint,
+		Owner:       payload.Owner,
+		Response: &dto.StoredResponseDTO{
+			StatusCode: response.Status,
+			Header:     response.Header(),
+			Body:       recorder.body.Bytes(),
+		},
+	}); err != nil {
+		c.Logger().Error(err)
+	}
+	return nil
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// heartbeat renews the lock of the key every IdempotencyHeartbeatInterval
+// until the returned func is called, so that a retry does not take over a
+// request that is still running.
+func heartbeat(c echo.Context, payload *dto.BeginRequestDTO, useCase usecase.IIdempotencyUseCase) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(constants.IdempotencyHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := useCase.Extend(payload); err != nil {
+					c.Logger().Error(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// fingerprint hashes the method, path, query and body of the request. The
+// body, at most IdempotencyMaxBodySize, is streamed into the hash and spooled
+// for the handler; cleanup removes the spool once the request is done.
+func fingerprint(c echo.Context) (string, func(), error) {
+	request := c.Request()
+	hash := sha256.New()
+	io.WriteString(hash, request.Method+" "+request.URL.RequestURI()+"\n")
+
+	body := &spool{}
+	limited := http.MaxBytesReader(c.Response(), request.Body, constants.IdempotencyMaxBodySize)
+	if _, err := io.Copy(io.MultiWriter(hash, body), limited); err != nil {
+		body.Close()
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", nil, echo.ErrStatusRequestEntityTooLarge
+		}
+		return "", nil, apperror.Invalid(err)
+	}
+
+	reader, err := body.Reader()
+	if err != nil {
+		body.Close()
+		return "", nil, err
+	}
+	request.Body = reader
+	return hex.EncodeToString(hash.Sum(nil)), body.Close, nil
+}
+
+// spool keeps a request body in memory up to IdempotencyBodyMemory and moves
+// it to a temporary file once it grows past that.
+type spool struct {
+	memory bytes.Buffer
+	file   *os.File
+}
+
+func (s *spool) Write(b []byte) (int, error) {
+	if s.file == nil && s.memory.Len()+len(b) > constants.IdempotencyBodyMemory {
+		file, err := os.CreateTemp("", "idempotency-*")
+		if err != nil {
+			return 0, err
+		}
+		s.file = file
+		if _, err := file.Write(s.memory.Bytes()); err != nil {
+			return 0, err
+		}
+		s.memory.Reset()
+	}
+
+	if s.file != nil {
+		return s.file.Write(b)
+	}
+	return s.memory.Write(b)
+}
+
+// Reader reads the spooled body from the start.
+func (s *spool) Reader() (io.ReadCloser, error) {
+	if s.file == nil {
+		return io.NopCloser(bytes.NewReader(s.memory.Bytes())), nil
+	}
+
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(s.file), nil
+}
+
+// Close removes the temporary file of the spool, if any.
+func (s *spool) Close() {
+	if s.file == nil {
+		return
+	}
+	_ = s.file.Close()
+	_ = os.Remove(s.file.Name())
+	s.file = nil
+}
+
+// recorder keeps a copy of the response body written through it.
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"bytes"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/idempotency/dto"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	large := strings.Repeat("x", constants.IdempotencyBodyMemory+10)
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		differs bool
+	}{
+		{name: "small body", method: http.MethodPost, target: "/products", body: `{"name":"a"}`},
+		{name: "same request", method: http.MethodPost, target: "/products", body: `{"name":"a"}`},
+		{name: "other body", method: http.MethodPost, target: "/products", body: `{"name":"b"}`, differs: true},
+		{name: "other path", method: http.MethodPost, target: "/brands", body: `{"name":"a"}`, differs: true},
+		{name: "other method", method: http.MethodPatch, target: "/products", body: `{"name":"a"}`, differs: true},
+		{name: "body spooled to a file", method: http.MethodPost, target: "/products", body: large, differs: true},
+	}
+
+	e := echo.New()
+	first := ""
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			c := e.NewContext(request, httptest.NewRecorder())
+
+			got, cleanup, err := fingerprint(c)
+			if err != nil {
+				t.Fatalf("fingerprint() error = %v", err)
+			}
+			defer cleanup()
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			if string(body) != tt.body {
+				t.Errorf("body put back has %d bytes, want %d", len(body), len(tt.body))
+			}
+
+			if first == "" {
+				first = got
+				return
+			}
+			if (got != first) != tt.differs {
+				t.Errorf("fingerprint() = %s, first = %s, want differs %v", got, first, tt.differs)
+			}
+		})
+	}
+}
+
+type fakeUseCase struct {
+	stored    *dto.StoredResponseDTO
+	completed *dto.CompleteRequestDTO
+	released  *dto.BeginRequestDTO
+}
+
+func (f *fakeUseCase) Begin(payload *dto.BeginRequestDTO) (*dto.StoredResponseDTO, error) {
+	if f.stored != nil {
+		return f.stored, nil
+	}
+	payload.Owner = "owner"
+	return nil, nil
+}
+
+func (f *fakeUseCase) Extend(payload *dto.BeginRequestDTO) error {
+	return nil
+}
+
+func (f *fakeUseCase) Complete(payload *dto.CompleteRequestDTO) error {
+	f.completed = payload
+	return nil
+}
+
+func (f *fakeUseCase) Release(payload *dto.BeginRequestDTO) error {
+	f.released = payload
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       *dto.StoredResponseDTO
+		handler      echo.HandlerFunc
+		wantStatus   int
+		wantBody     string
+		wantComplete bool
+		wantRelease  bool
+		wantReplayed bool
+	}{
+		{
+			name:         "success is stored",
+			handler:      func(c echo.Context) error { return c.String(http.StatusCreated, "created") },
+			wantStatus:   http.StatusCreated,
+			wantBody:     "created",
+			wantComplete: true,
+		},
+		{
+			name:        "failure releases the key",
+			handler:     func(c echo.Context) error { return echo.NewHTTPError(http.StatusConflict) },
+			wantStatus:  http.StatusConflict,
+			wantRelease: true,
+		},
+		{
+			name:         "stored response is replayed",
+			stored:       &dto.StoredResponseDTO{StatusCode: http.StatusCreated, Header: http.Header{}, Body: []byte("earlier")},
+			handler:      func(c echo.Context) error { t.Error("handler ran for a replay"); return nil },
+			wantStatus:   http.StatusCreated,
+			wantBody:     "earlier",
+			wantReplayed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			useCase := &fakeUseCase{stored: tt.stored}
+			e.Use(NewIdempotencyMiddleware(useCase))
+			e.POST("/products", tt.handler)
+
+			request := httptest.NewRequest(http.MethodPost, "/products", bytes.NewReader([]byte(`{}`)))
+			request.Header.Set(HeaderIdempotencyKey, "key")
+			recorder := httptest.NewRecorder()
+			e.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+			if (recorder.Header().Get(HeaderIdempotentReplayed) == "true") != tt.wantReplayed {
+				t.Errorf("%s = %q, want replayed %v", HeaderIdempotentReplayed, recorder.Header().Get(HeaderIdempotentReplayed), tt.wantReplayed)
+			}
+			if (useCase.completed != nil) != tt.wantComplete {
+				t.Errorf("completed = %v, want %v", useCase.completed != nil, tt.wantComplete)
+			}
+			if useCase.completed != nil && useCase.completed.Owner != "owner" {
+				t.Errorf("completed by owner %q, want owner", useCase.completed.Owner)
+			}
+			if (useCase.released != nil) != tt.wantRelease {
+				t.Errorf("released = %v, want %v", useCase.released != nil, tt.wantRelease)
+			}
+			if useCase.released != nil && useCase.released.Owner != "owner" {
+				t.Errorf("released by owner %q, want owner", useCase.released.Owner)
+			}
+		})
+	}
+}
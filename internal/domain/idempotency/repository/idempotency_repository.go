@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/idempotency/entity"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source=idempotency_repository.go -destination=mocks/idempotency_repository_mock.go -package=mocks
+type IIdempotencyRepository interface {
+	FindByKey(key string) (*entity.IdempotencyKey, error)
+	Acquire(key *entity.IdempotencyKey, staleBefore time.Time) (bool, error)
+	Extend(key *entity.IdempotencyKey) error
+	Complete(key *entity.IdempotencyKey) error
+	Release(key *entity.IdempotencyKey) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	dbProvider *config.DatabaseConfiguration
+	ctx        context.Context
+	logger     *slog.Logger
+}
+
+func NewIdempotencyRepository(ctx context.Context, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		dbProvider: dbProvider,
+		ctx:        ctx,
+		logger:     logger,
+	}
+}
+
+func (repo *IdempotencyRepository) FindByKey(key string) (*entity.IdempotencyKey, error) {
+	idempotencyKey := &entity.IdempotencyKey{}
+	if err := repo.dbProvider.WithContext(repo.ctx).First(idempotencyKey, "key = ?", key).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return nil, err
+	}
+	return idempotencyKey, nil
+}
+
+// Acquire locks key for a new request. A key that is taken can only be
+// acquired once it expired, or when the request holding it locked it before
+// staleBefore with the same fingerprint and never completed. The insert and
+// the takeover are a single statement, so of concurrent requests with the
+// same key exactly one acquires it.
+func (repo *IdempotencyRepository) Acquire(key *entity.IdempotencyKey, staleBefore time.Time) (bool, error) {
+	acquired := make([]string, 0, 1)
+	err := repo.dbProvider.WithContext(repo.ctx).Raw(`
+		INSERT INTO idempotency_keys (key, fingerprint, owner, locked_at, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, owner = EXCLUDED.owner,
+			status_code = NULL, response_headers = NULL, response_body = NULL,
+			locked_at = EXCLUDED.locked_at, expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+			OR (idempotency_keys.status_code IS NULL
+				AND idempotency_keys.locked_at < ?
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)
+		RETURNING key`,
+		key.Key, key.Fingerprint, key.Owner, key.LockedAt, key.ExpiresAt, key.CreatedAt, key.UpdatedAt, staleBefore,
+	).Scan(&acquired).Error
+	if err != nil {
+		repo.logger.Error(err.Error())
+		return false, err
+	}
+	return len(acquired) > 0, nil
+}
+
+// Extend renews the lock of the request holding key. It returns
+// gorm.ErrRecordNotFound when the request lost the key to a retry.
+func (repo *IdempotencyRepository) Extend(key *entity.IdempotencyKey) error {
+	qw := repo.dbProvider.WithContext(repo.ctx).
+		Model(key).
+		Where("status_code IS NULL AND owner = ?", key.Owner).
+		Select("locked_at", "updated_at").
+		Updates(key)
+	if qw.Error != nil {
+		repo.logger.Error(qw.Error.Error())
+		return qw.Error
+	}
+
+	if qw.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Complete stores the response of the request holding key and unlocks it.
+func (repo *IdempotencyRepository) Complete(key *entity.IdempotencyKey) error {
+	qw := repo.dbProvider.WithContext(repo.ctx).
+		Model(key).
+		Where("status_code IS NULL AND fingerprint = ? AND owner = ?", key.Fingerprint, key.Owner).
+		Select("status_code", "response_headers", "response_body", "locked_at", "updated_at").
+		Updates(key)
+	if qw.Error != nil {
+		repo.logger.Error(qw.Error.Error())
+		return qw.Error
+	}
+
+	if qw.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Release drops the lock of a request that is not replayed, so that a retry
+// runs it again. A key taken over by a retry in the meantime is left alone.
+func (repo *IdempotencyRepository) Release(key *entity.IdempotencyKey) error {
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		Where("key = ? AND owner = ? AND status_code IS NULL", key.Key, key.Owner).
+		Delete(&entity.IdempotencyKey{}).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (repo *IdempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	qw := repo.dbProvider.WithContext(repo.ctx).
+		Where("expires_at <= ?", now).
+		Delete(&entity.IdempotencyKey{})
+	if qw.Error != nil {
+		repo.logger.Error(qw.Error.Error())
+		return 0, qw.Error
+	}
+	return qw.RowsAffected, nil
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/idempotency/dto"
+	"ecommerce/internal/domain/idempotency/entity"
+	"ecommerce/internal/domain/idempotency/repository"
+	"ecommerce/pkg/apperror"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	ErrKeyReused  = apperror.Unprocessable("idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	ErrInProgress = apperror.Conflict("idempotency_key_in_progress", "a request with this Idempotency-Key is still in progress")
+)
+
+// replayedHeaders are the response headers stored with a response; the rest
+// describe the connection rather than the result.
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+type IIdempotencyUseCase interface {
+	Begin(payload *dto.BeginRequestDTO) (*dto.StoredResponseDTO, error)
+	Extend(payload *dto.BeginRequestDTO) error
+	Complete(payload *dto.CompleteRequestDTO) error
+	Release(payload *dto.BeginRequestDTO) error
+}
+
+type IdempotencyUseCase struct {
+	repository repository.IIdempotencyRepository
+	ttl        time.Duration
+
+	mu        sync.Mutex
+	lastPurge time.Time
+}
+
+func NewIdempotencyUseCase(repository repository.IIdempotencyRepository, ttl time.Duration) *IdempotencyUseCase {
+	return &IdempotencyUseCase{
+		repository: repository,
+		ttl:        ttl,
+	}
+}
+
+// Begin locks the key for the request, setting payload.Owner, and returns
+// nil, or returns the stored response of an earlier request with the same key
+// and fingerprint. It returns ErrKeyReused when the key was used for a
+// different request and ErrInProgress while the earlier request still runs.
+func (uc *IdempotencyUseCase) Begin(payload *dto.BeginRequestDTO) (*dto.StoredResponseDTO, error) {
+	uc.purge()
+
+	owner, err := newOwner()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key := &entity.IdempotencyKey{
+		Key:         payload.Key,
+		Fingerprint: payload.Fingerprint,
+		Owner:       owner,
+		LockedAt:    &now,
+		ExpiresAt:   now.Add(uc.ttl),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	acquired, err := uc.repository.Acquire(key, now.Add(-constants.IdempotencyLockTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	if acquired {
+		payload.Owner = owner
+		return nil, nil
+	}
+
+	existing, err := uc.repository.FindByKey(payload.Key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// released by the request holding it just now
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.Fingerprint != payload.Fingerprint {
+		return nil, ErrKeyReused
+	}
+
+	if !existing.IsCompleted() {
+		return nil, ErrInProgress
+	}
+
+	response := &dto.StoredResponseDTO{
+		StatusCode: *existing.StatusCode,
+		Header:     http.Header{},
+		Body:       existing.ResponseBody,
+	}
+
+	if existing.ResponseHeaders != nil {
+		if err := json.Unmarshal([]byte(*existing.ResponseHeaders), &response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// Extend renews the lock of the request holding the key, so that a retry does
+// not take over a request running longer than IdempotencyLockTimeout.
+func (uc *IdempotencyUseCase) Extend(payload *dto.BeginRequestDTO) error {
+	now := time.Now()
+	return uc.repository.Extend(&entity.IdempotencyKey{
+		Key:      payload.Key,
+		Owner:    payload.Owner,
+		LockedAt: &now,
+	})
+}
+
+// Complete stores the response of the request holding the key.
+func (uc *IdempotencyUseCase) Complete(payload *dto.CompleteRequestDTO) error {
+	header := http.Header{}
+	for _, name := range replayedHeaders {
+		if values := payload.Response.Header.Values(name); len(values) > 0 {
+			header[name] = values
+		}
+	}
+
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	headers := string(encoded)
+	return uc.repository.Complete(&entity.IdempotencyKey{
+		Key:             payload.Key,
+		Fingerprint:     payload.Fingerprint,
+		Owner:           payload.Owner,
+		StatusCode:      &payload.Response.StatusCode,
+		ResponseHeaders: &headers,
+		ResponseBody:    payload.Response.Body,
+	})
+}
+
+// Release unlocks the key without storing a response, so that a retry runs
+// the request again.
+func (uc *IdempotencyUseCase) Release(payload *dto.BeginRequestDTO) error {
+	return uc.repository.Release(&entity.IdempotencyKey{Key: payload.Key, Owner: payload.Owner})
+}
+
+// newOwner returns a random token identifying the request holding a key.
+func newOwner() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// purge deletes the expired keys at most once per IdempotencyPurgeInterval;
+// an expired key is also taken over by the next request using it.
+func (uc *IdempotencyUseCase) purge() {
+	uc.mu.Lock()
+	now := time.Now()
+	if now.Sub(uc.lastPurge) < constants.IdempotencyPurgeInterval {
+		uc.mu.Unlock()
+		return
+	}
+	uc.lastPurge = now
+	uc.mu.Unlock()
+
+	_, _ = uc.repository.DeleteExpired(now)
+}
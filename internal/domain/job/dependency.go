@@ -0,0 +1,70 @@
+package job
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/job/presenter"
+	JobRepository "ecommerce/internal/domain/job/repository"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+	"ecommerce/pkg/storage"
+	"log/slog"
+)
+
+func NewJobDependency(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) presenter.IJobPresenter {
+	return presenter.NewJobPresenter(NewJobUseCase(ctx, dbProvider, logger))
+}
+
+// NewJobUseCase wires the job use case for the domains that queue jobs.
+func NewJobUseCase(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) JobUseCase.IJobUseCase {
+	maxAttempts := config.AppConfig.JobMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = constants.DefaultJobMaxAttempts
+	}
+
+	repository := JobRepository.NewJobRepository(ctx, dbProvider, logger)
+	return JobUseCase.NewJobUseCase(repository, newStorage(logger), maxAttempts)
+}
+
+// NewJobWorker wires the worker pool; handlers are registered by the domains
+// owning the job types before it is started.
+func NewJobWorker(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) *JobUseCase.Worker {
+	workers := config.AppConfig.JobWorkers
+	if workers <= 0 {
+		workers = constants.DefaultJobWorkers
+	}
+
+	pollInterval := config.AppConfig.JobPollInterval
+	if pollInterval <= 0 {
+		pollInterval = constants.DefaultJobPollInterval
+	}
+
+	retryBackoff := config.AppConfig.JobRetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = constants.DefaultJobRetryBackoff
+	}
+
+	repository := JobRepository.NewJobRepository(ctx, dbProvider, logger)
+	return JobUseCase.NewWorker(repository, newStorage(logger), logger, workers, pollInterval, retryBackoff)
+}
+
+func newStorage(logger *slog.Logger) storage.IStorage {
+	localStorage, err := storage.NewLocalStorage(config.AppConfig.StorageLocalPath)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil
+	}
+	return localStorage
+}
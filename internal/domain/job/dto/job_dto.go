@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// EnqueueJobDTO queues a job of Type. Payload is stored as JSON and Input,
+// such as an uploaded file, is kept in storage for the job to read.
+// MaxAttempts overrides the configured attempts, e.g. 1 for a job that is not
+// safe to repeat.
+type EnqueueJobDTO struct {
+	Type        string
+	Payload     interface{}
+	Input       io.Reader
+	MaxAttempts int
+}
+
+type JobWithIdDTO struct {
+	ID int64 `json:"id" param:"id" validate:"required,gt=0"`
+}
+
+// FindJobDTO is the status of a job. Progress counts the processed items out
+// of Total when the job knows it; ResultURL downloads the file a succeeded
+// job produced.
+type FindJobDTO struct {
+	ID              int64           `json:"id"`
+	Type            string          `json:"type"`
+	Status          string          `json:"status"`
+	Progress        int             `json:"progress"`
+	Total           *int            `json:"total"`
+	Attempts        int             `json:"attempts"`
+	MaxAttempts     int             `json:"max_attempts"`
+	CancelRequested bool            `json:"cancel_requested"`
+	Error           *string         `json:"error"`
+	Result          json.RawMessage `json:"result" swaggertype:"object"`
+	ResultURL       *string         `json:"result_url"`
+	RunAt           string          `json:"run_at"`
+	StartedAt       *string         `json:"started_at"`
+	FinishedAt      *string         `json:"finished_at"`
+	CreatedAt       string          `json:"created_at"`
+	UpdatedAt       string          `json:"updated_at"`
+}
+
+// JobResultDTO describes the result file of a job.
+type JobResultDTO struct {
+	FileName    string
+	ContentType string
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+// Job is a unit of background work. A queued job runs once RunAt has passed;
+// a running job holds a lease renewed through LockedAt. InputKey and
+// ResultKey name files in storage that the job reads and produces.
+type Job struct {
+	ID                uint `gorm:"primary_key"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Type              string
+	Status            string
+	Payload           JSON
+	InputKey          *string
+	Result            JSON
+	ResultKey         *string
+	ResultContentType *string
+	Error             *string
+	Progress          int
+	Total             *int
+	Attempts          int
+	MaxAttempts       int
+	CancelRequested   bool
+	RunAt             time.Time
+	LockedAt          *time.Time
+	StartedAt         *time.Time
+	FinishedAt        *time.Time
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	j.CreatedAt = time.Now()
+	j.UpdatedAt = j.CreatedAt
+	return nil
+}
+
+func (j *Job) BeforeUpdate(tx *gorm.DB) error {
+	j.UpdatedAt = time.Now()
+	return nil
+}
+
+// IsFinished reports whether the job reached a final status.
+func (j *Job) IsFinished() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCancelled
+}
+
+// JSON is an encoded JSON document stored in a jsonb column; an empty
+// document is stored as NULL.
+type JSON []byte
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *JSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return errors.New("unsupported json value")
+	}
+	return nil
+}
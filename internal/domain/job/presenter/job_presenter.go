@@ -0,0 +1,115 @@
+package presenter
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/job/dto"
+	"ecommerce/internal/domain/job/usecase"
+	"ecommerce/pkg/apiversion"
+	"ecommerce/pkg/apperror"
+	HttpResponser "ecommerce/pkg/response"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type IJobPresenter interface {
+	Get(c echo.Context) error
+	Cancel(c echo.Context) error
+	GetResult(c echo.Context) error
+}
+
+type JobPresenter struct {
+	useCase usecase.IJobUseCase
+}
+
+func NewJobPresenter(useCase usecase.IJobUseCase) *JobPresenter {
+	return &JobPresenter{useCase: useCase}
+}
+
+// Get godoc
+// @Summary      Get job
+// @Description  Get the status, progress and result of a background job. Status is queued, running, succeeded, failed or cancelled; progress counts the processed items out of total when known, and result_url downloads the file a succeeded job produced
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "job id"
+// @Success      200  {object}  response.SuccessResponse{data=dto.FindJobDTO}
+// @Router       /jobs/{id} [get]
+func (p *JobPresenter) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	job, err := p.useCase.FindById(&dto.JobWithIdDTO{ID: id})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get job success", job)
+}
+
+// Cancel godoc
+// @Summary      Cancel job
+// @Description  Cancel a queued job, or ask a running job to stop; a running job is cancelled within a few seconds unless it finishes first. Responds 409 for a finished job
+// @Tags         job
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "job id"
+// @Success      202  {object}  response.SuccessResponse{data=dto.FindJobDTO}
+// @Router       /jobs/{id}/cancel [post]
+func (p *JobPresenter) Cancel(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	job, err := p.useCase.Cancel(&dto.JobWithIdDTO{ID: id})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusAccepted, "Job cancellation requested", job)
+}
+
+// GetResult godoc
+// @Summary      Download job result
+// @Description  Download the file produced by a succeeded job, such as a product export
+// @Tags         job
+// @Produce      octet-stream
+// @Param 		 id path int true "job id"
+// @Success      200  {file}  file
+// @Router       /jobs/{id}/result [get]
+func (p *JobPresenter) GetResult(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	result, reader, err := p.useCase.OpenResult(&dto.JobWithIdDTO{ID: id})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+	defer reader.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, result.ContentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": result.FileName}))
+	http.ServeContent(c.Response(), c.Request(), result.FileName, time.Time{}, reader)
+	return nil
+}
+
+// Accepted answers a request that queued job with 202 and points Location at
+// the job status.
+func Accepted(c echo.Context, message string, job *dto.FindJobDTO) error {
+	c.Response().Header().Set(echo.HeaderLocation, apiversion.Path(c, constants.APIPath, fmt.Sprintf("/jobs/%d", job.ID)))
+	return HttpResponser.Success(c, http.StatusAccepted, message, job)
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/job/entity"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source=job_repository.go -destination=mocks/job_repository_mock.go -package=mocks
+type IJobRepository interface {
+	FindById(id uint) (*entity.Job, error)
+	Create(job *entity.Job) error
+	Claim(now time.Time) (*entity.Job, error)
+	Heartbeat(job *entity.Job, now time.Time) (bool, error)
+	Finish(job *entity.Job, attempt int) error
+	Cancel(id uint, now time.Time) error
+	RequeueStale(lockedBefore time.Time, now time.Time) (int64, error)
+}
+
+type JobRepository struct {
+	dbProvider *config.DatabaseConfiguration
+	ctx        context.Context
+	logger     *slog.Logger
+}
+
+func NewJobRepository(ctx context.Context, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) *JobRepository {
+	return &JobRepository{
+		dbProvider: dbProvider,
+		ctx:        ctx,
+		logger:     logger,
+	}
+}
+
+func (repo *JobRepository) FindById(id uint) (*entity.Job, error) {
+	var job *entity.Job
+	if err := repo.dbProvider.WithContext(repo.ctx).First(&job, "id = ?", id).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return nil, err
+	}
+	return job, nil
+}
+
+func (repo *JobRepository) Create(job *entity.Job) error {
+	if err := repo.dbProvider.WithContext(repo.ctx).Create(job).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Claim takes the lease of the oldest due queued job and counts the attempt.
+// SKIP LOCKED lets concurrent workers claim different jobs without waiting on
+// each other. It returns nil when no job is due.
+func (repo *JobRepository) Claim(now time.Time) (*entity.Job, error) {
+	jobs := make([]*entity.Job, 0, 1)
+	err := repo.dbProvider.WithContext(repo.ctx).Raw(`
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_at = ?, started_at = ?, updated_at = ?
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? AND run_at <= ?
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, entity.StatusRunning, now, now, now, entity.StatusQueued, now).
+		Scan(&jobs).Error
+	if err != nil {
+		repo.logger.Error(err.Error())
+		return nil, err
+	}
+
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+// Heartbeat renews the lease of a running job and saves its progress. It
+// reports whether the job was asked to cancel, and gorm.ErrRecordNotFound
+// when the job lost its lease.
+func (repo *JobRepository) Heartbeat(job *entity.Job, now time.Time) (bool, error) {
+	rows := make([]struct{ CancelRequested bool }, 0, 1)
+	err := repo.dbProvider.WithContext(repo.ctx).Raw(`
+		UPDATE jobs
+		SET locked_at = ?, progress = ?, total = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?
+		RETURNING cancel_requested`, now, job.Progress, job.Total, now, job.ID, entity.StatusRunning, job.Attempts).
+		Scan(&rows).Error
+	if err != nil {
+		repo.logger.Error(err.Error())
+		return false, err
+	}
+
+	if len(rows) == 0 {
+		return false, gorm.ErrRecordNotFound
+	}
+	return rows[0].CancelRequested, nil
+}
+
+// Finish stores the outcome of attempt: a final status, or the queued status
+// and RunAt of a retry. An attempt whose lease was taken over by another
+// worker is not stored.
+func (repo *JobRepository) Finish(job *entity.Job, attempt int) error {
+	qw := repo.dbProvider.WithContext(repo.ctx).
+		Model(job).
+		Where("status = ? AND attempts = ?", entity.StatusRunning, attempt).
+		Select("status", "result", "result_key", "result_content_type", "error", "progress", "total",
+			"attempts", "run_at", "locked_at", "finished_at", "updated_at").
+		Updates(job)
+	if qw.Error != nil {
+		repo.logger.Error(qw.Error.Error())
+		return qw.Error
+	}
+
+	if qw.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Cancel cancels a queued job right away and asks a running job to stop; the
+// worker running it cancels the job at its next heartbeat. Finished jobs are
+// left untouched.
+func (repo *JobRepository) Cancel(id uint, now time.Time) error {
+	return repo.dbProvider.WithContext(repo.ctx).Transaction(func(tx *gorm.DB) error {
+		cancelled := tx.Model(&entity.Job{}).
+			Where("id = ? AND status = ?", id, entity.StatusQueued).
+			Updates(map[string]interface{}{"status": entity.StatusCancelled, "finished_at": now, "updated_at": now})
+		if cancelled.Error != nil {
+			repo.logger.Error(cancelled.Error.Error())
+			return cancelled.Error
+		}
+
+		if cancelled.RowsAffected > 0 {
+			return nil
+		}
+
+		if err := tx.Model(&entity.Job{}).
+			Where("id = ? AND status = ?", id, entity.StatusRunning).
+			Updates(map[string]interface{}{"cancel_requested": true, "updated_at": now}).Error; err != nil {
+			repo.logger.Error(err.Error())
+			return err
+		}
+		return nil
+	})
+}
+
+// RequeueStale queues the running jobs whose lease expired again, or fails
+// them once they are out of attempts, and returns how many it found. A stale
+// job that was asked to stop is cancelled instead of being run again.
+func (repo *JobRepository) RequeueStale(lockedBefore time.Time, now time.Time) (int64, error) {
+	qw := repo.dbProvider.WithContext(repo.ctx).Exec(`
+		UPDATE jobs
+		SET status = CASE WHEN cancel_requested THEN @cancelled
+			WHEN attempts >= max_attempts THEN @failed ELSE @queued END,
+			finished_at = CASE WHEN cancel_requested OR attempts >= max_attempts THEN CAST(@now AS timestamp) END,
+			error = CASE WHEN cancel_requested THEN error ELSE @error END,
+			locked_at = NULL, run_at = @now, updated_at = @now
+		WHERE status = @running AND locked_at < @lockedBefore`,
+		map[string]interface{}{
+			"cancelled":    entity.StatusCancelled,
+			"failed":       entity.StatusFailed,
+			"queued":       entity.StatusQueued,
+			"running":      entity.StatusRunning,
+			"error":        "the worker running the job stopped responding",
+			"now":          now,
+			"lockedBefore": lockedBefore,
+		})
+	if qw.Error != nil {
+		repo.logger.Error(qw.Error.Error())
+		return 0, qw.Error
+	}
+	return qw.RowsAffected, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/job/entity"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// statements records the SQL gorm would run in dry run mode.
+type statements struct {
+	logger.Interface
+	sql []string
+}
+
+func (s *statements) LogMode(logger.LogLevel) logger.Interface {
+	return s
+}
+
+func (s *statements) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	s.sql = append(s.sql, sql)
+}
+
+func newRepository(db *gorm.DB) *JobRepository {
+	return NewJobRepository(context.Background(), &config.DatabaseConfiguration{DB: db}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// TestRequeueStaleBindsParams checks that every named parameter of the
+// statement is bound.
+func TestRequeueStaleBindsParams(t *testing.T) {
+	recorder := &statements{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: recorder})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	if _, err := newRepository(db).RequeueStale(now.Add(-time.Minute), now); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.sql) != 1 {
+		t.Fatalf("ran %d statements, want 1", len(recorder.sql))
+	}
+	if strings.Contains(recorder.sql[0], "@") {
+		t.Errorf("unbound parameter in %s", recorder.sql[0])
+	}
+}
+
+// TestRequeueStale runs the statement against the database of POSTGRES_DSN,
+// on a temporary jobs table that is dropped with the transaction.
+func TestRequeueStale(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	if err := tx.Exec(`
+		CREATE TEMPORARY TABLE jobs (
+			id                  bigserial PRIMARY KEY,
+			type                varchar(50)  NOT NULL,
+			status              varchar(20)  NOT NULL DEFAULT 'queued',
+			payload             jsonb        NOT NULL DEFAULT '{}',
+			input_key           varchar(512),
+			result              jsonb,
+			result_key          varchar(512),
+			result_content_type varchar(255),
+			error               text,
+			progress            integer      NOT NULL DEFAULT 0,
+			total               integer,
+			attempts            integer      NOT NULL DEFAULT 0,
+			max_attempts        integer      NOT NULL,
+			cancel_requested    boolean      NOT NULL DEFAULT false,
+			run_at              timestamp    NOT NULL,
+			locked_at           timestamp,
+			started_at          timestamp,
+			finished_at         timestamp,
+			created_at          timestamp    NOT NULL,
+			updated_at          timestamp    NOT NULL
+		) ON COMMIT DROP`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	stale, fresh := now.Add(-time.Hour), now.Add(-time.Second)
+
+	tests := []struct {
+		name       string
+		job        entity.Job
+		wantStatus string
+		wantError  bool
+		wantFinish bool
+	}{
+		{name: "retried", job: entity.Job{Attempts: 1, MaxAttempts: 3, LockedAt: &stale}, wantStatus: entity.StatusQueued, wantError: true},
+		{name: "out of attempts", job: entity.Job{Attempts: 3, MaxAttempts: 3, LockedAt: &stale}, wantStatus: entity.StatusFailed, wantError: true, wantFinish: true},
+		{name: "cancel requested", job: entity.Job{Attempts: 1, MaxAttempts: 3, LockedAt: &stale, CancelRequested: true}, wantStatus: entity.StatusCancelled, wantFinish: true},
+		{name: "lease held", job: entity.Job{Attempts: 1, MaxAttempts: 3, LockedAt: &fresh, CancelRequested: true}, wantStatus: entity.StatusRunning},
+	}
+
+	for _, tt := range tests {
+		tt.job.Type, tt.job.Status, tt.job.RunAt = "test", entity.StatusRunning, stale
+		if err := tx.Create(&tt.job).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := newRepository(tx).RequeueStale(now.Add(-time.Minute), now)
+	if err != nil {
+		t.Fatalf("RequeueStale() error = %v", err)
+	}
+	if found != 3 {
+		t.Errorf("RequeueStale() = %d, want 3", found)
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &entity.Job{}
+			if err := tx.First(job, i+1).Error; err != nil {
+				t.Fatal(err)
+			}
+			if job.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", job.Status, tt.wantStatus)
+			}
+			if (job.Error != nil) != tt.wantError {
+				t.Errorf("error = %v, want one: %v", job.Error, tt.wantError)
+			}
+			if (job.FinishedAt != nil) != tt.wantFinish {
+				t.Errorf("finished at = %v, want one: %v", job.FinishedAt, tt.wantFinish)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/job/dto"
+	"ecommerce/internal/domain/job/entity"
+	"ecommerce/internal/domain/job/repository"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/storage"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+var (
+	ErrJobNotFound        = apperror.NotFound("job_not_found", "job not found")
+	ErrJobFinished        = apperror.Conflict("job_finished", "job already finished")
+	ErrNoResult           = apperror.NotFound("job_result_not_found", "job has no result file")
+	ErrStorageUnavailable = apperror.Unavailable("storage_not_configured", "file storage is not configured")
+)
+
+type IJobUseCase interface {
+	Enqueue(payload *dto.EnqueueJobDTO) (*dto.FindJobDTO, error)
+	FindById(payload *dto.JobWithIdDTO) (*dto.FindJobDTO, error)
+	Cancel(payload *dto.JobWithIdDTO) (*dto.FindJobDTO, error)
+	OpenResult(payload *dto.JobWithIdDTO) (*dto.JobResultDTO, io.ReadSeekCloser, error)
+}
+
+type JobUseCase struct {
+	jobRepository repository.IJobRepository
+	storage       storage.IStorage
+	maxAttempts   int
+}
+
+func NewJobUseCase(jobRepository repository.IJobRepository, storage storage.IStorage, maxAttempts int) *JobUseCase {
+	return &JobUseCase{
+		jobRepository: jobRepository,
+		storage:       storage,
+		maxAttempts:   maxAttempts,
+	}
+}
+
+// Enqueue queues a job to run as soon as a worker is free. The input is
+// stored before the job is created so that a worker never sees a job without
+// its input.
+func (j *JobUseCase) Enqueue(payload *dto.EnqueueJobDTO) (*dto.FindJobDTO, error) {
+	data, err := json.Marshal(payload.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &entity.Job{
+		Type:        payload.Type,
+		Status:      entity.StatusQueued,
+		Payload:     data,
+		MaxAttempts: j.maxAttempts,
+		RunAt:       time.Now(),
+	}
+
+	if payload.MaxAttempts > 0 {
+		job.MaxAttempts = payload.MaxAttempts
+	}
+
+	if payload.Input != nil {
+		if j.storage == nil {
+			return nil, ErrStorageUnavailable
+		}
+
+		key, err := newInputKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := j.storage.Put(key, payload.Input); err != nil {
+			return nil, err
+		}
+		job.InputKey = &key
+	}
+
+	if err := j.jobRepository.Create(job); err != nil {
+		if job.InputKey != nil {
+			_ = j.storage.Delete(*job.InputKey)
+		}
+		return nil, err
+	}
+
+	return toFindJobDTO(job), nil
+}
+
+func (j *JobUseCase) FindById(payload *dto.JobWithIdDTO) (*dto.FindJobDTO, error) {
+	job, err := j.jobRepository.FindById(uint(payload.ID))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrJobNotFound)
+	}
+	return toFindJobDTO(job), nil
+}
+
+// Cancel stops a queued job right away. A running job is asked to stop and
+// is cancelled by its worker shortly after, so it may still finish first.
+func (j *JobUseCase) Cancel(payload *dto.JobWithIdDTO) (*dto.FindJobDTO, error) {
+	job, err := j.jobRepository.FindById(uint(payload.ID))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrJobNotFound)
+	}
+
+	if job.IsFinished() {
+		return nil, ErrJobFinished
+	}
+
+	if err := j.jobRepository.Cancel(job.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	job, err = j.jobRepository.FindById(job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == entity.StatusCancelled && job.InputKey != nil && j.storage != nil {
+		_ = j.storage.Delete(*job.InputKey)
+	}
+
+	return toFindJobDTO(job), nil
+}
+
+func (j *JobUseCase) OpenResult(payload *dto.JobWithIdDTO) (*dto.JobResultDTO, io.ReadSeekCloser, error) {
+	job, err := j.jobRepository.FindById(uint(payload.ID))
+	if err != nil {
+		return nil, nil, apperror.RecordNotFound(err, ErrJobNotFound)
+	}
+
+	if job.Status != entity.StatusSucceeded || job.ResultKey == nil || j.storage == nil {
+		return nil, nil, ErrNoResult
+	}
+
+	reader, err := j.storage.Open(*job.ResultKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrNoResult.Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &dto.JobResultDTO{FileName: path.Base(*job.ResultKey), ContentType: "application/octet-stream"}
+	if job.ResultContentType != nil {
+		result.ContentType = *job.ResultContentType
+	}
+	return result, reader, nil
+}
+
+func newInputKey() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return "jobs/inputs/" + hex.EncodeToString(random), nil
+}
+
+func toFindJobDTO(job *entity.Job) *dto.FindJobDTO {
+	jobDto := &dto.FindJobDTO{
+		ID:              int64(job.ID),
+		Type:            job.Type,
+		Status:          job.Status,
+		Progress:        job.Progress,
+		Total:           job.Total,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		CancelRequested: job.CancelRequested,
+		Error:           job.Error,
+		Result:          json.RawMessage(job.Result),
+		RunAt:           job.RunAt.Format("2006-01-02 15:04:05"),
+		StartedAt:       formatTime(job.StartedAt),
+		FinishedAt:      formatTime(job.FinishedAt),
+		CreatedAt:       job.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       job.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if job.Status == entity.StatusSucceeded && job.ResultKey != nil {
+		url := fmt.Sprintf("%s/%d/result", constants.JobsPath, job.ID)
+		jobDto.ResultURL = &url
+	}
+	return jobDto
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
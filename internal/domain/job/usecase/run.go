@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/job/entity"
+	"ecommerce/pkg/storage"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Run is the attempt of a job handed to its Handler. It gives access to the
+// payload and input of the job and collects its progress and result; the
+// worker saves progress with every heartbeat and the result once the handler
+// returns.
+type Run struct {
+	job     *entity.Job
+	storage storage.IStorage
+
+	mu                sync.Mutex
+	progress          int
+	total             *int
+	result            entity.JSON
+	resultKey         *string
+	resultContentType *string
+}
+
+func (r *Run) ID() uint {
+	return r.job.ID
+}
+
+// Attempt is the number of the attempt, starting at 1.
+func (r *Run) Attempt() int {
+	return r.job.Attempts
+}
+
+// Decode unmarshals the payload of the job into v.
+func (r *Run) Decode(v interface{}) error {
+	return json.Unmarshal(r.job.Payload, v)
+}
+
+// OpenInput opens the input file stored with the job.
+func (r *Run) OpenInput() (io.ReadSeekCloser, error) {
+	if r.job.InputKey == nil {
+		return nil, fmt.Errorf("job %d has no input", r.job.ID)
+	}
+
+	if r.storage == nil {
+		return nil, ErrStorageUnavailable
+	}
+	return r.storage.Open(*r.job.InputKey)
+}
+
+// SetTotal sets the number of items the job processes.
+func (r *Run) SetTotal(total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total = &total
+}
+
+// SetProgress sets the number of items processed so far.
+func (r *Run) SetProgress(done int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = done
+}
+
+// SetResult stores v as the JSON result of the job.
+func (r *Run) SetResult(v interface{}) error {
+	result, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.result = result
+	return nil
+}
+
+// WriteResultFile streams the result file of the job into storage through
+// write. The file is served as fileName once the job succeeded.
+func (r *Run) WriteResultFile(fileName string, contentType string, write func(w io.Writer) error) error {
+	if r.storage == nil {
+		return ErrStorageUnavailable
+	}
+
+	key := fmt.Sprintf("jobs/%d/%s", r.job.ID, fileName)
+	reader, writer := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		err := write(writer)
+		writer.CloseWithError(err)
+		written <- err
+	}()
+
+	_, err := r.storage.Put(key, reader)
+	// unblocks write when storage gave up reading
+	reader.CloseWithError(io.ErrClosedPipe)
+	writeErr := <-written
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resultKey = &key
+	r.resultContentType = &contentType
+	return nil
+}
+
+// state returns the progress and result collected so far.
+func (r *Run) state() (progress int, total *int, result entity.JSON, resultKey *string, resultContentType *string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress, r.total, r.result, r.resultKey, r.resultContentType
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/job/entity"
+	"ecommerce/internal/domain/job/repository"
+	"ecommerce/pkg/storage"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobCancelled is the cause of the context of a cancelled job.
+	ErrJobCancelled = errors.New("job cancelled")
+
+	errLeaseLost = errors.New("job lease lost")
+)
+
+// permanentError fails a job without retrying it.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as a failure that a retry cannot fix, such as an
+// invalid input, so that the job fails right away.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Handler runs one attempt of a job. ctx is cancelled when the job is
+// cancelled or the worker stops. An error fails the attempt, which is retried
+// with backoff until the job is out of attempts.
+type Handler func(ctx context.Context, run *Run) error
+
+// Worker runs queued jobs with a fixed number of goroutines. A job stopped by
+// Stop is queued again without counting the attempt, and a job of a worker
+// that died is queued again once its lease expires.
+type Worker struct {
+	jobRepository repository.IJobRepository
+	storage       storage.IStorage
+	logger        *slog.Logger
+	handlers      map[string]Handler
+	workers       int
+	pollInterval  time.Duration
+	retryBackoff  time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorker(
+	jobRepository repository.IJobRepository,
+	storage storage.IStorage,
+	logger *slog.Logger,
+	workers int,
+	pollInterval time.Duration,
+	retryBackoff time.Duration,
+) *Worker {
+	return &Worker{
+		jobRepository: jobRepository,
+		storage:       storage,
+		logger:        logger,
+		handlers:      make(map[string]Handler),
+		workers:       workers,
+		pollInterval:  pollInterval,
+		retryBackoff:  retryBackoff,
+	}
+}
+
+// Register sets the handler of a job type; it must be called before Start.
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Start runs the workers in the background until Stop is called.
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.poll(ctx)
+		}()
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.requeueStale(ctx)
+	}()
+}
+
+// Stop cancels the running jobs and waits until they are queued again.
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *Worker) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := w.jobRepository.Claim(time.Now())
+		if err == nil && job != nil {
+			w.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *Worker) requeueStale(ctx context.Context) {
+	ticker := time.NewTicker(constants.JobLeaseTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if requeued, err := w.jobRepository.RequeueStale(now.Add(-constants.JobLeaseTimeout), now); err == nil && requeued > 0 {
+			w.logger.Warn("requeued abandoned jobs", "count", requeued)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job *entity.Job) {
+	run := &Run{job: job, storage: w.storage, progress: job.Progress, total: job.Total}
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	beating := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		w.heartbeat(run, cancel, beating)
+	}()
+
+	w.logger.Info("job started", "id", job.ID, "type", job.Type, "attempt", job.Attempts)
+	err := w.handle(jobCtx, run)
+	close(beating)
+	<-stopped
+
+	w.finish(job, run, err, context.Cause(jobCtx))
+}
+
+// handle runs the handler of the job, turning a panic into an error.
+func (w *Worker) handle(ctx context.Context, run *Run) (err error) {
+	handler, ok := w.handlers[run.job.Type]
+	if !ok {
+		return fmt.Errorf("unknown job type %q", run.job.Type)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, run)
+}
+
+// heartbeat renews the lease of the job and saves its progress until done is
+// closed, and cancels the job when it is cancelled or loses its lease.
+func (w *Worker) heartbeat(run *Run, cancel context.CancelCauseFunc, done <-chan struct{}) {
+	ticker := time.NewTicker(constants.JobHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		snapshot := *run.job
+		snapshot.Progress, snapshot.Total, _, _, _ = run.state()
+		cancelRequested, err := w.jobRepository.Heartbeat(&snapshot, time.Now())
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			cancel(errLeaseLost)
+		case cancelRequested:
+			cancel(ErrJobCancelled)
+		}
+	}
+}
+
+// finish stores the outcome of the attempt and removes the files the job no
+// longer needs.
+func (w *Worker) finish(job *entity.Job, run *Run, err error, cause error) {
+	now := time.Now()
+	attempt := job.Attempts
+	var result entity.JSON
+	job.Progress, job.Total, result, job.ResultKey, job.ResultContentType = run.state()
+	job.LockedAt = nil
+	job.Error = nil
+
+	switch {
+	case errors.Is(cause, errLeaseLost):
+		// another worker runs the job now and owns its files
+		w.logger.Warn("job lease lost", "id", job.ID)
+		return
+	// a handler may record what a cancellation interrupted and return nil, so
+	// the cause goes first
+	case errors.Is(cause, ErrJobCancelled):
+		job.Status = entity.StatusCancelled
+		job.FinishedAt = &now
+	case errors.Is(cause, context.Canceled):
+		// the worker is stopping; the attempt does not count
+		job.Status = entity.StatusQueued
+		job.Attempts--
+		job.RunAt = now
+	case err == nil:
+		job.Status = entity.StatusSucceeded
+		job.Result = result
+		job.FinishedAt = &now
+	case job.Attempts < job.MaxAttempts && w.handlers[job.Type] != nil && !errors.As(err, new(*permanentError)):
+		message := err.Error()
+		job.Status = entity.StatusQueued
+		job.Error = &message
+		job.RunAt = now.Add(w.retryBackoff << (job.Attempts - 1))
+	default:
+		message := err.Error()
+		job.Status = entity.StatusFailed
+		job.Error = &message
+		job.FinishedAt = &now
+	}
+
+	if job.Status != entity.StatusSucceeded {
+		w.discardResult(job)
+	}
+
+	if err := w.jobRepository.Finish(job, attempt); err != nil {
+		w.logger.Error("job outcome not saved", "id", job.ID, "error", err.Error())
+		return
+	}
+
+	if job.FinishedAt != nil && job.InputKey != nil && w.storage != nil {
+		_ = w.storage.Delete(*job.InputKey)
+	}
+
+	w.logger.Info("job "+job.Status, "id", job.ID, "type", job.Type, "attempt", attempt)
+}
+
+func (w *Worker) discardResult(job *entity.Job) {
+	if job.ResultKey != nil && w.storage != nil {
+		_ = w.storage.Delete(*job.ResultKey)
+	}
+	job.ResultKey = nil
+	job.ResultContentType = nil
+}
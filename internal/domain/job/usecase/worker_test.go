@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/domain/job/entity"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// finishedJobs records the outcome saved by the worker.
+type finishedJobs struct {
+	finished *entity.Job
+}
+
+func (r *finishedJobs) FindById(id uint) (*entity.Job, error)                  { return nil, nil }
+func (r *finishedJobs) Create(job *entity.Job) error                           { return nil }
+func (r *finishedJobs) Claim(now time.Time) (*entity.Job, error)               { return nil, nil }
+func (r *finishedJobs) Heartbeat(job *entity.Job, now time.Time) (bool, error) { return false, nil }
+func (r *finishedJobs) Cancel(id uint, now time.Time) error                    { return nil }
+func (r *finishedJobs) RequeueStale(lockedBefore time.Time, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *finishedJobs) Finish(job *entity.Job, attempt int) error {
+	r.finished = job
+	return nil
+}
+
+func TestWorkerFinish(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		cause        error
+		wantStatus   string
+		wantAttempts int
+	}{
+		{
+			name:         "succeeded",
+			wantStatus:   entity.StatusSucceeded,
+			wantAttempts: 1,
+		},
+		{
+			name:         "cancelled handler returning nil stays cancelled",
+			cause:        ErrJobCancelled,
+			wantStatus:   entity.StatusCancelled,
+			wantAttempts: 1,
+		},
+		{
+			name:         "cancelled handler returning the context error",
+			err:          context.Canceled,
+			cause:        ErrJobCancelled,
+			wantStatus:   entity.StatusCancelled,
+			wantAttempts: 1,
+		},
+		{
+			name:         "stopping worker requeues without counting the attempt",
+			cause:        context.Canceled,
+			wantStatus:   entity.StatusQueued,
+			wantAttempts: 0,
+		},
+		{
+			name:         "failure with attempts left is retried",
+			err:          errors.New("boom"),
+			wantStatus:   entity.StatusQueued,
+			wantAttempts: 1,
+		},
+		{
+			name:         "permanent failure",
+			err:          Permanent(errors.New("invalid input")),
+			wantStatus:   entity.StatusFailed,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := &finishedJobs{}
+			worker := NewWorker(repository, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), 1, time.Second, time.Second)
+			worker.Register("test", func(ctx context.Context, run *Run) error { return nil })
+
+			job := &entity.Job{ID: 1, Type: "test", Status: entity.StatusRunning, Attempts: 1, MaxAttempts: 3}
+			worker.finish(job, &Run{job: job}, tt.err, tt.cause)
+
+			if repository.finished == nil {
+				t.Fatal("finish() did not save the outcome")
+			}
+			if repository.finished.Status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", repository.finished.Status, tt.wantStatus)
+			}
+			if repository.finished.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", repository.finished.Attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
@@ -3,10 +3,15 @@ package product
 import (
 	"context"
 	"ecommerce/config"
+	"ecommerce/constants"
 	BrandRepository "ecommerce/internal/domain/brand/repository"
+	JobDeps "ecommerce/internal/domain/job"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
 	"ecommerce/internal/domain/product/presenter"
 	ProductRepository "ecommerce/internal/domain/product/repository"
 	"ecommerce/internal/domain/product/usecase"
+	TaxDeps "ecommerce/internal/domain/tax"
+	"ecommerce/pkg/storage"
 	"log/slog"
 )
 
@@ -15,8 +20,55 @@ func NewProductDependency(
 	dbProvider *config.DatabaseConfiguration,
 	logger *slog.Logger,
 ) presenter.IProductPresenter {
+	bulkMode := config.AppConfig.BulkDefaultMode
+	if bulkMode == "" {
+		bulkMode = constants.DefaultBulkMode
+	}
+
+	jobUseCase := JobDeps.NewJobUseCase(ctx, dbProvider, logger)
+	return presenter.NewProductPresenter(NewProductUseCase(ctx, dbProvider, logger), jobUseCase, bulkMode, config.AppConfig.RequireIfMatch)
+}
+
+// RegisterJobs sets the handlers of the product jobs. Every run gets a use
+// case bound to the context of the job so that cancelling the job aborts its
+// queries.
+func RegisterJobs(worker *JobUseCase.Worker, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) {
+	worker.Register(usecase.JobImport, func(ctx context.Context, run *JobUseCase.Run) error {
+		return NewProductUseCase(ctx, dbProvider, logger).RunImport(ctx, run)
+	})
+
+	worker.Register(usecase.JobExport, func(ctx context.Context, run *JobUseCase.Run) error {
+		return NewProductUseCase(ctx, dbProvider, logger).RunExport(ctx, run)
+	})
+}
+
+// NewProductUseCase wires the product use case for the domains that build on
+// product listings, such as collections.
+func NewProductUseCase(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) usecase.IProductUseCase {
 	productRepository := ProductRepository.NewProductRepository(ctx, dbProvider, logger)
 	brandRepository := BrandRepository.NewBrandRepository(ctx, dbProvider, logger)
-	useCase := usecase.NewProductUseCase(productRepository, brandRepository)
-	return presenter.NewProductPresenter(useCase)
+	taxCalculator := TaxDeps.NewTaxCalculator(ctx, dbProvider, logger)
+
+	var fileStorage storage.IStorage
+	localStorage, err := storage.NewLocalStorage(config.AppConfig.StorageLocalPath)
+	if err != nil {
+		logger.Error(err.Error())
+	} else {
+		fileStorage = localStorage
+	}
+
+	var signer *storage.URLSigner
+	if config.AppConfig.DownloadSigningSecret != "" {
+		ttl := config.AppConfig.DownloadLinkTTL
+		if ttl <= 0 {
+			ttl = constants.DefaultDownloadLinkTTL
+		}
+		signer = storage.NewURLSigner(config.AppConfig.DownloadSigningSecret, ttl)
+	}
+
+	return usecase.NewProductUseCase(productRepository, brandRepository, taxCalculator, fileStorage, signer, config.SearchIndexProvider)
 }
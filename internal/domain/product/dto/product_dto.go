@@ -1,40 +1,553 @@
 package dto
 
-import "ecommerce/internal/domain/brand/dto"
+import (
+	"context"
+	"ecommerce/internal/domain/brand/dto"
+	TaxDto "ecommerce/internal/domain/tax/dto"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/jsonapi"
+	"ecommerce/pkg/ordering"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
 
+// CreateProductDTO requires Qty only for physical products; Type defaults to
+// physical.
 type CreateProductDTO struct {
-	Name    string `json:"name" validate:"required"`
-	Price   int    `json:"price" validate:"required,numeric"`
-	Qty     int    `json:"qty" validate:"required,numeric"`
-	BrandId int64  `json:"brand_id" validate:"required,numeric"`
+	Name        string   `json:"name" validate:"required"`
+	Description *string  `json:"description"`
+	Type        string   `json:"type" validate:"omitempty,oneof=physical digital service"`
+	Price       int      `json:"price" validate:"required,numeric"`
+	Qty         int      `json:"qty" validate:"required_if=Type physical,required_if=Type '',numeric"`
+	BrandId     int64    `json:"brand_id" validate:"required,numeric"`
+	Barcode     *string  `json:"barcode" validate:"omitempty,gtin"`
+	Tags        []string `json:"tags" validate:"omitempty,max=20,dive,max=50"`
+
+	TaxClassId *int64 `json:"tax_class_id" validate:"omitempty,gte=0"`
+
+	Weight     *WeightDTO     `json:"weight"`
+	Dimensions *DimensionsDTO `json:"dimensions"`
+	Content    *ContentDTO    `json:"content"`
 }
 
+// UpdateProductDTO is the document of a product that PATCH bodies apply to.
+// A merge patch changes only the members present in the body and clears the
+// nullable ones set to null; an empty barcode or a tax_class_id of 0 clears
+// it as well. The patched document must still make a valid product.
+// Measurements are read back in base units.
 type UpdateProductDTO struct {
-	ID      int64  `json:"id" swaggerignore:"true"`
-	Name    string `json:"name"`
-	Price   int    `json:"price" validate:"numeric"`
-	Qty     int    `json:"qty" validate:"numeric"`
-	BrandId int64  `json:"brand_id" validate:"numeric"`
+	ID          int64    `json:"-" swaggerignore:"true"`
+	Name        string   `json:"name" validate:"required"`
+	Description *string  `json:"description"`
+	Type        string   `json:"type" validate:"required,oneof=physical digital service"`
+	Price       int      `json:"price" validate:"gte=0"`
+	Qty         int      `json:"qty" validate:"gte=0"`
+	BrandId     int64    `json:"brand_id" validate:"gt=0"`
+	Barcode     *string  `json:"barcode" validate:"omitempty,gtin"`
+	Tags        []string `json:"tags" validate:"omitempty,max=20,dive,max=50"`
+
+	TaxClassId *int64 `json:"tax_class_id" validate:"omitempty,gte=0"`
+
+	Weight     *WeightDTO     `json:"weight"`
+	Dimensions *DimensionsDTO `json:"dimensions"`
+	Content    *ContentDTO    `json:"content"`
+}
+
+// Formats of a PatchProductDTO.
+const (
+	PatchFormatMergePatch = "merge-patch"
+	PatchFormatJSONPatch  = "json-patch"
+)
+
+// PatchProductDTO is a PATCH body: an RFC 7396 merge patch or an RFC 6902
+// JSON patch of the product document.
+type PatchProductDTO struct {
+	ID      int64
+	Format  string
+	Patch   json.RawMessage
+	IfMatch string
+}
+
+// BulkProductDTO carries the bulk operations that passed validation; Index
+// points back into the request.
+type BulkProductDTO struct {
+	Mode       string
+	Operations []*BulkProductOperationDTO
+	// Context stops the write between operations once it is done; a nil
+	// Context never does.
+	Context context.Context
+	// Progress is told how many of the operations are written.
+	Progress func(done int, total int)
+}
+
+type BulkProductOperationDTO struct {
+	Index  int
+	Op     string
+	ID     int64
+	Create *CreateProductDTO
+	Update *PatchProductDTO
+}
+
+// ImportProductsDTO is a CSV product import. Mapping renames CSV headers to
+// import fields; headers already named after a field need no mapping. It is
+// the payload of an import job, which reads File from the job input.
+type ImportProductsDTO struct {
+	File         io.Reader         `json:"-"`
+	Mapping      map[string]string `json:"mapping"`
+	Mode         string            `json:"mode"`
+	DryRun       bool              `json:"dry_run"`
+	CreateBrands bool              `json:"create_brands"`
+	// Context stops the import between rows once it is done.
+	Context context.Context `json:"-"`
+	// Progress is told how many of the rows are validated in a dry run, and
+	// how many are written otherwise.
+	Progress func(done int, total int) `json:"-"`
+}
+
+// ImportReportDTO reports every imported row. NewBrands are the brands the
+// import created, or would create in a dry run.
+type ImportReportDTO struct {
+	*bulk.Report
+	DryRun    bool     `json:"dry_run"`
+	Valid     int      `json:"valid"`
+	NewBrands []string `json:"new_brands"`
+}
+
+// ProductImportFields lists the CSV columns a product import understands.
+// Rows with an id update that product and the others create one; brand is
+// matched by name and tags are comma separated.
+var ProductImportFields = []string{
+	"id", "name", "description", "type", "price", "qty", "brand", "brand_id",
+	"barcode", "tags", "tax_class_id", "weight", "weight_unit",
+}
+
+// ExportProductDTO is a row of a product export, scanned straight from the
+// export query. Tags are comma separated and the weight is in grams.
+type ExportProductDTO struct {
+	ID          int64
+	Name        string
+	Description *string
+	Type        string
+	Price       int
+	Qty         int
+	BrandId     int64
+	BrandName   *string
+	Barcode     *string
+	Tags        *string
+	TaxClassId  *int64
+	WeightGrams *float64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ExportProductsDTO is the payload of an export job. Query holds the filter
+// and sort params of the product list.
+type ExportProductsDTO struct {
+	Format string `json:"format"`
+	Query  string `json:"query"`
+}
+
+// ProductExportColumns are the columns of a product export. They include
+// every import field, so an exported file can be imported again.
+var ProductExportColumns = []string{
+	"id", "name", "description", "type", "price", "qty", "brand_id", "brand",
+	"barcode", "tags", "tax_class_id", "weight", "weight_unit", "created_at",
+	"updated_at",
+}
+
+// Values returns the row in ProductExportColumns order; missing values are nil.
+func (p *ExportProductDTO) Values() []interface{} {
+	var weight, weightUnit interface{}
+	if p.WeightGrams != nil {
+		weight, weightUnit = *p.WeightGrams, "g"
+	}
+
+	return []interface{}{
+		p.ID, p.Name, optional(p.Description), p.Type, p.Price, p.Qty, p.BrandId,
+		optional(p.BrandName), optional(p.Barcode), optional(p.Tags), optional(p.TaxClassId),
+		weight, weightUnit, p.CreatedAt, p.UpdatedAt,
+	}
+}
+
+func optional[T any](value *T) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
 }
 
 type ProductWithIdDTO struct {
-	ID int64 `json:"id" form:"id" param:"id" query:"id"`
+	ID         int64             `json:"id" form:"id" param:"id" query:"id"`
+	WeightUnit string            `json:"weight_unit" query:"weight_unit" validate:"omitempty,oneof=g kg lb oz"`
+	LengthUnit string            `json:"length_unit" query:"length_unit" validate:"omitempty,oneof=mm cm m in"`
+	TaxRegion  string            `json:"tax_region" query:"tax_region" validate:"omitempty,max=10"`
+	Selection  *fields.Selection `json:"-" swaggerignore:"true"`
+	IfMatch    string            `json:"-" swaggerignore:"true"`
+}
+
+type WeightDTO struct {
+	Value float64 `json:"value" validate:"gte=0"`
+	Unit  string  `json:"unit" validate:"required,oneof=g kg lb oz"`
+}
+
+type DimensionsDTO struct {
+	Length float64 `json:"length" validate:"gt=0"`
+	Width  float64 `json:"width" validate:"gt=0"`
+	Height float64 `json:"height" validate:"gt=0"`
+	Unit   string  `json:"unit" validate:"required,oneof=mm cm m in"`
+}
+
+// ContentDTO is the net quantity sold, used to derive the unit price.
+type ContentDTO struct {
+	Value float64 `json:"value" validate:"gt=0"`
+	Unit  string  `json:"unit" validate:"required,oneof=g kg lb oz ml l"`
+}
+
+type UnitPriceDTO struct {
+	Amount float64 `json:"amount"`
+	Per    string  `json:"per"`
+}
+
+type ProductWithBarcodeDTO struct {
+	Barcode   string            `json:"barcode" param:"code" validate:"required,gtin"`
+	Selection *fields.Selection `json:"-" swaggerignore:"true"`
 }
 
+// FindProductDTO renders Price as the net amount; Pricing splits it into the
+// net, tax and gross amounts of the requested tax region.
 type FindProductDTO struct {
-	ID        int64             `json:"id"`
-	Name      string            `json:"name"`
-	Price     int               `json:"price"`
-	Qty       int               `json:"qty"`
-	Brand     *dto.FindBrandDTO `json:"brand"`
-	CreatedAt string            `json:"created_at"`
-	UpdatedAt string            `json:"updated_at"`
+	ID          int64                   `json:"id"`
+	Name        string                  `json:"name"`
+	Description *string                 `json:"description"`
+	Type        string                  `json:"type"`
+	Price       int                     `json:"price"`
+	Qty         int                     `json:"qty"`
+	Barcode     *string                 `json:"barcode"`
+	Pricing     *TaxDto.TaxBreakdownDTO `json:"pricing"`
+	Brand       *dto.FindBrandDTO       `json:"brand"`
+	Tags        []string                `json:"tags"`
+
+	Weight           *WeightDTO     `json:"weight"`
+	Dimensions       *DimensionsDTO `json:"dimensions"`
+	VolumetricWeight *WeightDTO     `json:"volumetric_weight"`
+	Content          *ContentDTO    `json:"content"`
+	UnitPrice        *UnitPriceDTO  `json:"unit_price"`
+
+	Files []*ProductFileDTO `json:"files,omitempty"`
+
+	// Version is the row version the ETag header is made of.
+	Version   int    `json:"version"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ProductFileDTO struct {
+	ID          int64  `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type ProductFileWithIdDTO struct {
+	ProductId int64 `json:"product_id" param:"id"`
+	FileId    int64 `json:"file_id" param:"fileId"`
+}
+
+type DownloadLinkDTO struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
 }
 
+type DownloadDTO struct {
+	FileId    int64  `json:"file_id" param:"fileId" validate:"required,gt=0"`
+	Expires   int64  `json:"expires" query:"expires" validate:"required,gt=0"`
+	Signature string `json:"signature" query:"signature" validate:"required,hexadecimal"`
+}
+
+// ProductPaginationDTO pages by PerPage and Page, or by cursor once Limit is
+// set. WithCount asks a cursor page for the total, which offset pages always
+// carry.
 type ProductPaginationDTO struct {
-	PerPage int64  `json:"per_page" query:"per_page" validate:"required,number"`
-	Page    int64  `json:"page" query:"page" validate:"required,number"`
-	Sort    string `json:"sort" query:"sort" validate:"required,oneof=asc desc"`
-	SortBy  string `json:"sort_by" query:"sort_by"`
-	Search  string `json:"search" query:"search"`
+	PerPage   int64             `json:"per_page" query:"per_page" validate:"required_without=Limit,number"`
+	Page      int64             `json:"page" query:"page" validate:"required_without=Limit,number"`
+	Limit     int64             `json:"limit" query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Cursor    *cursor.Cursor    `json:"-" swaggerignore:"true"`
+	WithCount bool              `json:"count" query:"count"`
+	OrderBy   []ordering.Key    `json:"-" swaggerignore:"true"`
+	Selection *fields.Selection `json:"-" swaggerignore:"true"`
+	ProductFilterDTO
+	WeightUnit string `json:"weight_unit" query:"weight_unit" validate:"omitempty,oneof=g kg lb oz"`
+	LengthUnit string `json:"length_unit" query:"length_unit" validate:"omitempty,oneof=mm cm m in"`
+	TaxRegion  string `json:"tax_region" query:"tax_region" validate:"omitempty,max=10"`
+}
+
+// ProductFilterDTO is the filter set shared by the product list and its
+// facets. Prices are compared against the stored price.
+type ProductFilterDTO struct {
+	Search           string   `json:"search" query:"search" validate:"max=200"`
+	BrandId          int64    `json:"brand_id" query:"brand_id" validate:"omitempty,gt=0"`
+	IncludeSubBrands bool     `json:"include_sub_brands" query:"include_sub_brands"`
+	Tags             []string `json:"tags" query:"tags"`
+	TagMatch         string   `json:"tag_match" query:"tag_match" validate:"omitempty,oneof=any all"`
+	MinPrice         *int     `json:"min_price" query:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice         *int     `json:"max_price" query:"max_price" validate:"omitempty,gte=0"`
+	InStock          *bool    `json:"in_stock" query:"in_stock"`
+	BrandIds         []int64  `json:"-" swaggerignore:"true"`
+	CollectionId     int64    `json:"-" swaggerignore:"true"`
+
+	Filters *filter.Expression `json:"-" swaggerignore:"true"`
+}
+
+// ProductQuery parses the filters and sort of a product list query into
+// params for an export.
+func ProductQuery(values url.Values) (*ProductPaginationDTO, error) {
+	params := &ProductPaginationDTO{}
+	if err := params.FromQuery(values); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if params.Filters, err = filter.Parse(values, ProductFilterFields); err != nil {
+		return nil, err
+	}
+
+	if params.OrderBy, err = ProductSortFields.Parse(ordering.FromQuery(values)); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// FromQuery reads the named filter params of a product list query. The
+// field[operator] params are parsed separately into Filters.
+func (f *ProductFilterDTO) FromQuery(values url.Values) error {
+	var err error
+	f.Search = values.Get("search")
+
+	if brandIdParam := values.Get("brand_id"); brandIdParam != "" {
+		f.BrandId, err = strconv.ParseInt(brandIdParam, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+
+	if includeSubBrandsParam := values.Get("include_sub_brands"); includeSubBrandsParam != "" {
+		f.IncludeSubBrands, err = strconv.ParseBool(includeSubBrandsParam)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tagsParam := values.Get("tags"); tagsParam != "" {
+		f.Tags = strings.Split(tagsParam, ",")
+	}
+
+	f.TagMatch = values.Get("tag_match")
+
+	if minPriceParam := values.Get("min_price"); minPriceParam != "" {
+		minPrice, err := strconv.Atoi(minPriceParam)
+		if err != nil {
+			return err
+		}
+		f.MinPrice = &minPrice
+	}
+
+	if maxPriceParam := values.Get("max_price"); maxPriceParam != "" {
+		maxPrice, err := strconv.Atoi(maxPriceParam)
+		if err != nil {
+			return err
+		}
+		f.MaxPrice = &maxPrice
+	}
+
+	if inStockParam := values.Get("in_stock"); inStockParam != "" {
+		inStock, err := strconv.ParseBool(inStockParam)
+		if err != nil {
+			return err
+		}
+		f.InStock = &inStock
+	}
+
+	return nil
+}
+
+// ProductSortFields lists the names the product list can be sorted by;
+// relevance ranks full text search matches.
+var ProductSortFields = ordering.Registry{
+	"id":         {Table: "products", Name: "id"},
+	"name":       {Table: "products", Name: "name"},
+	"price":      {Table: "products", Name: "price"},
+	"qty":        {Table: "products", Name: "qty"},
+	"created_at": {Table: "products", Name: "created_at"},
+	"updated_at": {Table: "products", Name: "updated_at"},
+	"relevance":  {},
+}
+
+// ProductFields maps the fields a product response can be narrowed to with
+// ?fields= to the columns they are rendered from; price is rendered through
+// the tax class.
+var ProductFields = fields.Registry{
+	"id":                {"id"},
+	"name":              {"name"},
+	"description":       {"description"},
+	"type":              {"type"},
+	"price":             {"price", "tax_class_id"},
+	"qty":               {"qty"},
+	"barcode":           {"barcode"},
+	"pricing":           {"price", "tax_class_id"},
+	"weight":            {"weight_grams"},
+	"dimensions":        {"length_cm", "width_cm", "height_cm"},
+	"volumetric_weight": {"length_cm", "width_cm", "height_cm"},
+	"content":           {"content_amount", "content_unit"},
+	"unit_price":        {"price", "tax_class_id", "content_amount", "content_unit"},
+	"version":           {"version"},
+	"created_at":        {"created_at"},
+	"updated_at":        {"updated_at"},
+}
+
+// ProductIncludes lists the relations ?include= can load; files are only
+// rendered for digital products.
+var ProductIncludes = fields.Registry{
+	"brand": {"brand_id"},
+	"tags":  {},
+	"files": {"type"},
+}
+
+// ProductResource describes products to JSON:API clients; their brand and
+// files are included in the document.
+var ProductResource = &jsonapi.Schema{
+	Type: "products",
+	Relations: []*jsonapi.Relation{
+		{Name: "brand", Key: "brand", Type: dto.BrandResource.Type, Embedded: true, Relations: dto.BrandResource.Relations},
+		{Name: "files", Key: "files", Type: "product-files", Embedded: true},
+	},
+}
+
+// ProductFilterFields whitelists the field[operator] filters of the product
+// list, e.g. price[gte]=1000 or brand_id[in]=1,2.
+var ProductFilterFields = filter.Whitelist{
+	"id":           {Table: "products", Column: "id", Type: filter.Int, Operators: filter.Equality},
+	"name":         {Table: "products", Column: "name", Type: filter.String, Operators: filter.Equality},
+	"type":         {Table: "products", Column: "type", Type: filter.String, Operators: filter.Equality},
+	"price":        {Table: "products", Column: "price", Type: filter.Int, Operators: filter.Comparable},
+	"qty":          {Table: "products", Column: "qty", Type: filter.Int, Operators: filter.Comparable},
+	"brand_id":     {Table: "products", Column: "brand_id", Type: filter.Int, Operators: filter.Equality},
+	"tax_class_id": {Table: "products", Column: "tax_class_id", Type: filter.Int, Operators: []filter.Operator{filter.Eq, filter.Ne, filter.In, filter.Nin, filter.Null}},
+	"created_at":   {Table: "products", Column: "created_at", Type: filter.Time, Operators: filter.Comparable},
+	"updated_at":   {Table: "products", Column: "updated_at", Type: filter.Time, Operators: filter.Comparable},
+}
+
+type ProductFacetsDTO struct {
+	Total        int64                 `json:"total"`
+	Brands       []*BrandFacetDTO      `json:"brands"`
+	PriceRanges  []*PriceRangeFacetDTO `json:"price_ranges"`
+	Availability *AvailabilityFacetDTO `json:"availability"`
+}
+
+type BrandFacetDTO struct {
+	ID    int64  `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// PriceRangeFacetDTO is one histogram bucket; Min and Max are inclusive.
+type PriceRangeFacetDTO struct {
+	Min   int   `json:"min"`
+	Max   int   `json:"max"`
+	Count int64 `json:"count"`
+}
+
+type AvailabilityFacetDTO struct {
+	InStock    int64 `json:"in_stock"`
+	OutOfStock int64 `json:"out_of_stock"`
+}
+
+// SearchProductsDTO queries the search index: Query is matched against the
+// name, brand name and description, and each filter keeps the products having
+// any of its values.
+type SearchProductsDTO struct {
+	Query      string            `json:"q" query:"q" validate:"required,max=200"`
+	BrandIds   []int64           `json:"brand_id" query:"brand_id" validate:"omitempty,max=50,dive,gt=0"`
+	Types      []string          `json:"type" query:"type" validate:"omitempty,dive,oneof=physical digital service"`
+	Tags       []string          `json:"tags" query:"tags" validate:"omitempty,max=20,dive,max=50"`
+	InStock    *bool             `json:"in_stock" query:"in_stock"`
+	MinPrice   *int              `json:"min_price" query:"min_price" validate:"omitempty,gte=0"`
+	MaxPrice   *int              `json:"max_price" query:"max_price" validate:"omitempty,gte=0"`
+	PerPage    int64             `json:"per_page" query:"per_page" validate:"gte=1,lte=100"`
+	Page       int64             `json:"page" query:"page" validate:"gte=1"`
+	WeightUnit string            `json:"weight_unit" query:"weight_unit" validate:"omitempty,oneof=g kg lb oz"`
+	LengthUnit string            `json:"length_unit" query:"length_unit" validate:"omitempty,oneof=mm cm m in"`
+	TaxRegion  string            `json:"tax_region" query:"tax_region" validate:"omitempty,max=10"`
+	Selection  *fields.Selection `json:"-" swaggerignore:"true"`
+}
+
+// FromQuery reads the query and filters of a product search.
+func (s *SearchProductsDTO) FromQuery(values url.Values) error {
+	s.Query = strings.TrimSpace(values.Get("q"))
+
+	if brandIdParam := values.Get("brand_id"); brandIdParam != "" {
+		for _, raw := range strings.Split(brandIdParam, ",") {
+			brandId, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+			if err != nil {
+				return err
+			}
+			s.BrandIds = append(s.BrandIds, brandId)
+		}
+	}
+
+	if typeParam := values.Get("type"); typeParam != "" {
+		s.Types = strings.Split(typeParam, ",")
+	}
+
+	if tagsParam := values.Get("tags"); tagsParam != "" {
+		s.Tags = strings.Split(tagsParam, ",")
+	}
+
+	if inStockParam := values.Get("in_stock"); inStockParam != "" {
+		inStock, err := strconv.ParseBool(inStockParam)
+		if err != nil {
+			return err
+		}
+		s.InStock = &inStock
+	}
+
+	if minPriceParam := values.Get("min_price"); minPriceParam != "" {
+		minPrice, err := strconv.Atoi(minPriceParam)
+		if err != nil {
+			return err
+		}
+		s.MinPrice = &minPrice
+	}
+
+	if maxPriceParam := values.Get("max_price"); maxPriceParam != "" {
+		maxPrice, err := strconv.Atoi(maxPriceParam)
+		if err != nil {
+			return err
+		}
+		s.MaxPrice = &maxPrice
+	}
+
+	s.WeightUnit = values.Get("weight_unit")
+	s.LengthUnit = values.Get("length_unit")
+	s.TaxRegion = values.Get("tax_region")
+	return nil
+}
+
+// ProductSearchDTO is a page of search hits, best match first, with the
+// number of hits per value of brand_id, type, tags and in_stock.
+type ProductSearchDTO struct {
+	Products []*FindProductDTO            `json:"products"`
+	Facets   map[string][]*SearchFacetDTO `json:"facets"`
+}
+
+type SearchFacetDTO struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
 }
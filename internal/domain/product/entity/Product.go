@@ -5,15 +5,50 @@ import (
 	"time"
 )
 
+const (
+	ProductTypePhysical = "physical"
+	ProductTypeDigital  = "digital"
+	ProductTypeService  = "service"
+)
+
 type Product struct {
-	ID        uint `gorm:"primary_key"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt
-	Name      string
-	Price     int
-	Qty       int
-	BrandId   int
+	ID          uint `gorm:"primary_key"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt
+	Name        string
+	Description *string
+	Type        string
+	Price       int
+	Qty         int
+	BrandId     int
+	Barcode     *string
+	Version     int
+	TaxClassId  *uint
+	// measurements are stored in base units: grams, centimeters and either
+	// grams or milliliters for the net content
+	WeightGrams   *float64
+	LengthCm      *float64
+	WidthCm       *float64
+	HeightCm      *float64
+	ContentAmount *float64
+	ContentUnit   *string
+	Tags          []ProductTag  `gorm:"foreignKey:ProductId"`
+	Files         []ProductFile `gorm:"foreignKey:ProductId"`
+}
+
+// IsShippable reports whether the product has stock and is shipped, which only
+// holds for physical products.
+func (p *Product) IsShippable() bool {
+	return p.Type == "" || p.Type == ProductTypePhysical
+}
+
+func (p *Product) TagNames() []string {
+	tags := make([]string, 0, len(p.Tags))
+	for _, t := range p.Tags {
+		tags = append(tags, t.Tag)
+	}
+	return tags
 }
 
 func (Product) TableName() string {
@@ -22,6 +57,9 @@ func (Product) TableName() string {
 
 func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	p.CreatedAt = time.Now()
+	if p.Version == 0 {
+		p.Version = 1
+	}
 	return nil
 }
 
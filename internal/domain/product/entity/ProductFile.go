@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ProductFile is a downloadable file attached to a digital product. The
+// content lives in file storage under StorageKey.
+type ProductFile struct {
+	ID          uint `gorm:"primary_key"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt
+	ProductId   uint
+	FileName    string
+	StorageKey  string
+	ContentType string
+	SizeBytes   int64
+}
+
+func (ProductFile) TableName() string {
+	return "product_files"
+}
+
+func (f *ProductFile) BeforeCreate(tx *gorm.DB) error {
+	f.CreatedAt = time.Now()
+	f.UpdatedAt = f.CreatedAt
+	return nil
+}
+
+func (f *ProductFile) BeforeUpdate(tx *gorm.DB) error {
+	f.UpdatedAt = time.Now()
+	return nil
+}
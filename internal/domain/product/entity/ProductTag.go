@@ -0,0 +1,36 @@
+package entity
+
+import "strings"
+
+type ProductTag struct {
+	ProductId uint   `gorm:"primaryKey"`
+	Tag       string `gorm:"primaryKey"`
+}
+
+func (ProductTag) TableName() string {
+	return "product_tags"
+}
+
+// NormalizeTags trims and lower-cases tags, dropping empty and duplicate
+// entries while preserving the original order.
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func NewProductTags(tags []string) []ProductTag {
+	productTags := make([]ProductTag, 0, len(tags))
+	for _, tag := range NormalizeTags(tags) {
+		productTags = append(productTags, ProductTag{Tag: tag})
+	}
+	return productTags
+}
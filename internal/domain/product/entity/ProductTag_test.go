@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "nil", tags: nil, want: []string{}},
+		{name: "case and spaces", tags: []string{" Sale ", "NEW"}, want: []string{"sale", "new"}},
+		{name: "duplicates keep first", tags: []string{"new", "sale", "New "}, want: []string{"new", "sale"}},
+		{name: "blank dropped", tags: []string{"", "  ", "gift"}, want: []string{"gift"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,84 +1,302 @@
 package presenter
 
 import (
+	"ecommerce/constants"
+	JobDto "ecommerce/internal/domain/job/dto"
+	JobPresenter "ecommerce/internal/domain/job/presenter"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
 	"ecommerce/internal/domain/product/dto"
 	"ecommerce/internal/domain/product/usecase"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/export"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/jsonapi"
+	"ecommerce/pkg/jsonpatch"
+	"ecommerce/pkg/ordering"
 	HttpResponser "ecommerce/pkg/response"
+	"encoding/json"
 	"github.com/labstack/echo/v4"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type IProductPresenter interface {
 	GetAll(c echo.Context) error
+	GetFacets(c echo.Context) error
+	Search(c echo.Context) error
 	Get(c echo.Context) error
+	GetByBarcode(c echo.Context) error
 	Create(c echo.Context) error
 	Update(c echo.Context) error
 	Delete(c echo.Context) error
+	Bulk(c echo.Context) error
+	Import(c echo.Context) error
+	Export(c echo.Context) error
+	ExportAsync(c echo.Context) error
+	UploadFile(c echo.Context) error
+	CreateDownloadLink(c echo.Context) error
+	Download(c echo.Context) error
 }
 
 type ProductPresenter struct {
-	useCase usecase.IProductUseCase
+	useCase        usecase.IProductUseCase
+	jobUseCase     JobUseCase.IJobUseCase
+	bulkMode       string
+	requireIfMatch bool
 }
 
-func NewProductPresenter(useCase usecase.IProductUseCase) *ProductPresenter {
-	return &ProductPresenter{useCase}
+func NewProductPresenter(useCase usecase.IProductUseCase, jobUseCase JobUseCase.IJobUseCase, bulkMode string, requireIfMatch bool) *ProductPresenter {
+	return &ProductPresenter{useCase: useCase, jobUseCase: jobUseCase, bulkMode: bulkMode, requireIfMatch: requireIfMatch}
 }
 
 // GetAll godoc
 // @Summary      Get All product
-// @Description  Get All product data
+// @Description  Get All product data. Filter with field[operator]=value params on id, name, type, price, qty, brand_id, tax_class_id, created_at and updated_at, e.g. price[gte]=1000&brand_id[in]=1,2; operators are eq, ne, gt, gte, lt, lte, in, nin and null. Passing limit or cursor pages by cursor instead of PerPage and Page and responds with limit, next_cursor, prev_cursor and, when count=true, total_items
 // @Tags         product
 // @Accept       json
-// @Produce      json
-// @Param 		 PerPage query int true "item per page count"
-// @Param 		 Page query int true "page"
-// @Param 		 Sort query string true "sorting order (desc, asc)"
-// @Param 		 SortBy query string true "sorting fields (default created_at)"
-// @Param 		 Search query string false "product param query"
+// @Produce      json,application/vnd.api+json
+// @Param 		 per_page query int false "item per page count, required without limit"
+// @Param 		 page query int false "page, required without limit"
+// @Param 		 limit query int false "cursor page size (1-100, default 10); switches to cursor pagination"
+// @Param 		 cursor query string false "next_cursor or prev_cursor of a previous cursor page"
+// @Param 		 count query bool false "include total_items in a cursor page"
+// @Param 		 sort query string false "comma separated sort fields (id, name, price, qty, created_at, updated_at, relevance), prefixed by - for descending, e.g. -price,name (default -created_at, -relevance when searching)"
+// @Param 		 SortBy query string false "deprecated, use sort"
+// @Param 		 Sort query string false "deprecated, direction of SortBy (desc, asc)"
+// @Param 		 search query string false "full text search over name, brand name and description"
+// @Param 		 brand_id query int false "filter by brand id"
+// @Param 		 include_sub_brands query bool false "also include products of sub-brands of brand_id"
+// @Param 		 tags query string false "comma separated tags"
+// @Param 		 tag_match query string false "match any or all of the tags (default any)"
+// @Param 		 min_price query int false "minimum stored price"
+// @Param 		 max_price query int false "maximum stored price"
+// @Param 		 in_stock query bool false "only products that are (true) or are not (false) available"
+// @Param 		 weight_unit query string false "weight display unit (g, kg, lb, oz; default kg)"
+// @Param 		 length_unit query string false "length display unit (mm, cm, m, in; default cm)"
+// @Param 		 tax_region query string false "tax region code (default from TAX_DEFAULT_REGION)"
+// @Param 		 fields query string false "comma separated fields to return (id, name, description, type, price, qty, barcode, pricing, weight, dimensions, volumetric_weight, content, unit_price, version, created_at, updated_at; default all)"
+// @Param 		 include query string false "comma separated relations to return (brand, tags, files; default all unless fields is set)"
 // @Success      200  {object}  response.PaginationResponse{data=[]dto.FindProductDTO}
 // @Router       /products [get]
 func (p *ProductPresenter) GetAll(c echo.Context) error {
 	params := &dto.ProductPaginationDTO{}
-	perPageParam := c.QueryParam("PerPage")
-	pageParam := c.QueryParam("Page")
-	sortParam := c.QueryParam("Sort")
-	sortByParam := c.QueryParam("SortBy")
-	searchParam := c.QueryParam("Search")
+	cursorMode := c.QueryParams().Has("cursor") || c.QueryParams().Has("limit")
+
+	if cursorMode {
+		if err := bindCursor(c, params); err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+	} else {
+		perPage, err := strconv.ParseInt(c.QueryParam("per_page"), 10, 64)
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+
+		page, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+
+		params.PerPage = perPage
+		params.Page = page
+	}
+
+	if err := params.FromQuery(c.QueryParams()); err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	var err error
+	params.Filters, err = filter.Parse(c.QueryParams(), dto.ProductFilterFields)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
+	params.WeightUnit = c.QueryParam("weight_unit")
+	params.LengthUnit = c.QueryParam("length_unit")
+	params.TaxRegion = c.QueryParam("tax_region")
+	params.OrderBy, err = dto.ProductSortFields.Parse(ordering.FromQuery(c.QueryParams()))
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(map[string]interface{}{"sort": err})
+	}
+
+	params.Selection, err = fields.FromQuery(c.QueryParams(), dto.ProductFields, dto.ProductIncludes)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
+	if err := c.Validate(params); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if cursorMode {
+		products, page, err := p.useCase.FindPage(params)
+		if err != nil {
+			c.Logger().Error(err)
+			return err
+		}
+
+		return HttpResponser.CursorPagination(c, page.Next, page.Prev, int(params.Limit), page.Total, jsonapi.Of(dto.ProductResource, params.Selection, products))
+	}
+
+	count, totalPage, products, err := p.useCase.FindAll(params)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Pagination(c, count, totalPage, int(params.PerPage), int(params.Page), jsonapi.Of(dto.ProductResource, params.Selection, products))
+}
 
-	perPage, err := strconv.ParseInt(perPageParam, 10, 64)
+// bindCursor reads the cursor, limit and count query params of a cursor page.
+func bindCursor(c echo.Context, params *dto.ProductPaginationDTO) error {
+	limit, err := cursor.ParseLimit(c.QueryParam("limit"), constants.DefaultCursorLimit)
 	if err != nil {
+		return err
+	}
+	params.Limit = limit
+
+	if raw := c.QueryParam("cursor"); raw != "" {
+		next, err := cursor.Decode(raw)
+		if err != nil {
+			return err
+		}
+		params.Cursor = next
+	}
+
+	if raw := c.QueryParam("count"); raw != "" {
+		withCount, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		params.WithCount = withCount
+	}
+
+	return nil
+}
+
+// GetFacets godoc
+// @Summary      Get product facets
+// @Description  Count the products matching the filters per brand, price range and availability
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 search query string false "full text search over name, brand name and description"
+// @Param 		 brand_id query int false "filter by brand id"
+// @Param 		 include_sub_brands query bool false "also include products of sub-brands of brand_id"
+// @Param 		 tags query string false "comma separated tags"
+// @Param 		 tag_match query string false "match any or all of the tags (default any)"
+// @Param 		 min_price query int false "minimum stored price"
+// @Param 		 max_price query int false "maximum stored price"
+// @Param 		 in_stock query bool false "only products that are (true) or are not (false) available"
+// @Success      200  {object}  response.SuccessResponse{data=dto.ProductFacetsDTO}
+// @Router       /products/facets [get]
+func (p *ProductPresenter) GetFacets(c echo.Context) error {
+	params := &dto.ProductFilterDTO{}
+	if err := params.FromQuery(c.QueryParams()); err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	filters, err := filter.Parse(c.QueryParams(), dto.ProductFilterFields)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+	params.Filters = filters
+
+	if err := c.Validate(params); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	page, err := strconv.ParseInt(pageParam, 10, 64)
+	facets, err := p.useCase.FindFacets(params)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get product facets success", facets)
+}
+
+// Search godoc
+// @Summary      Search products
+// @Description  Search the products in the search index, best match first, with the counts of the brands, types, tags and availability of every match. Answers 503 when the search index is disabled
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 q query string true "text matched against name, brand name and description"
+// @Param 		 per_page query int true "item per page count"
+// @Param 		 page query int true "page"
+// @Param 		 brand_id query string false "comma separated brand ids"
+// @Param 		 type query string false "comma separated product types (physical, digital, service)"
+// @Param 		 tags query string false "comma separated tags, any of which must match"
+// @Param 		 in_stock query bool false "only products that are (true) or are not (false) available"
+// @Param 		 min_price query int false "minimum stored price"
+// @Param 		 max_price query int false "maximum stored price"
+// @Param 		 weight_unit query string false "weight display unit (g, kg, lb, oz; default kg)"
+// @Param 		 length_unit query string false "length display unit (mm, cm, m, in; default cm)"
+// @Param 		 tax_region query string false "tax region code (default from TAX_DEFAULT_REGION)"
+// @Param 		 fields query string false "comma separated fields to return (default all)"
+// @Param 		 include query string false "comma separated relations to return (brand, tags, files; default all unless fields is set)"
+// @Success      200  {object}  response.PaginationResponse{data=dto.ProductSearchDTO}
+// @Router       /products/search [get]
+func (p *ProductPresenter) Search(c echo.Context) error {
+	params := &dto.SearchProductsDTO{}
+
+	perPage, err := strconv.ParseInt(c.QueryParam("per_page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	page, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
 	}
 
-	params.Sort = sortParam
-	params.SortBy = sortByParam
-	params.Search = searchParam
 	params.PerPage = perPage
 	params.Page = page
 
+	if err := params.FromQuery(c.QueryParams()); err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	params.Selection, err = fields.FromQuery(c.QueryParams(), dto.ProductFields, dto.ProductIncludes)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
 	if err := c.Validate(params); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
 	}
 
-	count, totalPage, products, err := p.useCase.FindAll(params)
+	count, totalPage, result, err := p.useCase.Search(params)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewPaginationResponse(count, totalPage, int(params.PerPage), int(params.Page), products))
+	return HttpResponser.Pagination(c, count, totalPage, int(params.PerPage), int(params.Page), result)
 }
 
 // Get godoc
@@ -86,29 +304,89 @@ func (p *ProductPresenter) GetAll(c echo.Context) error {
 // @Description  Get product data
 // @Tags         product
 // @Accept       json
-// @Produce      json
+// @Produce      json,application/vnd.api+json
 // @Param 		 id path int true "product id"
+// @Param 		 weight_unit query string false "weight display unit (g, kg, lb, oz; default kg)"
+// @Param 		 length_unit query string false "length display unit (mm, cm, m, in; default cm)"
+// @Param 		 tax_region query string false "tax region code (default from TAX_DEFAULT_REGION)"
+// @Param 		 fields query string false "comma separated fields to return (id, name, description, type, price, qty, barcode, pricing, weight, dimensions, volumetric_weight, content, unit_price, version, created_at, updated_at; default all)"
+// @Param 		 include query string false "comma separated relations to return (brand, tags, files; default all unless fields is set)"
 // @Success      200  {object}  response.PaginationResponse{data=dto.FindProductDTO}
+// @Header       200  {string}  ETag "version of the product, to send back in If-Match"
 // @Router       /products/{id} [get]
 func (p *ProductPresenter) Get(c echo.Context) error {
 	paramId := c.Param("id")
 	id, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.Invalid(err)
 	}
 
 	payload := &dto.ProductWithIdDTO{
-		ID: id,
+		ID:         id,
+		WeightUnit: c.QueryParam("weight_unit"),
+		LengthUnit: c.QueryParam("length_unit"),
+		TaxRegion:  c.QueryParam("tax_region"),
+	}
+
+	payload.Selection, err = fields.FromQuery(c.QueryParams(), dto.ProductFields, dto.ProductIncludes)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
+	if err := c.Validate(payload); err != nil {
+		c.Logger().Error(err)
+		return err
 	}
 
 	product, err := p.useCase.FindById(payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	c.Response().Header().Set(etag.HeaderETag, etag.Format(product.Version))
+	return HttpResponser.Success(c, http.StatusOK, "Get product success", jsonapi.Of(dto.ProductResource, payload.Selection, product))
+}
+
+// GetByBarcode godoc
+// @Summary      Get product by barcode
+// @Description  Get product data by its GTIN-8, GTIN-12, GTIN-13 or GTIN-14 barcode
+// @Tags         product
+// @Accept       json
+// @Produce      json,application/vnd.api+json
+// @Param 		 code path string true "product barcode"
+// @Param 		 fields query string false "comma separated fields to return (id, name, description, type, price, qty, barcode, pricing, weight, dimensions, volumetric_weight, content, unit_price, version, created_at, updated_at; default all)"
+// @Param 		 include query string false "comma separated relations to return (brand, tags, files; default all unless fields is set)"
+// @Success      200  {object}  response.SuccessResponse{data=dto.FindProductDTO}
+// @Failure      400  {object}  response.Problem
+// @Failure      404  {object}  response.Problem
+// @Router       /products/by-barcode/{code} [get]
+func (p *ProductPresenter) GetByBarcode(c echo.Context) error {
+	payload := &dto.ProductWithBarcodeDTO{
+		Barcode: strings.TrimSpace(c.Param("code")),
+	}
+
+	var err error
+	payload.Selection, err = fields.FromQuery(c.QueryParams(), dto.ProductFields, dto.ProductIncludes)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.InvalidFields(err)
+	}
+
+	if err := c.Validate(payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	product, err := p.useCase.FindByBarcode(payload)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Get product success", product))
+	return HttpResponser.Success(c, http.StatusOK, "Get product success", jsonapi.Of(dto.ProductResource, payload.Selection, product))
 }
 
 // Create godoc
@@ -119,72 +397,82 @@ func (p *ProductPresenter) Get(c echo.Context) error {
 // @Produce      json
 // @Param 		 request body dto.CreateProductDTO true "request body"
 // @Success      200  {object}  response.PaginationResponse{data=nil}
+// @Failure      409  {object}  response.Problem
 // @Router       /products [post]
 func (p *ProductPresenter) Create(c echo.Context) error {
 	payload := &dto.CreateProductDTO{}
 	if err := c.Bind(&payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
 	if err := c.Validate(payload); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return err
 	}
 
 	err := p.useCase.CreateProduct(payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, HttpResponser.NewSuccessResponse("Product created", nil))
+	return HttpResponser.Success(c, http.StatusCreated, "Product created", nil)
 }
 
 // Update godoc
 // @Summary      Update product
-// @Description  Update product data
+// @Description  Update product data with a patch of the product document. An application/merge-patch+json (or application/json) body changes only the members it sets and clears the ones set to null; an application/json-patch+json body is a list of add, remove, replace, move, copy and test operations. Name, type, price, qty and brand_id cannot be removed, and the patched product must pass the rules of a new one
 // @Tags         product
-// @Accept       json
+// @Accept       json,application/merge-patch+json,application/json-patch+json
 // @Produce      json
 // @Param 		 id path int true "product id"
+// @Param 		 If-Match header string false "ETag the product was read with; required when REQUIRE_IF_MATCH is set"
 // @Param 		 request body dto.UpdateProductDTO true "request body"
 // @Success      200  {object}  response.PaginationResponse{data=nil}
+// @Failure      400  {object}  response.Problem
+// @Failure      409  {object}  response.Problem
+// @Failure      412  {object}  response.Problem
+// @Failure      415  {object}  response.Problem
+// @Failure      422  {object}  response.Problem
+// @Failure      428  {object}  response.Problem
 // @Router       /products/{id} [patch]
 func (p *ProductPresenter) Update(c echo.Context) error {
 	paramId := c.Param("id")
 	id, err := strconv.ParseInt(paramId, 10, 64)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+		return apperror.Invalid(err)
 	}
 
-	payload := &dto.UpdateProductDTO{}
-	if err := c.Bind(payload); err != nil {
-		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(err.Error()))
+	payload := &dto.PatchProductDTO{ID: id}
+	switch mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType)); mediaType {
+	case jsonpatch.MediaTypeJSONPatch:
+		payload.Format = dto.PatchFormatJSONPatch
+	case jsonpatch.MediaTypeMergePatch, echo.MIMEApplicationJSON, "":
+		payload.Format = dto.PatchFormatMergePatch
+	default:
+		return echo.ErrUnsupportedMediaType
 	}
 
-	payload.ID = id
-
-	if err := c.Validate(payload); err != nil {
+	payload.Patch, err = io.ReadAll(c.Request().Body)
+	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusBadRequest, HttpResponser.NewErrorResponse(
-			"Bad Request",
-			err.(*echo.HTTPError).Message.(map[string]interface{})["errors"]),
-		)
+		return apperror.Invalid(err)
+	}
+
+	payload.IfMatch = c.Request().Header.Get(etag.HeaderIfMatch)
+	if payload.IfMatch == "" && p.requireIfMatch {
+		return etag.ErrPreconditionRequired
 	}
 
-	err = p.useCase.UpdateProduct(payload)
+	err = p.useCase.PatchProduct(payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
 	}
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Product updated", nil))
+	return HttpResponser.Success(c, http.StatusOK, "Product updated", nil)
 }
 
 // Delete godoc
@@ -194,7 +482,10 @@ func (p *ProductPresenter) Update(c echo.Context) error {
 // @Accept       json
 // @Produce      json
 // @Param 		 id path int true "product id"
+// @Param 		 If-Match header string false "ETag the product was read with; required when REQUIRE_IF_MATCH is set"
 // @Success      200  {object}  response.PaginationResponse{data=nil}
+// @Failure      412  {object}  response.Problem
+// @Failure      428  {object}  response.Problem
 // @Router       /products/{id} [delete]
 func (p *ProductPresenter) Delete(c echo.Context) error {
 	payload := &dto.ProductWithIdDTO{}
@@ -205,12 +496,428 @@ func (p *ProductPresenter) Delete(c echo.Context) error {
 	}
 
 	payload.ID = id
+	payload.IfMatch = c.Request().Header.Get(etag.HeaderIfMatch)
+
+	if payload.IfMatch == "" && p.requireIfMatch {
+		return etag.ErrPreconditionRequired
+	}
 
 	err = p.useCase.DeleteProduct(payload)
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, HttpResponser.NewErrorResponse(err.Error()))
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Product deleted", nil)
+}
+
+// Bulk godoc
+// @Summary      Bulk write products
+// @Description  Create, update and delete products in one request. Each operation names its op (create, update, delete), the product id for update and delete, and data holding the body of the single create or update endpoint. Atomic mode (default from BULK_DEFAULT_MODE) applies every operation or none, best_effort applies each operation that succeeds. Responds 200 when every operation succeeded and 207 with the status and validation errors of each item otherwise
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 request body bulk.Request true "request body"
+// @Success      200  {object}  response.SuccessResponse{data=bulk.Report}
+// @Success      207  {object}  response.SuccessResponse{data=bulk.Report}
+// @Router       /products/bulk [post]
+func (p *ProductPresenter) Bulk(c echo.Context) error {
+	request := &bulk.Request{}
+	if err := c.Bind(request); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := c.Validate(request); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if request.Mode == "" {
+		request.Mode = p.bulkMode
+	}
+
+	report := bulk.NewReport(request)
+	payload := &dto.BulkProductDTO{Mode: request.Mode}
+	for i, op := range request.Operations {
+		if err := c.Validate(op); err != nil {
+			report.Reject(i, err.(*apperror.Error).Details()["errors"])
+			continue
+		}
+
+		item := &dto.BulkProductOperationDTO{Index: i, Op: op.Op, ID: op.ID}
+		var data interface{}
+		switch op.Op {
+		case bulk.OpCreate:
+			item.Create = &dto.CreateProductDTO{}
+			data = item.Create
+		case bulk.OpUpdate:
+			// the data is a merge patch, checked here for the types of the
+			// members it sets; the patched document is validated when it runs
+			item.Update = &dto.PatchProductDTO{Format: dto.PatchFormatMergePatch, Patch: op.Data}
+			data = &dto.UpdateProductDTO{}
+		}
+
+		if data != nil {
+			if len(op.Data) == 0 {
+				report.Reject(i, map[string]string{"data": "Data is required"})
+				continue
+			}
+
+			if err := json.Unmarshal(op.Data, data); err != nil {
+				report.Reject(i, map[string]string{"data": err.Error()})
+				continue
+			}
+
+			if item.Create != nil {
+				if err := c.Validate(data); err != nil {
+					report.Reject(i, err.(*apperror.Error).Details()["errors"])
+					continue
+				}
+			}
+		}
+
+		payload.Operations = append(payload.Operations, item)
+	}
+
+	if err := p.useCase.BulkWrite(payload, report); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	status := http.StatusOK
+	if report.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return HttpResponser.Success(c, status, "Bulk operations processed", report)
+}
+
+// Import godoc
+// @Summary      Import products from CSV
+// @Description  Create and update products from a CSV file, sent as the file form field or as the request body. Columns named after a field (id, name, description, type, price, qty, brand, brand_id, barcode, tags, tax_class_id, weight, weight_unit) are imported and map renames other columns; rows with an id update that product and the others are created. Brands are matched by name and created when missing with create_brands=true. dry_run=true only validates the rows. The import runs as a background job: responds 202 with the job, whose result is the import report with the status, line and errors of each row
+// @Tags         product
+// @Accept       mpfd
+// @Produce      json
+// @Param 		 file formData file false "CSV file"
+// @Param 		 map query []string false "column mapping as header=field, e.g. Title=name" collectionFormat(multi)
+// @Param 		 mode query string false "atomic or best_effort (default from BULK_DEFAULT_MODE)"
+// @Param 		 dry_run query bool false "validate the rows without writing them"
+// @Param 		 create_brands query bool false "create brands that are not found by name"
+// @Success      202  {object}  response.SuccessResponse{data=JobDto.FindJobDTO}
+// @Router       /products/import [post]
+func (p *ProductPresenter) Import(c echo.Context) error {
+	payload := &dto.ImportProductsDTO{
+		Mode:    c.QueryParam("mode"),
+		Mapping: make(map[string]string),
+	}
+
+	if payload.Mode == "" {
+		payload.Mode = p.bulkMode
+	}
+	if payload.Mode != bulk.ModeAtomic && payload.Mode != bulk.ModeBestEffort {
+		return apperror.InvalidFields(map[string]string{"mode": "Mode must be one of [atomic best_effort]"})
+	}
+
+	for name, target := range map[string]*bool{"dry_run": &payload.DryRun, "create_brands": &payload.CreateBrands} {
+		if raw := c.QueryParam(name); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				return apperror.InvalidFields(map[string]string{name: "Must be true or false"})
+			}
+			*target = value
+		}
+	}
+
+	for _, pair := range c.QueryParams()["map"] {
+		column, field, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(column) == "" {
+			return apperror.InvalidFields(map[string]string{"map": "Map must be given as header=field"})
+		}
+		payload.Mapping[strings.TrimSpace(column)] = strings.TrimSpace(field)
+	}
+
+	payload.File = c.Request().Body
+	if mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType)); mediaType == echo.MIMEMultipartForm {
+		header, err := c.FormFile("file")
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+
+		src, err := header.Open()
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+		defer src.Close()
+		payload.File = src
+	}
+
+	// an atomic import runs in one transaction and can be repeated, a best
+	// effort import may have written some rows before it failed
+	enqueue := &JobDto.EnqueueJobDTO{Type: usecase.JobImport, Payload: payload, Input: payload.File}
+	if payload.Mode == bulk.ModeBestEffort && !payload.DryRun {
+		enqueue.MaxAttempts = 1
+	}
+
+	job, err := p.jobUseCase.Enqueue(enqueue)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return JobPresenter.Accepted(c, "Import queued", job)
+}
+
+// Export godoc
+// @Summary      Export products
+// @Description  Stream every product matching the filters, with its brand name and tags, as CSV, NDJSON or XLSX. Takes the filters and sort of the product list; the columns match the product import and the weight is in grams
+// @Tags         product
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param 		 format query string false "csv, ndjson or xlsx (default csv)"
+// @Param 		 sort query string false "comma separated sort fields, as for the product list"
+// @Param 		 search query string false "full text search over name, brand name and description"
+// @Param 		 brand_id query int false "filter by brand id"
+// @Param 		 include_sub_brands query bool false "also include products of sub-brands of brand_id"
+// @Param 		 tags query string false "comma separated tags"
+// @Param 		 tag_match query string false "match any or all of the tags (default any)"
+// @Param 		 min_price query int false "minimum stored price"
+// @Param 		 max_price query int false "maximum stored price"
+// @Param 		 in_stock query bool false "only products that are (true) or are not (false) available"
+// @Success      200  {file}  file
+// @Router       /products/export [get]
+func (p *ProductPresenter) Export(c echo.Context) error {
+	format, params, err := p.bindExport(c)
+	if err != nil {
+		return err
+	}
+
+	writer, err := export.NewWriter(format, c.Response())
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	fileName := "products-" + time.Now().Format("20060102") + "." + format
+	c.Response().Header().Set(echo.HeaderContentType, export.ContentType(format))
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+
+	if _, err := p.useCase.Export(params, writer); err != nil {
+		c.Logger().Error(err)
+		// once rows are streamed the status is sent and the export is cut short
+		if !c.Response().Committed {
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+			c.Response().Header().Del(echo.HeaderContentType)
+			return err
+		}
+		return nil
+	}
+
+	if err := writer.Close(); err != nil {
+		c.Logger().Error(err)
+	}
+	return nil
+}
+
+// ExportAsync godoc
+// @Summary      Export products in the background
+// @Description  Queue an export with the same format, filters and sort as GET /products/export. Responds 202 with the job; once it succeeded the file is downloaded from its result_url
+// @Tags         product
+// @Produce      json
+// @Param 		 format query string false "csv, ndjson or xlsx (default csv)"
+// @Param 		 sort query string false "comma separated sort fields, as for the product list"
+// @Param 		 search query string false "full text search over name, brand name and description"
+// @Param 		 brand_id query int false "filter by brand id"
+// @Param 		 tags query string false "comma separated tags"
+// @Param 		 in_stock query bool false "only products that are (true) or are not (false) available"
+// @Success      202  {object}  response.SuccessResponse{data=JobDto.FindJobDTO}
+// @Router       /products/export [post]
+func (p *ProductPresenter) ExportAsync(c echo.Context) error {
+	format, _, err := p.bindExport(c)
+	if err != nil {
+		return err
+	}
+
+	query := c.QueryParams()
+	query.Del("format")
+
+	job, err := p.jobUseCase.Enqueue(&JobDto.EnqueueJobDTO{
+		Type:    usecase.JobExport,
+		Payload: &dto.ExportProductsDTO{Format: format, Query: query.Encode()},
+	})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return JobPresenter.Accepted(c, "Export queued", job)
+}
+
+// bindExport reads the format, filters and sort of an export.
+func (p *ProductPresenter) bindExport(c echo.Context) (format string, params *dto.ProductPaginationDTO, err error) {
+	format = c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	switch format {
+	case export.FormatCSV, export.FormatNDJSON, export.FormatXLSX:
+	default:
+		c.Logger().Error(export.ErrUnknownFormat)
+		return "", nil, export.ErrUnknownFormat
+	}
+
+	params = &dto.ProductPaginationDTO{}
+	if err := params.FromQuery(c.QueryParams()); err != nil {
+		c.Logger().Error(err)
+		return "", nil, apperror.Invalid(err)
+	}
+
+	params.Filters, err = filter.Parse(c.QueryParams(), dto.ProductFilterFields)
+	if err != nil {
+		c.Logger().Error(err)
+		return "", nil, apperror.InvalidFields(err)
+	}
+
+	params.OrderBy, err = dto.ProductSortFields.Parse(ordering.FromQuery(c.QueryParams()))
+	if err != nil {
+		c.Logger().Error(err)
+		return "", nil, apperror.InvalidFields(map[string]interface{}{"sort": err})
+	}
+
+	if err := c.Validate(&params.ProductFilterDTO); err != nil {
+		c.Logger().Error(err)
+		return "", nil, err
+	}
+
+	return format, params, nil
+}
+
+// UploadFile godoc
+// @Summary      Upload product file
+// @Description  Attach a downloadable file to a digital product
+// @Tags         product
+// @Accept       mpfd
+// @Produce      json
+// @Param 		 id path int true "product id"
+// @Param 		 file formData file true "downloadable file"
+// @Success      201  {object}  response.SuccessResponse{data=dto.ProductFileDTO}
+// @Router       /products/{id}/files [post]
+func (p *ProductPresenter) UploadFile(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	if header.Size > constants.MaxProductFileSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "File is too large")
+	}
+
+	src, err := header.Open()
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+	defer src.Close()
+
+	contentType := header.Header.Get(echo.HeaderContentType)
+	if contentType == "" {
+		contentType = echo.MIMEOctetStream
+	}
+
+	file, err := p.useCase.UploadFile(&dto.ProductWithIdDTO{ID: id}, header.Filename, contentType, src)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusCreated, "Product file uploaded", file)
+}
+
+// CreateDownloadLink godoc
+// @Summary      Create download link
+// @Description  Issue an expiring signed download link for a product file
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "product id"
+// @Param 		 fileId path int true "product file id"
+// @Success      201  {object}  response.SuccessResponse{data=dto.DownloadLinkDTO}
+// @Router       /products/{id}/files/{fileId}/download-link [post]
+func (p *ProductPresenter) CreateDownloadLink(c echo.Context) error {
+	productId, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	fileId, err := strconv.ParseInt(c.Param("fileId"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	link, err := p.useCase.CreateDownloadLink(&dto.ProductFileWithIdDTO{ProductId: productId, FileId: fileId})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusCreated, "Download link created", link)
+}
+
+// Download godoc
+// @Summary      Download product file
+// @Description  Download a product file through a signed link
+// @Tags         product
+// @Produce      octet-stream
+// @Param 		 fileId path int true "product file id"
+// @Param 		 expires query int true "link expiry as unix timestamp"
+// @Param 		 signature query string true "link signature"
+// @Success      200  {file}  file
+// @Router       /downloads/{fileId} [get]
+func (p *ProductPresenter) Download(c echo.Context) error {
+	fileId, err := strconv.ParseInt(c.Param("fileId"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	expires, err := strconv.ParseInt(c.QueryParam("expires"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	payload := &dto.DownloadDTO{
+		FileId:    fileId,
+		Expires:   expires,
+		Signature: c.QueryParam("signature"),
+	}
+
+	if err := c.Validate(payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	file, reader, err := p.useCase.OpenDownload(payload)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
 	}
+	defer reader.Close()
 
-	return c.JSON(http.StatusOK, HttpResponser.NewSuccessResponse("Product deleted", nil))
+	c.Response().Header().Set(echo.HeaderContentType, file.ContentType)
+	c.Response().Header().Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": file.FileName}))
+	http.ServeContent(c.Response(), c.Request(), file.FileName, time.Time{}, reader)
+	return nil
 }
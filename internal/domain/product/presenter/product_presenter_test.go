@@ -0,0 +1,57 @@
+package presenter
+
+import (
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/cursor"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBindCursor(t *testing.T) {
+	next := (&cursor.Cursor{ID: 3, Sort: "-created_at"}).Encode()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantLimit int64
+		wantCount bool
+		wantNext  bool
+		wantErr   error
+	}{
+		{name: "default limit", query: "cursor=" + next, wantLimit: 10, wantNext: true},
+		{name: "limit and count", query: "limit=25&count=true", wantLimit: 25, wantCount: true},
+		{name: "zero limit", query: "limit=0", wantErr: cursor.ErrInvalidLimit},
+		{name: "limit over the maximum", query: "limit=101", wantErr: cursor.ErrInvalidLimit},
+		{name: "bad cursor", query: "cursor=abc", wantErr: cursor.ErrInvalid},
+	}
+
+	e := echo.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/products?"+tt.query, nil)
+			c := e.NewContext(request, httptest.NewRecorder())
+
+			params := &dto.ProductPaginationDTO{}
+			err := bindCursor(c, params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("bindCursor() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if params.Limit != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", params.Limit, tt.wantLimit)
+			}
+			if params.WithCount != tt.wantCount {
+				t.Errorf("WithCount = %v, want %v", params.WithCount, tt.wantCount)
+			}
+			if (params.Cursor != nil) != tt.wantNext {
+				t.Errorf("Cursor = %v, want set %v", params.Cursor, tt.wantNext)
+			}
+		})
+	}
+}
@@ -3,20 +3,48 @@ package repository
 import (
 	"context"
 	"ecommerce/config"
+	BrandRepository "ecommerce/internal/domain/brand/repository"
 	"ecommerce/internal/domain/product/dto"
 	"ecommerce/internal/domain/product/entity"
-	"fmt"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/filter"
+	"ecommerce/pkg/fulltext"
+	"ecommerce/pkg/ordering"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log/slog"
+	"slices"
+	"sort"
 )
 
+const uniqueViolationCode = "23505"
+
+var ErrBarcodeTaken = errors.New("product barcode already exists")
+
+var productIdColumn = clause.Column{Table: "products", Name: "id"}
+
 //go:generate mockgen -source=product_repository.go -destination=mocks/product_repository_mock.go -package=mocks
 type IProductRepository interface {
-	Count() (int, error)
+	Count(params *dto.ProductPaginationDTO) (int, error)
 	FindAll(params *dto.ProductPaginationDTO) ([]*entity.Product, error)
+	FindPage(params *dto.ProductPaginationDTO) ([]*entity.Product, bool, error)
+	Export(params *dto.ProductPaginationDTO, fn func(row *dto.ExportProductDTO) error) error
+	Facets(params *dto.ProductFilterDTO, priceBuckets int) (*dto.ProductFacetsDTO, error)
 	FindById(id int) (*entity.Product, error)
+	FindByIdWithFields(id int, selection *fields.Selection) (*entity.Product, error)
+	FindByIds(ids []uint, selection *fields.Selection) ([]*entity.Product, error)
+	FindByBarcode(barcode string, selection *fields.Selection) (*entity.Product, error)
+	FindFileById(id uint) (*entity.ProductFile, error)
+	CreateFile(file *entity.ProductFile) error
 	Create(product *entity.Product) error
 	Update(product *entity.Product) error
 	Delete(product *entity.Product) error
+	FindExistingIds(ids []uint) ([]uint, error)
+	WithTransaction(fn func(repository IProductRepository) error) error
+	BrandRepository() BrandRepository.IBrandRepository
 }
 
 type ProductRepository struct {
@@ -37,9 +65,10 @@ func NewProductRepository(
 	}
 }
 
-func (p *ProductRepository) Count() (int, error) {
+func (p *ProductRepository) Count(params *dto.ProductPaginationDTO) (int, error) {
 	var count int64
-	if err := p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}).Count(&count).Error; err != nil {
+	qw := p.filter(p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}), &params.ProductFilterDTO)
+	if err := qw.Count(&count).Error; err != nil {
 		return 0, err
 	}
 
@@ -48,11 +77,17 @@ func (p *ProductRepository) Count() (int, error) {
 
 func (p *ProductRepository) FindAll(params *dto.ProductPaginationDTO) ([]*entity.Product, error) {
 	products := make([]*entity.Product, 0)
-	qw := p.dbProvider.WithContext(p.ctx).
-		Model(&products).
+	qw := selectFields(p.filter(p.dbProvider.WithContext(p.ctx).Model(&products), &params.ProductFilterDTO), params.Selection).
 		Limit(int(params.PerPage)).
-		Offset(int(params.PerPage * (params.Page - 1))).
-		Order(fmt.Sprintf("%s %s", params.SortBy, params.Sort))
+		Offset(int(params.PerPage * (params.Page - 1)))
+
+	// collection products keep their manual position ahead of the sort keys
+	keys := params.OrderBy
+	if params.CollectionId != 0 {
+		keys = append([]ordering.Key{{Name: "position", Column: clause.Column{Table: "collection_products", Name: "position"}}}, keys...)
+	}
+
+	qw = qw.Order(ordering.OrderBy(keys, productIdColumn, computedKeys(params.Search)))
 
 	if err := qw.Find(&products).Error; err != nil {
 		return make([]*entity.Product, 0), err
@@ -61,38 +96,420 @@ func (p *ProductRepository) FindAll(params *dto.ProductPaginationDTO) ([]*entity
 	return products, nil
 }
 
+// FindPage returns up to params.Limit products following params.Cursor in
+// sort order, or preceding it for a backward cursor, and whether more
+// products lie beyond the page in that direction. Collection positions are
+// not supported.
+func (p *ProductRepository) FindPage(params *dto.ProductPaginationDTO) ([]*entity.Product, bool, error) {
+	products := make([]*entity.Product, 0)
+	computed := computedKeys(params.Search)
+
+	keys := ordering.WithTieBreaker(params.OrderBy, "id", productIdColumn)
+	backward := params.Cursor != nil && params.Cursor.Backward
+	if backward {
+		keys = ordering.Reverse(keys)
+	}
+
+	qw := selectFields(p.filter(p.dbProvider.WithContext(p.ctx).Model(&products), &params.ProductFilterDTO), params.Selection).
+		Order(ordering.OrderBy(keys, productIdColumn, computed)).
+		Limit(int(params.Limit) + 1)
+
+	if params.Cursor != nil {
+		qw = qw.Where(ordering.Keyset(keys, computed, "products", params.Cursor.ID))
+	}
+
+	if err := qw.Find(&products).Error; err != nil {
+		return make([]*entity.Product, 0), false, err
+	}
+
+	more := len(products) > int(params.Limit)
+	if more {
+		products = products[:params.Limit]
+	}
+
+	if backward {
+		slices.Reverse(products)
+	}
+
+	return products, more, nil
+}
+
+// Export streams the filtered products in sort order together with their
+// brand name and tags. Rows are read from the database cursor one at a time
+// and handed to fn, which stops the export by returning an error.
+func (p *ProductRepository) Export(params *dto.ProductPaginationDTO, fn func(row *dto.ExportProductDTO) error) error {
+	keys := ordering.WithTieBreaker(params.OrderBy, "id", productIdColumn)
+	qw := p.filter(p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}), &params.ProductFilterDTO).
+		Select(`products.id, products.name, products.description, products.type, products.price,
+			products.qty, products.brand_id, brands.name AS brand_name, products.barcode,
+			(SELECT string_agg(tag, ',' ORDER BY tag) FROM product_tags WHERE product_tags.product_id = products.id) AS tags,
+			products.tax_class_id, products.weight_grams, products.created_at, products.updated_at`).
+		Joins("LEFT JOIN brands ON brands.id = products.brand_id").
+		Order(ordering.OrderBy(keys, productIdColumn, computedKeys(params.Search)))
+
+	rows, err := qw.Rows()
+	if err != nil {
+		p.logger.Error(err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := &dto.ExportProductDTO{}
+		if err := qw.ScanRows(rows, row); err != nil {
+			p.logger.Error(err.Error())
+			return err
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// selectFields narrows a product query to the columns and relations of the
+// selection; a nil selection loads everything.
+func selectFields(qw *gorm.DB, selection *fields.Selection) *gorm.DB {
+	if columns := selection.Columns("products"); columns != nil {
+		// the version is always loaded for the ETag
+		qw = qw.Select(append(columns, "products.version"))
+	}
+
+	if selection.Include("tags") {
+		qw = qw.Preload("Tags")
+	}
+
+	if selection.Include("files") {
+		qw = qw.Preload("Files")
+	}
+	return qw
+}
+
+// computedKeys supplies the relevance sort key while searching.
+func computedKeys(search string) map[string]clause.Expr {
+	computed := make(map[string]clause.Expr)
+	if query := fulltext.PrefixQuery(search); query != "" {
+		computed["relevance"] = clause.Expr{
+			SQL:  "ts_rank(products.search_vector, to_tsquery(?, ?))",
+			Vars: []interface{}{fulltext.Config, query},
+		}
+	}
+	return computed
+}
+
+// Facets counts the filtered products per brand, per price bucket and per
+// availability in a single grouping sets query. The price range of the filtered
+// products is split into priceBuckets equal-width buckets.
+func (p *ProductRepository) Facets(params *dto.ProductFilterDTO, priceBuckets int) (*dto.ProductFacetsDTO, error) {
+	filtered := p.filter(p.dbProvider.Model(&entity.Product{}), params).
+		Joins("JOIN brands ON brands.id = products.brand_id").
+		Select("products.brand_id, brands.name AS brand_name, products.price, (products.qty > 0 OR products.type <> ?) AS in_stock", entity.ProductTypePhysical)
+
+	rows := make([]struct {
+		Grouping  int
+		BrandId   int64
+		BrandName string
+		Bucket    int
+		InStock   bool
+		Total     int64
+		MinPrice  int
+		MaxPrice  int
+	}, 0)
+
+	err := p.dbProvider.WithContext(p.ctx).Raw(`
+		WITH filtered AS (?),
+		     bounds AS (SELECT min(price) AS min_price, max(price) AS max_price FROM filtered),
+		     bucketed AS (
+		         SELECT f.brand_id, f.brand_name, f.in_stock,
+		                ((f.price - b.min_price)::bigint * ? / (b.max_price - b.min_price + 1))::int AS bucket
+		         FROM filtered f CROSS JOIN bounds b
+		     )
+		SELECT GROUPING(brand_id, bucket, in_stock) AS grouping,
+		       brand_id, min(brand_name) AS brand_name, bucket, in_stock, count(*) AS total,
+		       coalesce((SELECT min_price FROM bounds), 0) AS min_price,
+		       coalesce((SELECT max_price FROM bounds), 0) AS max_price
+		FROM bucketed
+		GROUP BY GROUPING SETS ((brand_id), (bucket), (in_stock), ())`,
+		filtered, priceBuckets,
+	).Scan(&rows).Error
+	if err != nil {
+		p.logger.Error(err.Error())
+		return nil, err
+	}
+
+	facets := &dto.ProductFacetsDTO{
+		Brands:       make([]*dto.BrandFacetDTO, 0),
+		PriceRanges:  make([]*dto.PriceRangeFacetDTO, 0),
+		Availability: &dto.AvailabilityFacetDTO{},
+	}
+
+	bucketCounts := make(map[int]int64)
+	for _, row := range rows {
+		// GROUPING sets a bit for every column the row is not grouped by:
+		// brand_id (4), bucket (2), in_stock (1)
+		switch row.Grouping {
+		case 0b011:
+			facets.Brands = append(facets.Brands, &dto.BrandFacetDTO{ID: row.BrandId, Name: row.BrandName, Count: row.Total})
+		case 0b101:
+			bucketCounts[row.Bucket] = row.Total
+		case 0b110:
+			if row.InStock {
+				facets.Availability.InStock = row.Total
+			} else {
+				facets.Availability.OutOfStock = row.Total
+			}
+		case 0b111:
+			facets.Total = row.Total
+		}
+	}
+
+	sort.Slice(facets.Brands, func(i, j int) bool {
+		if facets.Brands[i].Count != facets.Brands[j].Count {
+			return facets.Brands[i].Count > facets.Brands[j].Count
+		}
+		return facets.Brands[i].Name < facets.Brands[j].Name
+	})
+
+	if facets.Total > 0 {
+		facets.PriceRanges = priceRanges(rows[0].MinPrice, rows[0].MaxPrice, priceBuckets, bucketCounts)
+	}
+
+	return facets, nil
+}
+
+// priceRanges turns the per-bucket counts of the facets query back into the
+// price ranges of its equal-width buckets, leaving out buckets too narrow to
+// hold a whole price.
+func priceRanges(minPrice, maxPrice, priceBuckets int, bucketCounts map[int]int64) []*dto.PriceRangeFacetDTO {
+	ranges := make([]*dto.PriceRangeFacetDTO, 0, priceBuckets)
+	width := maxPrice - minPrice + 1
+	for i := 0; i < priceBuckets; i++ {
+		// bucket i holds the prices where (price - min) * n / width == i
+		from := minPrice + ceilDiv(i*width, priceBuckets)
+		to := minPrice + ceilDiv((i+1)*width, priceBuckets) - 1
+		if from > to {
+			continue
+		}
+		ranges = append(ranges, &dto.PriceRangeFacetDTO{Min: from, Max: to, Count: bucketCounts[i]})
+	}
+	return ranges
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// filter narrows a product query to the filters carried by the pagination
+// params so that FindAll and Count always agree.
+func (p *ProductRepository) filter(qw *gorm.DB, params *dto.ProductFilterDTO) *gorm.DB {
+	if query := fulltext.PrefixQuery(params.Search); query != "" {
+		qw = qw.Where("products.search_vector @@ to_tsquery(?, ?)", fulltext.Config, query)
+	}
+
+	if params.CollectionId != 0 {
+		qw = qw.Joins("JOIN collection_products ON collection_products.product_id = products.id AND collection_products.collection_id = ?", params.CollectionId)
+	}
+
+	if len(params.BrandIds) > 0 {
+		qw = qw.Where("products.brand_id IN ?", params.BrandIds)
+	}
+
+	if params.MinPrice != nil {
+		qw = qw.Where("products.price >= ?", *params.MinPrice)
+	}
+
+	if params.MaxPrice != nil {
+		qw = qw.Where("products.price <= ?", *params.MaxPrice)
+	}
+
+	// only physical products carry stock; digital products and services are
+	// always available
+	if params.InStock != nil {
+		if *params.InStock {
+			qw = qw.Where("(products.qty > 0 OR products.type <> ?)", entity.ProductTypePhysical)
+		} else {
+			qw = qw.Where("products.qty <= 0 AND products.type = ?", entity.ProductTypePhysical)
+		}
+	}
+
+	if len(params.Tags) > 0 {
+		tagged := p.dbProvider.Model(&entity.ProductTag{}).
+			Select("product_id").
+			Where("tag IN ?", params.Tags).
+			Group("product_id")
+		if params.TagMatch == "all" {
+			tagged = tagged.Having("count(DISTINCT tag) = ?", len(params.Tags))
+		}
+		qw = qw.Where("products.id IN (?)", tagged)
+	}
+
+	return filter.Apply(qw, params.Filters)
+}
+
 func (p *ProductRepository) FindById(id int) (*entity.Product, error) {
+	return p.FindByIdWithFields(id, nil)
+}
+
+func (p *ProductRepository) FindByIdWithFields(id int, selection *fields.Selection) (*entity.Product, error) {
 	product := &entity.Product{}
-	if err := p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}).Where("id = ?", id).First(product).Error; err != nil {
+	qw := selectFields(p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}), selection)
+	if err := qw.Where("products.id = ?", id).First(product).Error; err != nil {
 		return nil, err
 	}
 
 	return product, nil
 }
 
-func (p *ProductRepository) Create(product *entity.Product) error {
-	tx := p.dbProvider.WithContext(p.ctx).Begin()
-	if err := tx.Create(product).Error; err != nil {
-		tx.Rollback()
-		return err
+// FindByIds loads the products with the given ids in no particular order;
+// ids without a product are left out.
+func (p *ProductRepository) FindByIds(ids []uint, selection *fields.Selection) ([]*entity.Product, error) {
+	products := make([]*entity.Product, 0, len(ids))
+	if len(ids) == 0 {
+		return products, nil
 	}
-	return tx.Commit().Error
+
+	qw := selectFields(p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}), selection)
+	if err := qw.Where("products.id IN ?", ids).Find(&products).Error; err != nil {
+		p.logger.Error(err.Error())
+		return make([]*entity.Product, 0), err
+	}
+
+	return products, nil
 }
 
-func (p *ProductRepository) Update(product *entity.Product) error {
+// FindByBarcode matches barcodes on their zero-padded GTIN-14 form, the same
+// expression the products_barcode_unique index is built on; barcode must be
+// in that form already.
+func (p *ProductRepository) FindByBarcode(barcode string, selection *fields.Selection) (*entity.Product, error) {
+	product := &entity.Product{}
+	if err := selectFields(p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}), selection).
+		Where("barcode IS NOT NULL AND lpad(barcode, 14, '0') = ?", barcode).
+		First(product).Error; err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+func (p *ProductRepository) FindFileById(id uint) (*entity.ProductFile, error) {
+	file := &entity.ProductFile{}
+	if err := p.dbProvider.WithContext(p.ctx).Where("id = ?", id).First(file).Error; err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (p *ProductRepository) CreateFile(file *entity.ProductFile) error {
 	tx := p.dbProvider.WithContext(p.ctx).Begin()
-	if err := tx.Save(product).Where("id = ?", product.ID).Error; err != nil {
+	if err := tx.Create(file).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 	return tx.Commit().Error
 }
 
+func (p *ProductRepository) Create(product *entity.Product) error {
+	return p.dbProvider.WithContext(p.ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(product).Error; err != nil {
+			return translateError(err)
+		}
+		return nil
+	})
+}
+
+// Update writes the product only when its stored version is still
+// product.Version, the version the update is based on, and bumps the version.
+// It returns etag.ErrPreconditionFailed when another write came first.
+func (p *ProductRepository) Update(product *entity.Product) error {
+	return p.dbProvider.WithContext(p.ctx).Transaction(func(tx *gorm.DB) error {
+		version := product.Version
+		product.Version++
+
+		qw := tx.Model(product).
+			Where("version = ?", version).
+			Select("*").
+			Omit("ID", "CreatedAt", "DeletedAt", "Tags", "Files").
+			Updates(product)
+		if qw.Error != nil {
+			product.Version = version
+			return translateError(qw.Error)
+		}
+
+		if qw.RowsAffected == 0 {
+			product.Version = version
+			return etag.ErrPreconditionFailed
+		}
+
+		// nil Tags keeps the stored tags, an empty slice clears them
+		if product.Tags != nil {
+			if err := tx.Where("product_id = ?", product.ID).Delete(&entity.ProductTag{}).Error; err != nil {
+				return err
+			}
+
+			for i := range product.Tags {
+				product.Tags[i].ProductId = product.ID
+			}
+
+			if len(product.Tags) > 0 {
+				if err := tx.Create(&product.Tags).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Delete deletes the product only when its stored version is still
+// product.Version and returns etag.ErrPreconditionFailed otherwise.
 func (p *ProductRepository) Delete(product *entity.Product) error {
-	tx := p.dbProvider.WithContext(p.ctx).Begin()
-	if err := tx.Delete(product).Where("id = ?", product.ID).Error; err != nil {
-		tx.Rollback()
-		return err
+	return p.dbProvider.WithContext(p.ctx).Transaction(func(tx *gorm.DB) error {
+		qw := tx.Where("version = ?", product.Version).Delete(product)
+		if qw.Error != nil {
+			return qw.Error
+		}
+
+		if qw.RowsAffected == 0 {
+			return etag.ErrPreconditionFailed
+		}
+		return nil
+	})
+}
+
+// FindExistingIds returns the ids out of ids that belong to a record.
+func (p *ProductRepository) FindExistingIds(ids []uint) ([]uint, error) {
+	existing := make([]uint, 0, len(ids))
+	if len(ids) == 0 {
+		return existing, nil
 	}
-	return tx.Commit().Error
+
+	err := p.dbProvider.WithContext(p.ctx).Model(&entity.Product{}).Where("id IN ?", ids).Pluck("id", &existing).Error
+	return existing, err
+}
+
+// WithTransaction runs fn against a repository bound to a single transaction
+// that is committed when fn returns nil and rolled back otherwise.
+// Create, Update and Delete use Transaction rather than Begin so that they
+// nest as savepoints inside it.
+func (p *ProductRepository) WithTransaction(fn func(repository IProductRepository) error) error {
+	return p.dbProvider.WithContext(p.ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewProductRepository(p.ctx, &config.DatabaseConfiguration{DB: tx}, p.logger))
+	})
+}
+
+// BrandRepository returns a brand repository on the same connection, bound to
+// the transaction inside WithTransaction.
+func (p *ProductRepository) BrandRepository() BrandRepository.IBrandRepository {
+	return BrandRepository.NewBrandRepository(p.ctx, p.dbProvider, p.logger)
+}
+
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode && pgErr.ConstraintName == "products_barcode_unique" {
+		return ErrBarcodeTaken
+	}
+	return err
 }
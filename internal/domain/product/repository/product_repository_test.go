@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"ecommerce/internal/domain/product/dto"
+	"reflect"
+	"testing"
+)
+
+func TestPriceRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max int
+		buckets  int
+		counts   map[int]int64
+		want     []*dto.PriceRangeFacetDTO
+	}{
+		{
+			name: "even split", min: 0, max: 99, buckets: 4,
+			counts: map[int]int64{0: 3, 2: 1},
+			want: []*dto.PriceRangeFacetDTO{
+				{Min: 0, Max: 24, Count: 3},
+				{Min: 25, Max: 49},
+				{Min: 50, Max: 74, Count: 1},
+				{Min: 75, Max: 99},
+			},
+		},
+		{
+			name: "uneven split", min: 100, max: 109, buckets: 3,
+			counts: map[int]int64{1: 2},
+			want: []*dto.PriceRangeFacetDTO{
+				{Min: 100, Max: 103},
+				{Min: 104, Max: 106, Count: 2},
+				{Min: 107, Max: 109},
+			},
+		},
+		{
+			name: "fewer prices than buckets", min: 5, max: 6, buckets: 5,
+			counts: map[int]int64{0: 1, 2: 4},
+			want: []*dto.PriceRangeFacetDTO{
+				{Min: 5, Max: 5, Count: 1},
+				{Min: 6, Max: 6, Count: 4},
+			},
+		},
+		{
+			name: "single price", min: 990, max: 990, buckets: 3,
+			counts: map[int]int64{0: 7},
+			want:   []*dto.PriceRangeFacetDTO{{Min: 990, Max: 990, Count: 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := priceRanges(tt.min, tt.max, tt.buckets, tt.counts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("priceRanges() = %+v, want %+v", describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+// TestPriceRangesMatchBuckets checks that every price lands in the range of
+// the bucket the facets query puts it in.
+func TestPriceRangesMatchBuckets(t *testing.T) {
+	for _, n := range []int{1, 3, 7, 10} {
+		minPrice, maxPrice := 1999, 2311
+		ranges := priceRanges(minPrice, maxPrice, n, nil)
+		if ranges[0].Min != minPrice || ranges[len(ranges)-1].Max != maxPrice {
+			t.Fatalf("%d buckets span %d-%d, want %d-%d", n, ranges[0].Min, ranges[len(ranges)-1].Max, minPrice, maxPrice)
+		}
+
+		for price := minPrice; price <= maxPrice; price++ {
+			// the bucket expression of the facets query
+			bucket := (price - minPrice) * n / (maxPrice - minPrice + 1)
+			if r := ranges[bucket]; price < r.Min || price > r.Max {
+				t.Fatalf("%d buckets: price %d is in bucket %d, whose range is %d-%d", n, price, bucket, r.Min, r.Max)
+			}
+		}
+	}
+}
+
+func describe(ranges []*dto.PriceRangeFacetDTO) []dto.PriceRangeFacetDTO {
+	values := make([]dto.PriceRangeFacetDTO, 0, len(ranges))
+	for _, r := range ranges {
+		values = append(values, *r)
+	}
+	return values
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"ecommerce/internal/domain/product/dto"
+	ProductRepository "ecommerce/internal/domain/product/repository"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/searchindex"
+	"strconv"
+)
+
+// BulkWrite applies the operations in order and records each outcome in the
+// report. Atomic mode runs them in one transaction that is rolled back by the
+// first failure, and does not start when validation already rejected an
+// operation; the search index is only written once the transaction commits.
+// Best effort mode applies every operation that succeeds. Once the Context of
+// the payload is done the write stops, an atomic one is rolled back, and the
+// error of the Context is returned.
+func (p *ProductUseCase) BulkWrite(payload *dto.BulkProductDTO, report *bulk.Report) error {
+	return p.bulkWrite(payload, report, nil)
+}
+
+// bulkWrite is BulkWrite running prepare first, in the transaction of atomic
+// mode; an error of prepare stops the write and is returned.
+func (p *ProductUseCase) bulkWrite(payload *dto.BulkProductDTO, report *bulk.Report, prepare func(tx *ProductUseCase) error) error {
+	if payload.Mode == bulk.ModeBestEffort {
+		if prepare != nil {
+			if err := prepare(p); err != nil {
+				return err
+			}
+		}
+
+		for i, op := range payload.Operations {
+			if err := stopped(payload.Context); err != nil {
+				return err
+			}
+
+			id, err := p.applyBulk(op)
+			report.Record(op.Index, int64(id), err)
+			if payload.Progress != nil {
+				payload.Progress(i+1, len(payload.Operations))
+			}
+		}
+		return nil
+	}
+
+	if report.Failed > 0 {
+		return nil
+	}
+
+	// errors that abort the write rather than fail an operation
+	var abortErr error
+	err := p.productRepository.WithTransaction(func(repository ProductRepository.IProductRepository) error {
+		tx := *p
+		tx.productRepository = repository
+		tx.brandRepository = repository.BrandRepository()
+		tx.index = searchindex.NopIndex{}
+
+		if prepare != nil {
+			if abortErr = prepare(&tx); abortErr != nil {
+				return abortErr
+			}
+		}
+
+		for i, op := range payload.Operations {
+			if abortErr = stopped(payload.Context); abortErr != nil {
+				return abortErr
+			}
+
+			id, err := tx.applyBulk(op)
+			report.Record(op.Index, int64(id), err)
+			if err != nil {
+				return err
+			}
+			if payload.Progress != nil {
+				payload.Progress(i+1, len(payload.Operations))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		report.RollBack()
+		return abortErr
+	}
+
+	for _, result := range report.Results {
+		if result.Op == bulk.OpDelete {
+			_ = p.index.Delete(SearchDocumentType, strconv.FormatInt(*result.ID, 10))
+		} else {
+			p.indexProduct(uint(*result.ID))
+		}
+	}
+	return nil
+}
+
+// stopped returns the error of ctx once it is done; a nil ctx never is.
+func stopped(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// applyBulk runs one operation and returns the id of the product it wrote.
+func (p *ProductUseCase) applyBulk(op *dto.BulkProductOperationDTO) (uint, error) {
+	switch op.Op {
+	case bulk.OpCreate:
+		return p.createProduct(op.Create)
+	case bulk.OpUpdate:
+		op.Update.ID = op.ID
+		return uint(op.ID), p.PatchProduct(op.Update)
+	default:
+		return uint(op.ID), p.DeleteProduct(&dto.ProductWithIdDTO{ID: op.ID})
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import "ecommerce/pkg/apperror"
+
+var (
+	ErrProductNotFound = apperror.NotFound("product_not_found", "product not found")
+	// ErrInvalidProduct carries the field errors of a product that cannot be
+	// written, such as a patched document that is no longer valid.
+	ErrInvalidProduct = apperror.Validation("invalid_product", "invalid product")
+	// ErrBarcodeTaken carries the existing_id of the product that already
+	// owns the barcode.
+	ErrBarcodeTaken = apperror.Conflict("barcode_taken", "product barcode already exists")
+	// ErrInvalidImport rejects an import file as a whole, such as one with an
+	// unknown header mapping or without a name column.
+	ErrInvalidImport = apperror.Validation("invalid_import", "invalid import")
+
+	ErrStorageNotConfigured = apperror.Unavailable("storage_not_configured", "file storage is not configured")
+	ErrProductNotDigital    = apperror.Validation("product_not_digital", "files can only be attached to digital products")
+	ErrProductFileNotFound  = apperror.NotFound("product_file_not_found", "product file not found")
+)
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/export"
+)
+
+// Export writes every product matching the filters of params to writer in
+// the list sort order and returns how many were written. The header is only
+// written once the filters are resolved, so a failure before any output
+// leaves the writer untouched; the caller closes the writer.
+func (p *ProductUseCase) Export(params *dto.ProductPaginationDTO, writer export.Writer) (int, error) {
+	defaultOrder(params)
+
+	if err := p.prepareFilter(&params.ProductFilterDTO); err != nil {
+		return 0, err
+	}
+	return p.export(params, writer)
+}
+
+// export writes the products of params with resolved filters.
+func (p *ProductUseCase) export(params *dto.ProductPaginationDTO, writer export.Writer) (int, error) {
+	if err := writer.WriteHeader(dto.ProductExportColumns); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	err := p.productRepository.Export(params, func(row *dto.ExportProductDTO) error {
+		total++
+		return writer.Write(row.Values())
+	})
+	return total, err
+}
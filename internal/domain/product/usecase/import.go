@@ -0,0 +1,448 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	BrandDto "ecommerce/internal/domain/brand/dto"
+	BrandUseCase "ecommerce/internal/domain/brand/usecase"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/bulk"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// importRow is a parsed CSV row. brand holds a brand name that is still to be
+// resolved to the brand id of the operation.
+type importRow struct {
+	line int
+	op   *dto.BulkProductOperationDTO
+	// update holds the columns of an update row, written as a merge patch
+	update *dto.UpdateProductDTO
+	// given holds the fields the row has a value for; an update leaves the
+	// others as they are stored
+	given  map[string]bool
+	brand  string
+	errors map[string]string
+}
+
+// Import reads a CSV product import and applies its rows in file order like a
+// bulk request. Every row is validated first and a dry run stops there,
+// reporting the rows that would be written. Brands are matched by name and,
+// with CreateBrands, created when missing; an atomic import creates them in
+// its transaction, so they are rolled back with the rows.
+func (p *ProductUseCase) Import(payload *dto.ImportProductsDTO) (*dto.ImportReportDTO, error) {
+	rows, err := readImport(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &bulk.Request{Mode: payload.Mode}
+	for _, row := range rows {
+		request.Operations = append(request.Operations, &bulk.Operation{Op: row.op.Op, ID: row.op.ID})
+	}
+
+	report := &dto.ImportReportDTO{
+		Report:    bulk.NewReport(request),
+		DryRun:    payload.DryRun,
+		NewBrands: make([]string, 0),
+	}
+
+	brands := make(map[string]int64)
+	for i, row := range rows {
+		if err := stopped(payload.Context); err != nil {
+			return nil, err
+		}
+
+		row.op.Index = i
+		report.Results[i].Line = row.line
+		if len(row.errors) == 0 {
+			p.validateImportRow(row, brands, payload.CreateBrands)
+		}
+
+		if payload.DryRun && payload.Progress != nil {
+			payload.Progress(i+1, len(rows))
+		}
+
+		if len(row.errors) > 0 {
+			report.Reject(i, row.errors)
+			continue
+		}
+
+		key := brandKey(row.brand)
+		if key != "" && brands[key] == 0 && !slices.ContainsFunc(report.NewBrands, func(name string) bool { return brandKey(name) == key }) {
+			report.NewBrands = append(report.NewBrands, row.brand)
+		}
+	}
+
+	if payload.DryRun {
+		for _, result := range report.Results {
+			if result.Status == bulk.StatusSkipped {
+				result.Status = bulk.StatusValid
+				report.Valid++
+			}
+		}
+		return report, nil
+	}
+
+	if payload.Mode != bulk.ModeBestEffort && report.Failed > 0 {
+		report.NewBrands = make([]string, 0)
+		return report, nil
+	}
+
+	bulkPayload := &dto.BulkProductDTO{Mode: payload.Mode, Context: payload.Context, Progress: payload.Progress}
+	for i, row := range rows {
+		if report.Results[i].Status != bulk.StatusFailed {
+			bulkPayload.Operations = append(bulkPayload.Operations, row.op)
+		}
+	}
+
+	// the brands are created and the rows completed with their ids once the
+	// write has started, inside the transaction of an atomic import
+	prepare := func(tx *ProductUseCase) error {
+		brandUseCase := BrandUseCase.NewBrandUseCase(tx.brandRepository, tx.index, nil)
+		for _, name := range report.NewBrands {
+			if err := brandUseCase.CreateBrand(&BrandDto.CreateBrandDTO{Name: name}); err != nil {
+				return err
+			}
+
+			brand, err := tx.brandRepository.FindByName(name)
+			if err != nil {
+				return err
+			}
+			brands[brandKey(name)] = int64(brand.ID)
+		}
+
+		for i, row := range rows {
+			if report.Results[i].Status == bulk.StatusFailed {
+				continue
+			}
+
+			if row.brand != "" {
+				setBrand(row, brands[brandKey(row.brand)])
+			}
+
+			if row.update != nil {
+				patch, err := importPatch(row)
+				if err != nil {
+					return err
+				}
+				row.op.Update.Patch = patch
+			}
+		}
+		return nil
+	}
+
+	if err := p.bulkWrite(bulkPayload, report.Report, prepare); err != nil {
+		return nil, err
+	}
+
+	// the transaction of an atomic import wrote no index documents
+	if payload.Mode != bulk.ModeBestEffort {
+		brandUseCase := BrandUseCase.NewBrandUseCase(p.brandRepository, p.index, nil)
+		for _, name := range report.NewBrands {
+			if brand, err := p.brandRepository.FindByName(name); err == nil {
+				brandUseCase.IndexBrand(brand.ID)
+			}
+		}
+	}
+	return report, nil
+}
+
+// validateImportRow resolves the brand of a row and records its validation
+// errors. Update rows keep the stored values of the columns they leave empty.
+func (p *ProductUseCase) validateImportRow(row *importRow, brands map[string]int64, createBrands bool) {
+	if row.brand != "" {
+		key := brandKey(row.brand)
+		if _, ok := brands[key]; !ok {
+			brands[key] = 0
+			if brand, err := p.brandRepository.FindByName(row.brand); err == nil {
+				brands[key] = int64(brand.ID)
+			}
+		}
+
+		switch {
+		case brands[key] != 0:
+			setBrand(row, brands[key])
+		case createBrands:
+			// a placeholder passing validation until the brand is created
+			setBrand(row, -1)
+		default:
+			row.errors["brand"] = fmt.Sprintf("Brand %q not found", row.brand)
+			return
+		}
+	}
+
+	var payload interface{} = row.op.Create
+	if row.op.Op == bulk.OpUpdate {
+		existing, err := p.productRepository.FindById(int(row.op.ID))
+		if err != nil {
+			row.errors["id"] = fmt.Sprintf("Product %d not found", row.op.ID)
+			return
+		}
+
+		// validated with the stored values of the columns the row leaves out
+		update := *row.update
+		stored := toProductDocument(existing)
+		if !row.given["name"] {
+			update.Name = stored.Name
+		}
+		if !row.given["type"] {
+			update.Type = stored.Type
+		}
+		if !row.given["price"] {
+			update.Price = stored.Price
+		}
+		if !row.given["qty"] {
+			update.Qty = stored.Qty
+		}
+		// a brand still to be created is checked once it exists
+		if (!row.given["brand_id"] && row.brand == "") || update.BrandId < 0 {
+			update.BrandId = stored.BrandId
+		}
+		payload = &update
+	}
+
+	for field, message := range p.validator.Fields(payload) {
+		row.errors[field] = message
+	}
+}
+
+func brandKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func setBrand(row *importRow, brandId int64) {
+	if row.op.Create != nil {
+		row.op.Create.BrandId = brandId
+	} else {
+		row.update.BrandId = brandId
+	}
+}
+
+// importPatch renders an update row as a merge patch of the columns it has a
+// value for, so a 0 price or qty is written while an empty cell is not.
+func importPatch(row *importRow) (json.RawMessage, error) {
+	update := row.update
+	patch := make(map[string]interface{})
+	if row.given["name"] {
+		patch["name"] = update.Name
+	}
+	if row.given["type"] {
+		patch["type"] = update.Type
+	}
+	if row.given["price"] {
+		patch["price"] = update.Price
+	}
+	if row.given["qty"] {
+		patch["qty"] = update.Qty
+	}
+	if row.given["brand_id"] || row.brand != "" {
+		patch["brand_id"] = update.BrandId
+	}
+
+	if update.Description != nil {
+		patch["description"] = *update.Description
+	}
+	if update.Barcode != nil {
+		patch["barcode"] = *update.Barcode
+	}
+	if update.Tags != nil {
+		patch["tags"] = update.Tags
+	}
+	if update.TaxClassId != nil {
+		patch["tax_class_id"] = *update.TaxClassId
+	}
+	if update.Weight != nil {
+		patch["weight"] = update.Weight
+	}
+	return json.Marshal(patch)
+}
+
+// readImport parses the CSV file into rows; the file is rejected as a whole
+// with ErrInvalidImport when it cannot be read or mapped.
+func readImport(payload *dto.ImportProductsDTO) ([]*importRow, error) {
+	reader := csv.NewReader(payload.File)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImport, err)
+	}
+
+	columns, err := importColumns(header, payload.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*importRow, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidImport, err)
+		}
+
+		if len(rows) == constants.MaxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, constants.MaxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseImportRow(columns, record, line))
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file has no rows", ErrInvalidImport)
+	}
+	return rows, nil
+}
+
+// importColumns maps each import field to its CSV column. Headers are mapped
+// through mapping first and otherwise match a field by name; other columns
+// are ignored.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	for column, field := range mapping {
+		if !slices.Contains(dto.ProductImportFields, field) {
+			return nil, fmt.Errorf("%w: column %q is mapped to unknown field %q", ErrInvalidImport, column, field)
+		}
+
+		if !slices.ContainsFunc(header, func(name string) bool { return strings.TrimSpace(name) == column }) {
+			return nil, fmt.Errorf("%w: mapped column %q is not in the header", ErrInvalidImport, column)
+		}
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		field, ok := mapping[name]
+		if !ok {
+			field = strings.ToLower(name)
+			if !slices.Contains(dto.ProductImportFields, field) {
+				continue
+			}
+		}
+
+		if _, ok := columns[field]; ok {
+			return nil, fmt.Errorf("%w: more than one column maps to %q", ErrInvalidImport, field)
+		}
+		columns[field] = i
+	}
+
+	_, hasId := columns["id"]
+	_, hasName := columns["name"]
+	if !hasId && !hasName {
+		return nil, fmt.Errorf("%w: a name or id column is required", ErrInvalidImport)
+	}
+	return columns, nil
+}
+
+// parseImportRow converts a CSV record into a create operation, or an update
+// when it carries an id. Values that do not parse are reported per field.
+func parseImportRow(columns map[string]int, record []string, line int) *importRow {
+	row := &importRow{line: line, given: make(map[string]bool), errors: make(map[string]string)}
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		raw := strings.TrimSpace(record[i])
+		row.given[field] = raw != ""
+		return raw
+	}
+
+	number := func(field string) int64 {
+		raw := value(field)
+		if raw == "" {
+			return 0
+		}
+
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			row.errors[field] = fmt.Sprintf("%s must be a whole number", strings.ToUpper(field[:1])+field[1:])
+		}
+		return n
+	}
+
+	optional := func(field string) *string {
+		if raw := value(field); raw != "" {
+			return &raw
+		}
+		return nil
+	}
+
+	create := &dto.CreateProductDTO{
+		Name:        value("name"),
+		Description: optional("description"),
+		Type:        value("type"),
+		Price:       int(number("price")),
+		Qty:         int(number("qty")),
+		BrandId:     number("brand_id"),
+		Barcode:     optional("barcode"),
+	}
+	row.brand = value("brand")
+
+	if tags := value("tags"); tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				create.Tags = append(create.Tags, tag)
+			}
+		}
+	}
+
+	if value("tax_class_id") != "" {
+		taxClassId := number("tax_class_id")
+		create.TaxClassId = &taxClassId
+	}
+
+	if raw := value("weight"); raw != "" {
+		weight, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			row.errors["weight"] = "Weight must be a number"
+		}
+
+		unit := value("weight_unit")
+		if unit == "" {
+			unit = "kg"
+		}
+		create.Weight = &dto.WeightDTO{Value: weight, Unit: unit}
+	}
+
+	id := number("id")
+	if id == 0 {
+		row.op = &dto.BulkProductOperationDTO{Op: bulk.OpCreate, Create: create}
+		return row
+	}
+
+	row.op = &dto.BulkProductOperationDTO{Op: bulk.OpUpdate, ID: id, Update: &dto.PatchProductDTO{
+		ID:     id,
+		Format: dto.PatchFormatMergePatch,
+	}}
+	row.update = &dto.UpdateProductDTO{
+		Name:        create.Name,
+		Description: create.Description,
+		Type:        create.Type,
+		Price:       create.Price,
+		Qty:         create.Qty,
+		BrandId:     create.BrandId,
+		Barcode:     create.Barcode,
+		Tags:        create.Tags,
+		TaxClassId:  create.TaxClassId,
+		Weight:      create.Weight,
+	}
+	return row
+}
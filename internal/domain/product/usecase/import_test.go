@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestImportPatch(t *testing.T) {
+	columns := map[string]int{"id": 0, "name": 1, "price": 2, "qty": 3, "brand_id": 4}
+	tests := []struct {
+		name   string
+		record []string
+		want   map[string]interface{}
+	}{
+		{
+			name:   "zero price and qty are written",
+			record: []string{"7", "", "0", "0", ""},
+			want:   map[string]interface{}{"price": float64(0), "qty": float64(0)},
+		},
+		{
+			name:   "empty cells keep the stored values",
+			record: []string{"7", "Mug", "", "", ""},
+			want:   map[string]interface{}{"name": "Mug"},
+		},
+		{
+			name:   "short record",
+			record: []string{"7", "", "1500"},
+			want:   map[string]interface{}{"price": float64(1500)},
+		},
+		{
+			name:   "every column",
+			record: []string{"7", "Mug", "1500", "3", "2"},
+			want:   map[string]interface{}{"name": "Mug", "price": float64(1500), "qty": float64(3), "brand_id": float64(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := parseImportRow(columns, tt.record, 2)
+			if len(row.errors) > 0 {
+				t.Fatalf("parseImportRow() errors = %v", row.errors)
+			}
+			if row.update == nil {
+				t.Fatal("parseImportRow() did not parse an update")
+			}
+
+			raw, err := importPatch(row)
+			if err != nil {
+				t.Fatalf("importPatch() error = %v", err)
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(raw, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importPatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportRowCreate(t *testing.T) {
+	columns := map[string]int{"name": 0, "qty": 1}
+	row := parseImportRow(columns, []string{"Mug", "0"}, 2)
+	if row.op.Create == nil {
+		t.Fatal("parseImportRow() did not parse a create")
+	}
+	if !row.given["qty"] || row.op.Create.Qty != 0 {
+		t.Errorf("qty given = %v, value = %d, want a given 0", row.given["qty"], row.op.Create.Qty)
+	}
+}
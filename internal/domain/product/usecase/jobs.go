@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/pkg/export"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+)
+
+// Job types run by the product use case.
+const (
+	JobImport = "product.import"
+	JobExport = "product.export"
+)
+
+// RunImport runs an import job on the CSV file of the job input; the job
+// result is the import report. An invalid file fails the job without retries
+// and a cancelled job stops between rows.
+func (p *ProductUseCase) RunImport(ctx context.Context, run *JobUseCase.Run) error {
+	payload := &dto.ImportProductsDTO{}
+	if err := run.Decode(payload); err != nil {
+		return JobUseCase.Permanent(err)
+	}
+
+	input, err := run.OpenInput()
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	payload.File = input
+	payload.Context = ctx
+	payload.Progress = func(done int, total int) {
+		run.SetTotal(total)
+		run.SetProgress(done)
+	}
+
+	report, err := p.Import(payload)
+	if errors.Is(err, ErrInvalidImport) {
+		return JobUseCase.Permanent(err)
+	}
+	if err != nil {
+		return err
+	}
+	return run.SetResult(report)
+}
+
+// RunExport runs an export job into the job result file; the job result holds
+// the number of exported products. A cancelled job stops between rows.
+func (p *ProductUseCase) RunExport(ctx context.Context, run *JobUseCase.Run) error {
+	payload := &dto.ExportProductsDTO{}
+	if err := run.Decode(payload); err != nil {
+		return JobUseCase.Permanent(err)
+	}
+
+	values, err := url.ParseQuery(payload.Query)
+	if err != nil {
+		return JobUseCase.Permanent(err)
+	}
+
+	params, err := dto.ProductQuery(values)
+	if err != nil {
+		return JobUseCase.Permanent(err)
+	}
+
+	defaultOrder(params)
+	if err := p.prepareFilter(&params.ProductFilterDTO); err != nil {
+		return err
+	}
+
+	total, err := p.productRepository.Count(params)
+	if err != nil {
+		return err
+	}
+	run.SetTotal(total)
+
+	exported := 0
+	fileName := "products-" + time.Now().Format("20060102") + "." + payload.Format
+	err = run.WriteResultFile(fileName, export.ContentType(payload.Format), func(w io.Writer) error {
+		writer, err := export.NewWriter(payload.Format, w)
+		if err != nil {
+			return JobUseCase.Permanent(err)
+		}
+
+		if exported, err = p.export(params, &progressWriter{Writer: writer, ctx: ctx, run: run}); err != nil {
+			return err
+		}
+		return writer.Close()
+	})
+	if err != nil {
+		return err
+	}
+	return run.SetResult(map[string]interface{}{"exported": exported, "format": payload.Format})
+}
+
+// progressWriter reports the rows written so far as the progress of a job and
+// stops the export once the job is cancelled.
+type progressWriter struct {
+	export.Writer
+	ctx  context.Context
+	run  *JobUseCase.Run
+	rows int
+}
+
+func (w *progressWriter) Write(values []interface{}) error {
+	if err := stopped(w.ctx); err != nil {
+		return err
+	}
+
+	w.rows++
+	w.run.SetProgress(w.rows)
+	return w.Writer.Write(values)
+}
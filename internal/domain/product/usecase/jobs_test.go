@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+	"ecommerce/internal/domain/product/dto"
+	ProductRepository "ecommerce/internal/domain/product/repository"
+	"errors"
+	"testing"
+)
+
+// exportRows streams count products and calls after once for every row.
+type exportRows struct {
+	ProductRepository.IProductRepository
+	count int
+	after func(row int)
+}
+
+func (e *exportRows) Export(params *dto.ProductPaginationDTO, fn func(row *dto.ExportProductDTO) error) error {
+	for i := 1; i <= e.count; i++ {
+		if err := fn(&dto.ExportProductDTO{ID: int64(i), Name: "product"}); err != nil {
+			return err
+		}
+		e.after(i)
+	}
+	return nil
+}
+
+// rowCounter is an export writer that only counts rows.
+type rowCounter struct {
+	rows int
+}
+
+func (c *rowCounter) WriteHeader(columns []string) error { return nil }
+func (c *rowCounter) Write(values []interface{}) error   { c.rows++; return nil }
+func (c *rowCounter) Close() error                       { return nil }
+
+func TestExportStopsWhenCancelled(t *testing.T) {
+	tests := []struct {
+		name     string
+		cancelAt int
+		wantRows int
+		wantErr  error
+	}{
+		{name: "runs to the end", wantRows: 5},
+		{name: "cancelled mid export", cancelAt: 2, wantRows: 2, wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			repo := &exportRows{count: 5, after: func(row int) {
+				if row == tt.cancelAt {
+					cancel()
+				}
+			}}
+			p := &ProductUseCase{productRepository: repo}
+			counter := &rowCounter{}
+
+			exported, err := p.export(&dto.ProductPaginationDTO{}, &progressWriter{Writer: counter, ctx: ctx, run: &JobUseCase.Run{}})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("export() error = %v, want %v", err, tt.wantErr)
+			}
+			if counter.rows != tt.wantRows {
+				t.Errorf("wrote %d rows, want %d", counter.rows, tt.wantRows)
+			}
+			if tt.wantErr == nil && exported != tt.wantRows {
+				t.Errorf("export() = %d, want %d", exported, tt.wantRows)
+			}
+		})
+	}
+}
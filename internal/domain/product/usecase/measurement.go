@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/units"
+)
+
+const (
+	defaultWeightUnit = "kg"
+	defaultLengthUnit = "cm"
+)
+
+// applyMeasurements converts supplier supplied measurements to base units and
+// stores them on the product. nil inputs leave the product untouched.
+func applyMeasurements(product *entity.Product, weight *dto.WeightDTO, dimensions *dto.DimensionsDTO, content *dto.ContentDTO) error {
+	if weight != nil {
+		grams, err := units.ToBase(weight.Value, weight.Unit, units.Mass)
+		if err != nil {
+			return invalidMeasurement("weight", err)
+		}
+		product.WeightGrams = &grams
+	}
+
+	if dimensions != nil {
+		length, err := units.ToBase(dimensions.Length, dimensions.Unit, units.Length)
+		if err != nil {
+			return invalidMeasurement("dimensions", err)
+		}
+
+		width, err := units.ToBase(dimensions.Width, dimensions.Unit, units.Length)
+		if err != nil {
+			return invalidMeasurement("dimensions", err)
+		}
+
+		height, err := units.ToBase(dimensions.Height, dimensions.Unit, units.Length)
+		if err != nil {
+			return invalidMeasurement("dimensions", err)
+		}
+
+		product.LengthCm = &length
+		product.WidthCm = &width
+		product.HeightCm = &height
+	}
+
+	if content != nil {
+		unit, err := units.Lookup(content.Unit)
+		if err != nil {
+			return invalidMeasurement("content", err)
+		}
+
+		amount := content.Value * unit.Factor
+		base := units.Base(unit.Dimension).Symbol
+		product.ContentAmount = &amount
+		product.ContentUnit = &base
+	}
+
+	return nil
+}
+
+// invalidMeasurement reports a measurement given in an unknown unit or in a
+// unit of the wrong dimension.
+func invalidMeasurement(field string, err error) error {
+	return ErrInvalidProduct.WithErrors(map[string]string{field: err.Error()})
+}
+
+// copyMeasurements carries the stored measurements of an existing product
+// over to its replacement.
+func copyMeasurements(product *entity.Product, existing *entity.Product) {
+	product.WeightGrams = existing.WeightGrams
+	product.LengthCm = existing.LengthCm
+	product.WidthCm = existing.WidthCm
+	product.HeightCm = existing.HeightCm
+	product.ContentAmount = existing.ContentAmount
+	product.ContentUnit = existing.ContentUnit
+}
+
+// renderMeasurements fills the measurement fields of a product response in
+// the requested display units, including volumetric weight and the unit price
+// of the already rendered Price.
+func renderMeasurements(productDto *dto.FindProductDTO, product *entity.Product, weightUnit string, lengthUnit string) {
+	if weightUnit == "" {
+		weightUnit = defaultWeightUnit
+	}
+
+	if lengthUnit == "" {
+		lengthUnit = defaultLengthUnit
+	}
+
+	if product.WeightGrams != nil {
+		productDto.Weight = toWeightDTO(*product.WeightGrams, weightUnit)
+	}
+
+	if product.LengthCm != nil && product.WidthCm != nil && product.HeightCm != nil {
+		length, _ := units.FromBase(*product.LengthCm, lengthUnit, units.Length)
+		width, _ := units.FromBase(*product.WidthCm, lengthUnit, units.Length)
+		height, _ := units.FromBase(*product.HeightCm, lengthUnit, units.Length)
+		productDto.Dimensions = &dto.DimensionsDTO{
+			Length: units.Round(length, constants.MeasurementPrecision),
+			Width:  units.Round(width, constants.MeasurementPrecision),
+			Height: units.Round(height, constants.MeasurementPrecision),
+			Unit:   lengthUnit,
+		}
+
+		// volumetric kilograms = cm³ / divisor
+		volumetricGrams := *product.LengthCm * *product.WidthCm * *product.HeightCm / constants.VolumetricDivisor * 1000
+		productDto.VolumetricWeight = toWeightDTO(volumetricGrams, weightUnit)
+	}
+
+	if product.ContentAmount != nil && product.ContentUnit != nil && *product.ContentAmount > 0 {
+		per := "kg"
+		if *product.ContentUnit == "ml" {
+			per = "l"
+		}
+
+		unit, _ := units.Lookup(per)
+		productDto.Content = &dto.ContentDTO{
+			Value: units.Round(*product.ContentAmount/unit.Factor, constants.MeasurementPrecision),
+			Unit:  per,
+		}
+		productDto.UnitPrice = &dto.UnitPriceDTO{
+			Amount: units.Round(float64(productDto.Price)/(*product.ContentAmount/unit.Factor), 2),
+			Per:    per,
+		}
+	}
+}
+
+func toWeightDTO(grams float64, unit string) *dto.WeightDTO {
+	value, _ := units.FromBase(grams, unit, units.Mass)
+	return &dto.WeightDTO{
+		Value: units.Round(value, constants.MeasurementPrecision),
+		Unit:  unit,
+	}
+}
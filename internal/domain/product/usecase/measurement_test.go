@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/apperror"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMeasurementsRoundTrip(t *testing.T) {
+	product := &entity.Product{}
+	err := applyMeasurements(product,
+		&dto.WeightDTO{Value: 2.2, Unit: "lb"},
+		&dto.DimensionsDTO{Length: 20, Width: 10, Height: 5, Unit: "in"},
+		&dto.ContentDTO{Value: 500, Unit: "g"},
+	)
+	if err != nil {
+		t.Fatalf("applyMeasurements() error = %v", err)
+	}
+
+	if got := *product.LengthCm; got != 50.8 {
+		t.Errorf("stored length = %v cm, want 50.8", got)
+	}
+	if got := *product.ContentUnit; got != "g" {
+		t.Errorf("stored content unit = %q, want g", got)
+	}
+
+	rendered := &dto.FindProductDTO{Price: 30000}
+	renderMeasurements(rendered, product, "", "cm")
+
+	want := &dto.FindProductDTO{
+		Price:            30000,
+		Weight:           &dto.WeightDTO{Value: 0.998, Unit: "kg"},
+		Dimensions:       &dto.DimensionsDTO{Length: 50.8, Width: 25.4, Height: 12.7, Unit: "cm"},
+		VolumetricWeight: &dto.WeightDTO{Value: 3.277, Unit: "kg"},
+		Content:          &dto.ContentDTO{Value: 0.5, Unit: "kg"},
+		UnitPrice:        &dto.UnitPriceDTO{Amount: 60000, Per: "kg"},
+	}
+	if !reflect.DeepEqual(rendered, want) {
+		t.Errorf("renderMeasurements() = %+v, want %+v", rendered, want)
+	}
+}
+
+func TestApplyMeasurementsRejectsUnit(t *testing.T) {
+	tests := []struct {
+		name       string
+		weight     *dto.WeightDTO
+		dimensions *dto.DimensionsDTO
+		content    *dto.ContentDTO
+		field      string
+	}{
+		{name: "weight in length unit", weight: &dto.WeightDTO{Value: 1, Unit: "cm"}, field: "weight"},
+		{name: "dimensions in mass unit", dimensions: &dto.DimensionsDTO{Length: 1, Width: 1, Height: 1, Unit: "kg"}, field: "dimensions"},
+		{name: "unknown content unit", content: &dto.ContentDTO{Value: 1, Unit: "cup"}, field: "content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product := &entity.Product{}
+			err := applyMeasurements(product, tt.weight, tt.dimensions, tt.content)
+			if !errors.Is(err, ErrInvalidProduct) {
+				t.Fatalf("applyMeasurements() error = %v, want %v", err, ErrInvalidProduct)
+			}
+			var appErr *apperror.Error
+			errors.As(err, &appErr)
+			if fieldErrors, _ := appErr.Details()["errors"].(map[string]string); fieldErrors[tt.field] == "" {
+				t.Errorf("field errors = %v, want one for %s", appErr.Details()["errors"], tt.field)
+			}
+			if product.WeightGrams != nil || product.LengthCm != nil || product.ContentAmount != nil {
+				t.Errorf("applyMeasurements() stored %+v despite the error", product)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"bytes"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/jsonpatch"
+	"ecommerce/pkg/units"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// requiredFields are the members of a product document a patch may change
+// but not remove.
+var requiredFields = []string{"name", "type", "price", "qty", "brand_id"}
+
+// PatchProduct applies a merge patch or JSON patch to the document of the
+// stored product, its UpdateProductDTO, and writes the result. Members the
+// patch leaves alone keep their value and removed members are cleared, so
+// only what the patch names changes.
+func (p *ProductUseCase) PatchProduct(payload *dto.PatchProductDTO) error {
+	existing, err := p.productRepository.FindById(int(payload.ID))
+	if err != nil {
+		return apperror.RecordNotFound(err, ErrProductNotFound)
+	}
+
+	if !etag.Match(payload.IfMatch, existing.Version) {
+		return etag.ErrPreconditionFailed
+	}
+
+	before := toProductDocument(existing)
+	document, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if payload.Format == dto.PatchFormatJSONPatch {
+		patched, err = jsonpatch.Apply(document, payload.Patch)
+	} else {
+		patched, err = jsonpatch.MergePatch(document, payload.Patch)
+	}
+	if err != nil {
+		return err
+	}
+
+	after := &dto.UpdateProductDTO{}
+	if errs := p.decodeDocument(patched, after); errs != nil {
+		return ErrInvalidProduct.WithErrors(errs)
+	}
+
+	return p.replaceProduct(existing, before, after)
+}
+
+// decodeDocument decodes and validates a patched product document and returns
+// the field errors, or nil when it is valid.
+func (p *ProductUseCase) decodeDocument(document []byte, product *dto.UpdateProductDTO) map[string]string {
+	members := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(document, &members); err != nil {
+		return map[string]string{"document": "Document must be a JSON object"}
+	}
+
+	errs := make(map[string]string)
+	for _, field := range requiredFields {
+		if value, ok := members[field]; !ok || string(value) == "null" {
+			errs[field] = fmt.Sprintf("%s cannot be removed", field)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(product); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			errs[typeErr.Field] = fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type)
+		} else {
+			errs["document"] = err.Error()
+		}
+		return errs
+	}
+
+	// a removed member reads as removed rather than as empty
+	for field, message := range p.validator.Fields(product) {
+		if _, ok := errs[field]; !ok {
+			errs[field] = message
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// replaceProduct writes the patched document over the stored product.
+func (p *ProductUseCase) replaceProduct(existing *entity.Product, before *dto.UpdateProductDTO, after *dto.UpdateProductDTO) error {
+	product := &entity.Product{
+		ID:          existing.ID,
+		Version:     existing.Version,
+		Name:        after.Name,
+		Description: after.Description,
+		Type:        after.Type,
+		Price:       after.Price,
+		Qty:         after.Qty,
+		BrandId:     int(after.BrandId),
+		Barcode:     normalizeBarcode(after.Barcode),
+		Tags:        entity.NewProductTags(after.Tags),
+	}
+
+	if after.TaxClassId != nil && *after.TaxClassId != 0 {
+		taxClassId := uint(*after.TaxClassId)
+		product.TaxClassId = &taxClassId
+	}
+
+	// shipping measurements a product kept from before it stopped being
+	// physical are dropped, new ones are refused
+	weight, dimensions := after.Weight, after.Dimensions
+	if !product.IsShippable() {
+		if reflect.DeepEqual(weight, before.Weight) {
+			weight = nil
+		}
+		if reflect.DeepEqual(dimensions, before.Dimensions) {
+			dimensions = nil
+		}
+	}
+
+	if err := validateShipping(product.Type, weight, dimensions); err != nil {
+		return err
+	}
+
+	if err := applyMeasurements(product, weight, dimensions, after.Content); err != nil {
+		return err
+	}
+
+	if err := p.productRepository.Update(product); err != nil {
+		return p.conflictError(product.Barcode, err)
+	}
+
+	p.indexProduct(product.ID)
+	return nil
+}
+
+// productType returns the type of a product, physical for the products stored
+// before types existed.
+func productType(product *entity.Product) string {
+	if product.Type == "" {
+		return entity.ProductTypePhysical
+	}
+	return product.Type
+}
+
+// toProductDocument renders a product as the document patches apply to, with
+// its measurements in base units.
+func toProductDocument(product *entity.Product) *dto.UpdateProductDTO {
+	document := &dto.UpdateProductDTO{
+		Name:        product.Name,
+		Description: product.Description,
+		Type:        productType(product),
+		Price:       product.Price,
+		Qty:         product.Qty,
+		BrandId:     int64(product.BrandId),
+		Barcode:     product.Barcode,
+		Tags:        product.TagNames(),
+	}
+
+	if product.TaxClassId != nil {
+		taxClassId := int64(*product.TaxClassId)
+		document.TaxClassId = &taxClassId
+	}
+
+	if product.WeightGrams != nil {
+		document.Weight = &dto.WeightDTO{Value: *product.WeightGrams, Unit: units.Base(units.Mass).Symbol}
+	}
+
+	if product.LengthCm != nil && product.WidthCm != nil && product.HeightCm != nil {
+		document.Dimensions = &dto.DimensionsDTO{
+			Length: *product.LengthCm,
+			Width:  *product.WidthCm,
+			Height: *product.HeightCm,
+			Unit:   units.Base(units.Length).Symbol,
+		}
+	}
+
+	if product.ContentAmount != nil && product.ContentUnit != nil {
+		document.Content = &dto.ContentDTO{Value: *product.ContentAmount, Unit: *product.ContentUnit}
+	}
+	return document
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/product/dto"
+	ValidatorUtils "ecommerce/pkg/validator"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeDocument(t *testing.T) {
+	p := &ProductUseCase{validator: ValidatorUtils.NewRequestValidator()}
+	tests := []struct {
+		name     string
+		document string
+		want     map[string]string
+	}{
+		{
+			name:     "valid",
+			document: `{"name":"Mug","type":"physical","price":1500,"qty":0,"brand_id":2}`,
+		},
+		{
+			name:     "empty barcode clears it",
+			document: `{"name":"Mug","type":"physical","price":0,"qty":3,"brand_id":2,"barcode":""}`,
+		},
+		{
+			name:     "empty name",
+			document: `{"name":"","type":"physical","price":1500,"qty":3,"brand_id":2}`,
+			want:     map[string]string{"name": "Name is required"},
+		},
+		{
+			name:     "empty type",
+			document: `{"name":"Mug","type":"","price":1500,"qty":3,"brand_id":2}`,
+			want:     map[string]string{"type": "Type is required"},
+		},
+		{
+			name:     "unknown type",
+			document: `{"name":"Mug","type":"gift","price":1500,"qty":3,"brand_id":2}`,
+			want:     map[string]string{"type": "Type must be one of [physical digital service]"},
+		},
+		{
+			name:     "zero brand",
+			document: `{"name":"Mug","type":"physical","price":1500,"qty":3,"brand_id":0}`,
+			want:     map[string]string{"brand_id": "Brand_id must be greater than 0"},
+		},
+		{
+			name:     "negative price and qty",
+			document: `{"name":"Mug","type":"physical","price":-1,"qty":-1,"brand_id":2}`,
+			want: map[string]string{
+				"price": "Price must be greater than or equal to 0",
+				"qty":   "Qty must be greater than or equal to 0",
+			},
+		},
+		{
+			name:     "removed and null members",
+			document: `{"name":null,"type":"physical","qty":3,"brand_id":2}`,
+			want: map[string]string{
+				"name":  "name cannot be removed",
+				"price": "price cannot be removed",
+			},
+		},
+		{
+			name:     "wrong type",
+			document: `{"name":"Mug","type":"physical","price":"free","qty":3,"brand_id":2}`,
+			want: map[string]string{
+				"price": "price must be a int",
+			},
+		},
+		{
+			name:     "invalid barcode",
+			document: `{"name":"Mug","type":"physical","price":1500,"qty":3,"brand_id":2,"barcode":"123"}`,
+			want:     map[string]string{"barcode": "Barcode must be a valid GTIN-8, GTIN-12, GTIN-13 or GTIN-14 barcode"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.decodeDocument([]byte(tt.document), &dto.UpdateProductDTO{})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
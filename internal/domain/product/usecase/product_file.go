@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"ecommerce/constants"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/storage"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (p *ProductUseCase) UploadFile(
+	payload *dto.ProductWithIdDTO,
+	fileName string,
+	contentType string,
+	reader io.Reader,
+) (*dto.ProductFileDTO, error) {
+	if p.storage == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	product, err := p.productRepository.FindById(int(payload.ID))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrProductNotFound)
+	}
+
+	if product.Type != entity.ProductTypeDigital {
+		return nil, ErrProductNotDigital
+	}
+
+	key, err := newStorageKey(product.ID, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := p.storage.Put(key, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &entity.ProductFile{
+		ProductId:   product.ID,
+		FileName:    filepath.Base(fileName),
+		StorageKey:  key,
+		ContentType: contentType,
+		SizeBytes:   size,
+	}
+
+	if err := p.productRepository.CreateFile(file); err != nil {
+		_ = p.storage.Delete(key)
+		return nil, err
+	}
+
+	return toProductFileDTO(file), nil
+}
+
+// CreateDownloadLink issues an expiring signed link to one file of a digital
+// product.
+func (p *ProductUseCase) CreateDownloadLink(payload *dto.ProductFileWithIdDTO) (*dto.DownloadLinkDTO, error) {
+	if p.signer == nil {
+		return nil, ErrStorageNotConfigured
+	}
+
+	file, err := p.productRepository.FindFileById(uint(payload.FileId))
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrProductFileNotFound)
+	}
+
+	if int64(file.ProductId) != payload.ProductId {
+		return nil, ErrProductFileNotFound
+	}
+
+	expires, signature := p.signer.Sign(downloadResource(file.ID))
+	return &dto.DownloadLinkDTO{
+		URL:       fmt.Sprintf("%s/%d?expires=%d&signature=%s", constants.DownloadsPath, file.ID, expires.Unix(), signature),
+		ExpiresAt: expires.Format(time.RFC3339),
+	}, nil
+}
+
+// OpenDownload verifies a signed link and opens the file it points to. The
+// caller must close the returned reader.
+func (p *ProductUseCase) OpenDownload(payload *dto.DownloadDTO) (*dto.ProductFileDTO, io.ReadSeekCloser, error) {
+	if p.signer == nil || p.storage == nil {
+		return nil, nil, ErrStorageNotConfigured
+	}
+
+	if err := p.signer.Verify(downloadResource(uint(payload.FileId)), payload.Expires, payload.Signature); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := p.productRepository.FindFileById(uint(payload.FileId))
+	if err != nil {
+		return nil, nil, apperror.RecordNotFound(err, ErrProductFileNotFound)
+	}
+
+	reader, err := p.storage.Open(file.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrProductFileNotFound.Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toProductFileDTO(file), reader, nil
+}
+
+func downloadResource(fileId uint) string {
+	return fmt.Sprintf("product-file:%d", fileId)
+}
+
+func newStorageKey(productId uint, fileName string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, filepath.Base(fileName))
+
+	return fmt.Sprintf("products/%d/%s-%s", productId, hex.EncodeToString(random), name), nil
+}
+
+func toProductFileDTO(file *entity.ProductFile) *dto.ProductFileDTO {
+	return &dto.ProductFileDTO{
+		ID:          int64(file.ID),
+		FileName:    file.FileName,
+		ContentType: file.ContentType,
+		SizeBytes:   file.SizeBytes,
+		CreatedAt:   file.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
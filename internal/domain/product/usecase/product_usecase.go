@@ -1,35 +1,86 @@
 package usecase
 
 import (
+	"context"
+	"ecommerce/constants"
 	BrandDto "ecommerce/internal/domain/brand/dto"
+	BrandEntity "ecommerce/internal/domain/brand/entity"
 	BrandRepository "ecommerce/internal/domain/brand/repository"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
 	"ecommerce/internal/domain/product/dto"
 	"ecommerce/internal/domain/product/entity"
 	ProductRepository "ecommerce/internal/domain/product/repository"
+	TaxUseCase "ecommerce/internal/domain/tax/usecase"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/bulk"
+	"ecommerce/pkg/cursor"
+	"ecommerce/pkg/etag"
+	"ecommerce/pkg/export"
+	"ecommerce/pkg/fields"
+	"ecommerce/pkg/ordering"
+	"ecommerce/pkg/searchindex"
+	"ecommerce/pkg/storage"
+	ValidatorUtils "ecommerce/pkg/validator"
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"strconv"
+	"strings"
 )
 
 type IProductUseCase interface {
 	FindAll(params *dto.ProductPaginationDTO) (int, int, []*dto.FindProductDTO, error)
+	FindPage(params *dto.ProductPaginationDTO) ([]*dto.FindProductDTO, *cursor.Page, error)
+	FindFacets(params *dto.ProductFilterDTO) (*dto.ProductFacetsDTO, error)
+	Search(params *dto.SearchProductsDTO) (int, int, *dto.ProductSearchDTO, error)
 	FindById(payload *dto.ProductWithIdDTO) (*dto.FindProductDTO, error)
+	FindByBarcode(payload *dto.ProductWithBarcodeDTO) (*dto.FindProductDTO, error)
 	CreateProduct(product *dto.CreateProductDTO) error
-	UpdateProduct(product *dto.UpdateProductDTO) error
+	PatchProduct(payload *dto.PatchProductDTO) error
 	DeleteProduct(payload *dto.ProductWithIdDTO) error
+	BulkWrite(payload *dto.BulkProductDTO, report *bulk.Report) error
+	Import(payload *dto.ImportProductsDTO) (*dto.ImportReportDTO, error)
+	Export(params *dto.ProductPaginationDTO, writer export.Writer) (int, error)
+	RunImport(ctx context.Context, run *JobUseCase.Run) error
+	RunExport(ctx context.Context, run *JobUseCase.Run) error
+	UploadFile(payload *dto.ProductWithIdDTO, fileName string, contentType string, reader io.Reader) (*dto.ProductFileDTO, error)
+	CreateDownloadLink(payload *dto.ProductFileWithIdDTO) (*dto.DownloadLinkDTO, error)
+	OpenDownload(payload *dto.DownloadDTO) (*dto.ProductFileDTO, io.ReadSeekCloser, error)
+	Reindex() (int, error)
+	IndexBrandProducts(brandId uint) error
+	PruneIndex() (int, error)
 }
 
+// ProductUseCase mirrors product writes into the search index. The database
+// stays the source of truth: a failed index write is logged by the index and
+// repaired by the reindex command.
 type ProductUseCase struct {
 	productRepository ProductRepository.IProductRepository
 	brandRepository   BrandRepository.IBrandRepository
+	taxCalculator     TaxUseCase.ITaxCalculator
+	storage           storage.IStorage
+	signer            *storage.URLSigner
+	index             searchindex.IIndex
+	validator         *ValidatorUtils.RequestValidator
 }
 
 func NewProductUseCase(
 	productRepository ProductRepository.IProductRepository,
 	brandRepository BrandRepository.IBrandRepository,
+	taxCalculator TaxUseCase.ITaxCalculator,
+	storage storage.IStorage,
+	signer *storage.URLSigner,
+	index searchindex.IIndex,
 ) *ProductUseCase {
 	return &ProductUseCase{
 		productRepository: productRepository,
 		brandRepository:   brandRepository,
+		taxCalculator:     taxCalculator,
+		storage:           storage,
+		signer:            signer,
+		index:             index,
+		validator:         ValidatorUtils.NewRequestValidator(),
 	}
 }
 
@@ -44,12 +95,10 @@ func (p *ProductUseCase) FindAll(params *dto.ProductPaginationDTO) (int, int, []
 		params.PerPage = 10
 	}
 
-	if params.Sort == "" {
-		params.Sort = "desc"
-	}
+	defaultOrder(params)
 
-	if params.SortBy == "" {
-		params.SortBy = "created_at"
+	if err := p.prepareFilter(&params.ProductFilterDTO); err != nil {
+		return 0, 0, make([]*dto.FindProductDTO, 0), err
 	}
 
 	products, err := p.productRepository.FindAll(params)
@@ -57,28 +106,13 @@ func (p *ProductUseCase) FindAll(params *dto.ProductPaginationDTO) (int, int, []
 		return 0, 0, make([]*dto.FindProductDTO, 0), err
 	}
 
-	if len(products) > 0 {
-		for _, product := range products {
-			brand, _ := p.brandRepository.FindById(uint(product.BrandId))
-			productDto = append(productDto, &dto.FindProductDTO{
-				ID:    int64(product.ID),
-				Name:  product.Name,
-				Price: product.Price,
-				Qty:   product.Qty,
-				Brand: &BrandDto.FindBrandDTO{
-					ID:        int64(brand.ID),
-					Name:      brand.Name,
-					CreatedAt: brand.CreatedAt.Format("2006-01-02 15:04:05"),
-					UpdatedAt: brand.UpdatedAt.Format("2006-01-02 15:04:05"),
-				},
-				CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-				UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-			})
-		}
+	productDto, err = p.toFindProductDTOs(products, params)
+	if err != nil {
+		return 0, 0, make([]*dto.FindProductDTO, 0), err
 	}
 
 	totalPage := 0.0
-	count, err := p.productRepository.Count()
+	count, err := p.productRepository.Count(params)
 	if err != nil {
 		return 0, 0, make([]*dto.FindProductDTO, 0), err
 	}
@@ -87,73 +121,266 @@ func (p *ProductUseCase) FindAll(params *dto.ProductPaginationDTO) (int, int, []
 	return count, int(totalPage), productDto, nil
 }
 
-func (p *ProductUseCase) FindById(payload *dto.ProductWithIdDTO) (*dto.FindProductDTO, error) {
-	product, err := p.productRepository.FindById(int(payload.ID))
+// FindPage returns the page of products around params.Cursor. The total is
+// only counted when params.WithCount is set.
+func (p *ProductUseCase) FindPage(params *dto.ProductPaginationDTO) ([]*dto.FindProductDTO, *cursor.Page, error) {
+	defaultOrder(params)
+
+	sort := ordering.Format(params.OrderBy)
+	if params.Cursor != nil && params.Cursor.Sort != sort {
+		return make([]*dto.FindProductDTO, 0), nil, cursor.ErrSortMismatch
+	}
+
+	if err := p.prepareFilter(&params.ProductFilterDTO); err != nil {
+		return make([]*dto.FindProductDTO, 0), nil, err
+	}
+
+	products, more, err := p.productRepository.FindPage(params)
+	if err != nil {
+		return make([]*dto.FindProductDTO, 0), nil, err
+	}
+
+	productDto, err := p.toFindProductDTOs(products, params)
+	if err != nil {
+		return make([]*dto.FindProductDTO, 0), nil, err
+	}
+
+	page := &cursor.Page{}
+	if len(products) > 0 {
+		backward := params.Cursor != nil && params.Cursor.Backward
+		if more || backward {
+			page.Next = (&cursor.Cursor{ID: products[len(products)-1].ID, Sort: sort}).Encode()
+		}
+		if params.Cursor != nil && (more || !backward) {
+			page.Prev = (&cursor.Cursor{ID: products[0].ID, Backward: true, Sort: sort}).Encode()
+		}
+	}
+
+	if params.WithCount {
+		count, err := p.productRepository.Count(params)
+		if err != nil {
+			return make([]*dto.FindProductDTO, 0), nil, err
+		}
+		page.Total = &count
+	}
+
+	return productDto, page, nil
+}
+
+// defaultOrder sorts by relevance while searching and by newest first
+// otherwise.
+func defaultOrder(params *dto.ProductPaginationDTO) {
+	if len(params.OrderBy) == 0 && params.Search != "" {
+		params.OrderBy = []ordering.Key{dto.ProductSortFields.Key("relevance", true)}
+	}
+
+	if len(params.OrderBy) == 0 {
+		params.OrderBy = []ordering.Key{dto.ProductSortFields.Key("created_at", true)}
+	}
+}
+
+func (p *ProductUseCase) toFindProductDTOs(products []*entity.Product, params *dto.ProductPaginationDTO) ([]*dto.FindProductDTO, error) {
+	return p.renderProducts(products, &renderOptions{
+		weightUnit: params.WeightUnit,
+		lengthUnit: params.LengthUnit,
+		taxRegion:  params.TaxRegion,
+		selection:  params.Selection,
+	})
+}
+
+// renderProducts renders a page of products with the brands and tax classes
+// of the whole page loaded at once.
+func (p *ProductUseCase) renderProducts(products []*entity.Product, options *renderOptions) ([]*dto.FindProductDTO, error) {
+	brands, err := p.loadBrands(products, options.selection)
 	if err != nil {
 		return nil, err
 	}
 
-	if product == nil {
-		return nil, errors.New("product not found")
+	taxClasses, err := p.loadTaxClasses(products, options.selection)
+	if err != nil {
+		return nil, err
 	}
+	options.taxClasses = taxClasses
 
-	brand, _ := p.brandRepository.FindById(uint(product.BrandId))
-	return &dto.FindProductDTO{
-		ID:    int64(product.ID),
-		Name:  product.Name,
-		Price: product.Price,
-		Qty:   product.Qty,
-		Brand: &BrandDto.FindBrandDTO{
-			ID:        int64(brand.ID),
-			Name:      brand.Name,
-			CreatedAt: brand.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt: brand.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
-		CreatedAt: product.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt: product.UpdatedAt.Format("2006-01-02 15:04:05"),
-	}, nil
+	productDto := make([]*dto.FindProductDTO, 0, len(products))
+	for _, product := range products {
+		productDto = append(productDto, p.toFindProductDTO(product, brands[uint(product.BrandId)], options))
+	}
+	return productDto, nil
 }
 
-func (p *ProductUseCase) CreateProduct(payload *dto.CreateProductDTO) error {
-	product := &entity.Product{
-		Name:    payload.Name,
-		Price:   payload.Price,
-		Qty:     payload.Qty,
-		BrandId: int(payload.BrandId),
+// loadBrands loads the brands of products at once, and nothing when the
+// selection leaves the brand out.
+func (p *ProductUseCase) loadBrands(products []*entity.Product, selection *fields.Selection) (map[uint]*BrandEntity.Brand, error) {
+	if !selection.Include("brand") {
+		return nil, nil
 	}
 
-	err := p.productRepository.Create(product)
+	seen := make(map[uint]bool, len(products))
+	brandIds := make([]uint, 0, len(products))
+	for _, product := range products {
+		if brandId := uint(product.BrandId); !seen[brandId] {
+			seen[brandId] = true
+			brandIds = append(brandIds, brandId)
+		}
+	}
+
+	found, err := p.brandRepository.FindByIds(brandIds)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	brands := make(map[uint]*BrandEntity.Brand, len(found))
+	for _, brand := range found {
+		brands[brand.ID] = brand
+	}
+	return brands, nil
+}
+
+// loadTaxClasses loads the tax classes of products at once, and nothing when
+// the selection renders no price.
+func (p *ProductUseCase) loadTaxClasses(products []*entity.Product, selection *fields.Selection) (TaxUseCase.TaxClasses, error) {
+	if !rendersPrice(selection) {
+		return nil, nil
+	}
+
+	taxClassIds := make([]uint, 0, len(products))
+	for _, product := range products {
+		if product.TaxClassId != nil {
+			taxClassIds = append(taxClassIds, *product.TaxClassId)
+		}
+	}
+	return p.taxCalculator.LoadClasses(taxClassIds)
+}
+
+func rendersPrice(selection *fields.Selection) bool {
+	return selection.Field("price") || selection.Field("pricing") || selection.Field("unit_price")
+}
+
+// findBrand loads the product brand unless the selection leaves it out.
+func (p *ProductUseCase) findBrand(product *entity.Product, selection *fields.Selection) *BrandEntity.Brand {
+	if !selection.Include("brand") {
+		return nil
+	}
+
+	brand, _ := p.brandRepository.FindById(uint(product.BrandId))
+	return brand
+}
+
+func (p *ProductUseCase) FindFacets(params *dto.ProductFilterDTO) (*dto.ProductFacetsDTO, error) {
+	if err := p.prepareFilter(params); err != nil {
+		return nil, err
+	}
+
+	return p.productRepository.Facets(params, constants.PriceFacetBuckets)
+}
+
+// prepareFilter normalizes the tag filter and resolves brand_id, expanded to
+// its sub-brands when asked, into the BrandIds the repository filters on.
+func (p *ProductUseCase) prepareFilter(params *dto.ProductFilterDTO) error {
+	params.Tags = entity.NormalizeTags(params.Tags)
+	if params.TagMatch == "" {
+		params.TagMatch = "any"
+	}
+
+	if params.BrandId != 0 {
+		params.BrandIds = append(params.BrandIds, params.BrandId)
+	}
+
+	if params.IncludeSubBrands && len(params.BrandIds) > 0 {
+		brandIds := make([]int64, 0, len(params.BrandIds))
+		for _, brandId := range params.BrandIds {
+			descendantIds, err := p.brandRepository.FindDescendantIds(uint(brandId))
+			if err != nil {
+				return err
+			}
+			if len(descendantIds) == 0 {
+				descendantIds = []int64{brandId}
+			}
+			brandIds = append(brandIds, descendantIds...)
+		}
+		params.BrandIds = brandIds
 	}
 
 	return nil
 }
 
-func (p *ProductUseCase) UpdateProduct(payload *dto.UpdateProductDTO) error {
-	product := &entity.Product{
-		ID:      uint(payload.ID),
-		Name:    payload.Name,
-		Price:   payload.Price,
-		Qty:     payload.Qty,
-		BrandId: int(payload.BrandId),
+func (p *ProductUseCase) FindById(payload *dto.ProductWithIdDTO) (*dto.FindProductDTO, error) {
+	product, err := p.productRepository.FindByIdWithFields(int(payload.ID), payload.Selection)
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrProductNotFound)
 	}
 
-	productExists, err := p.productRepository.FindById(int(product.ID))
+	taxClasses, err := p.loadTaxClasses([]*entity.Product{product}, payload.Selection)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if productExists == nil {
-		return errors.New("product not found")
+	return p.toFindProductDTO(product, p.findBrand(product, payload.Selection), &renderOptions{
+		weightUnit: payload.WeightUnit,
+		lengthUnit: payload.LengthUnit,
+		taxRegion:  payload.TaxRegion,
+		taxClasses: taxClasses,
+		selection:  payload.Selection,
+	}), nil
+}
+
+func (p *ProductUseCase) FindByBarcode(payload *dto.ProductWithBarcodeDTO) (*dto.FindProductDTO, error) {
+	product, err := p.productRepository.FindByBarcode(ValidatorUtils.NormalizeGTIN(payload.Barcode), payload.Selection)
+	if err != nil {
+		return nil, apperror.RecordNotFound(err, ErrProductNotFound)
 	}
 
-	err = p.productRepository.Update(product)
+	taxClasses, err := p.loadTaxClasses([]*entity.Product{product}, payload.Selection)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return p.toFindProductDTO(product, p.findBrand(product, payload.Selection), &renderOptions{taxClasses: taxClasses, selection: payload.Selection}), nil
+}
+
+func (p *ProductUseCase) CreateProduct(payload *dto.CreateProductDTO) error {
+	_, err := p.createProduct(payload)
+	return err
+}
+
+// createProduct creates the product and returns its id.
+func (p *ProductUseCase) createProduct(payload *dto.CreateProductDTO) (uint, error) {
+	product := &entity.Product{
+		Name:        payload.Name,
+		Description: payload.Description,
+		Type:        payload.Type,
+		Price:       payload.Price,
+		Qty:         payload.Qty,
+		BrandId:     int(payload.BrandId),
+		Barcode:     normalizeBarcode(payload.Barcode),
+		Tags:        entity.NewProductTags(payload.Tags),
+	}
+
+	if payload.TaxClassId != nil && *payload.TaxClassId != 0 {
+		taxClassId := uint(*payload.TaxClassId)
+		product.TaxClassId = &taxClassId
+	}
+
+	if product.Type == "" {
+		product.Type = entity.ProductTypePhysical
+	}
+
+	if err := validateShipping(product.Type, payload.Weight, payload.Dimensions); err != nil {
+		return 0, err
+	}
+
+	if err := applyMeasurements(product, payload.Weight, payload.Dimensions, payload.Content); err != nil {
+		return 0, err
+	}
+
+	err := p.productRepository.Create(product)
+	if err != nil {
+		return 0, p.conflictError(product.Barcode, err)
+	}
+
+	p.indexProduct(product.ID)
+	return product.ID, nil
 }
 
 func (p *ProductUseCase) DeleteProduct(payload *dto.ProductWithIdDTO) error {
@@ -163,17 +390,117 @@ func (p *ProductUseCase) DeleteProduct(payload *dto.ProductWithIdDTO) error {
 
 	productExists, err := p.productRepository.FindById(int(payload.ID))
 	if err != nil {
-		return err
+		return apperror.RecordNotFound(err, ErrProductNotFound)
 	}
 
-	if productExists == nil {
-		return errors.New("product not found")
+	if !etag.Match(payload.IfMatch, productExists.Version) {
+		return etag.ErrPreconditionFailed
 	}
+	product.Version = productExists.Version
 
 	err = p.productRepository.Delete(product)
 	if err != nil {
 		return err
 	}
 
+	_ = p.index.Delete(SearchDocumentType, strconv.FormatUint(uint64(product.ID), 10))
+	return nil
+}
+
+// renderOptions selects how a product is presented: display units for its
+// measurements, the tax region and loaded tax classes its pricing is
+// calculated with and which fields and relations are rendered at all.
+type renderOptions struct {
+	weightUnit string
+	lengthUnit string
+	taxRegion  string
+	taxClasses TaxUseCase.TaxClasses
+	selection  *fields.Selection
+}
+
+func (p *ProductUseCase) toFindProductDTO(product *entity.Product, brand *BrandEntity.Brand, options *renderOptions) *dto.FindProductDTO {
+	productDto := &dto.FindProductDTO{
+		ID:          int64(product.ID),
+		Name:        product.Name,
+		Price:       product.Price,
+		Qty:         product.Qty,
+		Description: product.Description,
+		Type:        product.Type,
+		Barcode:     product.Barcode,
+		Tags:        product.TagNames(),
+		Version:     product.Version,
+		CreatedAt:   product.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   product.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if brand != nil {
+		productDto.Brand = &BrandDto.FindBrandDTO{
+			ID:        int64(brand.ID),
+			Name:      brand.Name,
+			Version:   brand.Version,
+			CreatedAt: brand.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt: brand.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	if product.Type == entity.ProductTypeDigital {
+		productDto.Files = make([]*dto.ProductFileDTO, 0, len(product.Files))
+		for i := range product.Files {
+			productDto.Files = append(productDto.Files, toProductFileDTO(&product.Files[i]))
+		}
+	}
+
+	// price is always the net amount; the gross one is under pricing
+	if rendersPrice(options.selection) {
+		productDto.Pricing = p.taxCalculator.Calculate(int64(product.Price), product.TaxClassId, options.taxRegion, options.taxClasses)
+		productDto.Price = int(productDto.Pricing.Net)
+	}
+
+	renderMeasurements(productDto, product, options.weightUnit, options.lengthUnit)
+	return productDto
+}
+
+// normalizeBarcode trims the barcode and maps an empty value to NULL so it
+// does not take part in the uniqueness check.
+func normalizeBarcode(barcode *string) *string {
+	if barcode == nil {
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(*barcode)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+// conflictError resolves a barcode unique violation into ErrBarcodeTaken
+// carrying the id of the product that already owns the barcode.
+func (p *ProductUseCase) conflictError(barcode *string, err error) error {
+	if !errors.Is(err, ProductRepository.ErrBarcodeTaken) || barcode == nil {
+		return err
+	}
+
+	existing, findErr := p.productRepository.FindByBarcode(ValidatorUtils.NormalizeGTIN(*barcode), nil)
+	if findErr != nil {
+		return err
+	}
+
+	return ErrBarcodeTaken.WithDetails(map[string]interface{}{"existing_id": existing.ID})
+}
+
+// validateShipping rejects shipping measurements on products that are never
+// shipped.
+func validateShipping(productType string, weight *dto.WeightDTO, dimensions *dto.DimensionsDTO) error {
+	if productType == entity.ProductTypePhysical {
+		return nil
+	}
+
+	if weight != nil || dimensions != nil {
+		return ErrInvalidProduct.WithErrors(map[string]string{
+			"type": fmt.Sprintf("%s products cannot have weight or dimensions", productType),
+		})
+	}
+
 	return nil
 }
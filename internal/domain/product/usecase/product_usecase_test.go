@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	BrandEntity "ecommerce/internal/domain/brand/entity"
+	BrandRepository "ecommerce/internal/domain/brand/repository"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/fields"
+	"net/url"
+	"testing"
+)
+
+// pageBrands serves brands by id and counts the queries made for them.
+type pageBrands struct {
+	BrandRepository.IBrandRepository
+	brands  map[uint]*BrandEntity.Brand
+	queries int
+}
+
+func (b *pageBrands) FindByIds(ids []uint) ([]*BrandEntity.Brand, error) {
+	b.queries++
+	found := make([]*BrandEntity.Brand, 0, len(ids))
+	for _, id := range ids {
+		if brand, ok := b.brands[id]; ok {
+			found = append(found, brand)
+		}
+	}
+	return found, nil
+}
+
+func (b *pageBrands) FindById(id uint) (*BrandEntity.Brand, error) {
+	b.queries++
+	return b.brands[id], nil
+}
+
+func TestRenderProductsBrands(t *testing.T) {
+	products := []*entity.Product{
+		{ID: 1, Name: "Shirt", BrandId: 7},
+		{ID: 2, Name: "Mug", BrandId: 8},
+		{ID: 3, Name: "Cap", BrandId: 7},
+		{ID: 4, Name: "Orphan", BrandId: 9},
+	}
+
+	tests := []struct {
+		name        string
+		query       string
+		wantQueries int
+		wantBrands  []string
+	}{
+		{name: "brand included", query: "fields=id,name&include=brand", wantQueries: 1, wantBrands: []string{"Acme", "Globex", "Acme", ""}},
+		{name: "brand left out", query: "fields=id,name", wantQueries: 0, wantBrands: []string{"", "", "", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brands := &pageBrands{brands: map[uint]*BrandEntity.Brand{
+				7: {ID: 7, Name: "Acme"},
+				8: {ID: 8, Name: "Globex"},
+			}}
+			p := &ProductUseCase{brandRepository: brands}
+
+			values, _ := url.ParseQuery(tt.query)
+			selection, err := fields.FromQuery(values, dto.ProductFields, dto.ProductIncludes)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rendered, err := p.renderProducts(products, &renderOptions{selection: selection})
+			if err != nil {
+				t.Fatalf("renderProducts() error = %v", err)
+			}
+
+			if brands.queries != tt.wantQueries {
+				t.Errorf("brand queries = %d, want %d", brands.queries, tt.wantQueries)
+			}
+			for i, product := range rendered {
+				name := ""
+				if product.Brand != nil {
+					name = product.Brand.Name
+				}
+				if name != tt.wantBrands[i] {
+					t.Errorf("product %d brand = %q, want %q", product.ID, name, tt.wantBrands[i])
+				}
+			}
+		})
+	}
+}
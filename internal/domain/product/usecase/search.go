@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/searchindex"
+	"math"
+	"strconv"
+)
+
+// searchTextFields are the text fields of a product document a search
+// matches, weighted by how much a match in them counts.
+var searchTextFields = map[string]float64{"name": 3, "brand_name": 2, "description": 1}
+
+// searchFacets are the keyword fields of a product document a search counts
+// the values of.
+var searchFacets = []string{"brand_id", "type", "tags", "in_stock"}
+
+// Search finds products in the search index, best match first, and returns
+// the number of hits, the number of pages and the page of products with the
+// facet counts of every hit. Products deleted since they were indexed are
+// left out of the page.
+func (p *ProductUseCase) Search(params *dto.SearchProductsDTO) (int, int, *dto.ProductSearchDTO, error) {
+	if !searchindex.Enabled(p.index) {
+		return 0, 0, nil, searchindex.ErrDisabled
+	}
+
+	result, err := p.index.Search(searchQuery(params))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	ids := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		if id, err := strconv.ParseUint(hit.ID, 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+
+	found, err := p.productRepository.FindByIds(ids, params.Selection)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	byId := make(map[uint]*entity.Product, len(found))
+	for _, product := range found {
+		byId[product.ID] = product
+	}
+
+	products := make([]*entity.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := byId[id]; ok {
+			products = append(products, product)
+		}
+	}
+
+	productDto, err := p.renderProducts(products, &renderOptions{
+		weightUnit: params.WeightUnit,
+		lengthUnit: params.LengthUnit,
+		taxRegion:  params.TaxRegion,
+		selection:  params.Selection,
+	})
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	search := &dto.ProductSearchDTO{
+		Products: productDto,
+		Facets:   make(map[string][]*dto.SearchFacetDTO, len(result.Facets)),
+	}
+	for field, counts := range result.Facets {
+		facet := make([]*dto.SearchFacetDTO, 0, len(counts))
+		for _, count := range counts {
+			facet = append(facet, &dto.SearchFacetDTO{Value: count.Term, Count: count.Count})
+		}
+		search.Facets[field] = facet
+	}
+
+	total := int(result.Total)
+	totalPage := math.Ceil(float64(total) / float64(params.PerPage))
+	return total, int(totalPage), search, nil
+}
+
+// searchQuery translates a product search into a query of the product
+// documents written by toSearchDocument.
+func searchQuery(params *dto.SearchProductsDTO) *searchindex.Query {
+	query := &searchindex.Query{
+		Type:       SearchDocumentType,
+		Text:       params.Query,
+		TextFields: searchTextFields,
+		Terms:      make(map[string][]string),
+		Ranges:     make(map[string]searchindex.Range),
+		Facets:     searchFacets,
+		FacetSize:  constants.SearchFacetSize,
+		From:       int((params.Page - 1) * params.PerPage),
+		Size:       int(params.PerPage),
+	}
+
+	for _, brandId := range params.BrandIds {
+		query.Terms["brand_id"] = append(query.Terms["brand_id"], strconv.FormatInt(brandId, 10))
+	}
+	if len(params.Types) > 0 {
+		query.Terms["type"] = params.Types
+	}
+	if tags := entity.NormalizeTags(params.Tags); len(tags) > 0 {
+		query.Terms["tags"] = tags
+	}
+	if params.InStock != nil {
+		query.Terms["in_stock"] = []string{strconv.FormatBool(*params.InStock)}
+	}
+
+	if params.MinPrice != nil || params.MaxPrice != nil {
+		var priceRange searchindex.Range
+		if params.MinPrice != nil {
+			minPrice := float64(*params.MinPrice)
+			priceRange.Min = &minPrice
+		}
+		if params.MaxPrice != nil {
+			maxPrice := float64(*params.MaxPrice)
+			priceRange.Max = &maxPrice
+		}
+		query.Ranges["price"] = priceRange
+	}
+	return query
+}
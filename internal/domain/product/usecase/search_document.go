@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	BrandEntity "ecommerce/internal/domain/brand/entity"
+	"ecommerce/internal/domain/product/dto"
+	"ecommerce/internal/domain/product/entity"
+	"ecommerce/pkg/ordering"
+	"ecommerce/pkg/searchindex"
+	"strconv"
+)
+
+const SearchDocumentType = "product"
+
+// toSearchDocument denormalizes the brand name into the product document;
+// renaming a brand rewrites the documents of its products.
+func toSearchDocument(product *entity.Product, brand *BrandEntity.Brand) *searchindex.Document {
+	text := map[string]string{"name": product.Name}
+	if product.Description != nil {
+		text["description"] = *product.Description
+	}
+	if brand != nil {
+		text["brand_name"] = brand.Name
+	}
+
+	inStock := product.Qty > 0 || !product.IsShippable()
+	return &searchindex.Document{
+		ID:   strconv.FormatUint(uint64(product.ID), 10),
+		Type: SearchDocumentType,
+		Text: text,
+		Keywords: map[string][]string{
+			"brand_id": {strconv.Itoa(product.BrandId)},
+			"type":     {product.Type},
+			"tags":     product.TagNames(),
+			"in_stock": {strconv.FormatBool(inStock)},
+		},
+		Numbers: map[string]float64{"price": float64(product.Price)},
+	}
+}
+
+// indexProduct reloads the product so the document carries its stored tags
+// and writes it to the search index.
+func (p *ProductUseCase) indexProduct(id uint) {
+	product, err := p.productRepository.FindById(int(id))
+	if err != nil {
+		return
+	}
+
+	brand, _ := p.brandRepository.FindById(uint(product.BrandId))
+	_ = p.index.Index(toSearchDocument(product, brand))
+}
+
+// Reindex writes every product to the search index and returns how many
+// were indexed.
+func (p *ProductUseCase) Reindex() (int, error) {
+	return p.indexProducts(dto.ProductFilterDTO{})
+}
+
+// IndexBrandProducts rewrites the documents of the products of a brand, which
+// carry its name.
+func (p *ProductUseCase) IndexBrandProducts(brandId uint) error {
+	_, err := p.indexProducts(dto.ProductFilterDTO{BrandIds: []int64{int64(brandId)}})
+	return err
+}
+
+// indexProducts writes the products matching filter to the search index in
+// batches and returns how many were indexed.
+func (p *ProductUseCase) indexProducts(filter dto.ProductFilterDTO) (int, error) {
+	params := &dto.ProductPaginationDTO{
+		PerPage:          int64(constants.ReindexBatchSize),
+		Page:             1,
+		OrderBy:          []ordering.Key{dto.ProductSortFields.Key("id", false)},
+		ProductFilterDTO: filter,
+	}
+
+	brands := make(map[int]*BrandEntity.Brand)
+	total := 0
+	for {
+		products, err := p.productRepository.FindAll(params)
+		if err != nil {
+			return total, err
+		}
+
+		docs := make([]*searchindex.Document, 0, len(products))
+		for _, product := range products {
+			brand, ok := brands[product.BrandId]
+			if !ok {
+				brand, _ = p.brandRepository.FindById(uint(product.BrandId))
+				brands[product.BrandId] = brand
+			}
+			docs = append(docs, toSearchDocument(product, brand))
+		}
+
+		if err := p.index.Index(docs...); err != nil {
+			return total, err
+		}
+
+		total += len(products)
+		if len(products) < int(params.PerPage) {
+			return total, nil
+		}
+		params.Page++
+	}
+}
+
+// PruneIndex deletes the documents of products that no longer exist and
+// returns how many were deleted.
+func (p *ProductUseCase) PruneIndex() (int, error) {
+	return searchindex.Prune(p.index, SearchDocumentType, constants.ReindexBatchSize, p.productRepository.FindExistingIds)
+}
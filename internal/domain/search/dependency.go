@@ -0,0 +1,45 @@
+package search
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/constants"
+	BrandRepository "ecommerce/internal/domain/brand/repository"
+	BrandUseCase "ecommerce/internal/domain/brand/usecase"
+	JobDeps "ecommerce/internal/domain/job"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+	ProductDeps "ecommerce/internal/domain/product"
+	"ecommerce/internal/domain/search/presenter"
+	SearchRepository "ecommerce/internal/domain/search/repository"
+	SearchUseCase "ecommerce/internal/domain/search/usecase"
+	"ecommerce/pkg/searchindex"
+	"log/slog"
+)
+
+func NewSearchDependency(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) presenter.ISearchPresenter {
+	timeout := config.AppConfig.SearchSuggestTimeout
+	if timeout <= 0 {
+		timeout = constants.DefaultSuggestTimeout
+	}
+
+	repository := SearchRepository.NewSearchRepository(ctx, dbProvider, logger, timeout)
+	useCase := SearchUseCase.NewSearchUseCase(repository)
+	jobUseCase := JobDeps.NewJobUseCase(ctx, dbProvider, logger)
+	return presenter.NewSearchPresenter(useCase, jobUseCase, searchindex.Enabled(config.SearchIndexProvider))
+}
+
+// RegisterJobs sets the handler of the reindex job. Brands go first so that
+// the product documents pick up their current names.
+func RegisterJobs(worker *JobUseCase.Worker, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) {
+	worker.Register(SearchUseCase.JobReindex, func(ctx context.Context, run *JobUseCase.Run) error {
+		brandRepository := BrandRepository.NewBrandRepository(ctx, dbProvider, logger)
+		return SearchUseCase.RunReindex(ctx, run,
+			BrandUseCase.NewBrandUseCase(brandRepository, config.SearchIndexProvider, nil),
+			ProductDeps.NewProductUseCase(ctx, dbProvider, logger),
+		)
+	})
+}
@@ -0,0 +1,13 @@
+package dto
+
+type SuggestDTO struct {
+	Query string `json:"q" query:"q" validate:"max=100"`
+	Limit int    `json:"limit" query:"limit" validate:"omitempty,gte=1,lte=20"`
+}
+
+type SuggestionDTO struct {
+	Type  string  `json:"type"`
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
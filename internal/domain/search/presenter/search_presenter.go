@@ -0,0 +1,96 @@
+package presenter
+
+import (
+	JobDto "ecommerce/internal/domain/job/dto"
+	JobPresenter "ecommerce/internal/domain/job/presenter"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+	"ecommerce/internal/domain/search/dto"
+	"ecommerce/internal/domain/search/usecase"
+	"ecommerce/pkg/apperror"
+	"ecommerce/pkg/searchindex"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+
+	HttpResponser "ecommerce/pkg/response"
+)
+
+type ISearchPresenter interface {
+	Suggest(c echo.Context) error
+	Reindex(c echo.Context) error
+}
+
+type SearchPresenter struct {
+	useCase      usecase.ISearchUseCase
+	jobUseCase   JobUseCase.IJobUseCase
+	indexEnabled bool
+}
+
+func NewSearchPresenter(useCase usecase.ISearchUseCase, jobUseCase JobUseCase.IJobUseCase, indexEnabled bool) *SearchPresenter {
+	return &SearchPresenter{
+		useCase:      useCase,
+		jobUseCase:   jobUseCase,
+		indexEnabled: indexEnabled,
+	}
+}
+
+// Suggest godoc
+// @Summary      Search suggestions
+// @Description  Suggest product and brand names for the search box, tolerating typos
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Param 		 q query string true "search box input"
+// @Param 		 limit query int false "maximum number of suggestions (default 8, max 20)"
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.SuggestionDTO}
+// @Router       /search/suggest [get]
+func (presenter *SearchPresenter) Suggest(c echo.Context) error {
+	payload := &dto.SuggestDTO{
+		Query: c.QueryParam("q"),
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			c.Logger().Error(err)
+			return apperror.Invalid(err)
+		}
+		payload.Limit = limit
+	}
+
+	if err := c.Validate(payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	suggestions, err := presenter.useCase.Suggest(payload)
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get suggestions success", suggestions)
+}
+
+// Reindex godoc
+// @Summary      Rebuild the search index
+// @Description  Queue a job that writes every brand and product to the search index and deletes the documents of deleted ones. Searches keep working while it runs; poll the job for its progress
+// @Tags         search
+// @Produce      json
+// @Success      202  {object}  response.SuccessResponse{data=JobDto.FindJobDTO}
+// @Failure      503  {object}  response.Problem
+// @Router       /search/reindex [post]
+func (presenter *SearchPresenter) Reindex(c echo.Context) error {
+	if !presenter.indexEnabled {
+		return searchindex.ErrDisabled
+	}
+
+	// a reindex only writes what the database holds, so it is safe to repeat
+	job, err := presenter.jobUseCase.Enqueue(&JobDto.EnqueueJobDTO{Type: usecase.JobReindex})
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return JobPresenter.Accepted(c, "Reindex queued", job)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/search/dto"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const queryCanceledCode = "57014"
+
+var ErrSuggestTimeout = errors.New("search suggestions exceeded the latency budget")
+
+//go:generate mockgen -source=search_repository.go -destination=mocks/search_repository_mock.go -package=mocks
+type ISearchRepository interface {
+	Suggest(query string, limit int, threshold float64) ([]*dto.SuggestionDTO, error)
+}
+
+type SearchRepository struct {
+	dbProvider *config.DatabaseConfiguration
+	ctx        context.Context
+	logger     *slog.Logger
+	timeout    time.Duration
+}
+
+func NewSearchRepository(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+	timeout time.Duration,
+) *SearchRepository {
+	return &SearchRepository{
+		dbProvider: dbProvider,
+		ctx:        ctx,
+		logger:     logger,
+		timeout:    timeout,
+	}
+}
+
+// Suggest returns product and brand names that either start with the query or
+// contain a word similar to it, prefix matches first. The lookup is cut off
+// by both a context deadline and a statement_timeout once the latency budget
+// is spent, in which case ErrSuggestTimeout is returned.
+func (repo *SearchRepository) Suggest(query string, limit int, threshold float64) ([]*dto.SuggestionDTO, error) {
+	ctx, cancel := context.WithTimeout(repo.ctx, repo.timeout)
+	defer cancel()
+
+	query = strings.ToLower(query)
+	suggestions := make([]*dto.SuggestionDTO, 0)
+
+	tx := repo.dbProvider.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return suggestions, repo.translateError(err)
+	}
+
+	// set_config(..., true) is scoped to the transaction
+	if err := tx.Exec(
+		"SELECT set_config('statement_timeout', ?, true), set_config('pg_trgm.word_similarity_threshold', ?, true)",
+		fmt.Sprintf("%d", repo.timeout.Milliseconds()),
+		fmt.Sprintf("%g", threshold),
+	).Error; err != nil {
+		tx.Rollback()
+		return suggestions, repo.translateError(err)
+	}
+
+	err := tx.Raw(`
+		SELECT type, id, name, score FROM (
+		    SELECT 'product' AS type, id, name,
+		           lower(name) LIKE @prefix AS prefix_match,
+		           word_similarity(@query, lower(name)) AS score
+		    FROM products
+		    WHERE deleted_at IS NULL AND (lower(name) LIKE @prefix OR @query <% lower(name))
+		    UNION ALL
+		    SELECT 'brand' AS type, id, name,
+		           lower(name) LIKE @prefix AS prefix_match,
+		           word_similarity(@query, lower(name)) AS score
+		    FROM brands
+		    WHERE deleted_at IS NULL AND (lower(name) LIKE @prefix OR @query <% lower(name))
+		) suggestions
+		ORDER BY prefix_match DESC, score DESC, name ASC
+		LIMIT @limit`,
+		map[string]interface{}{
+			"query":  query,
+			"prefix": escapeLike(query) + "%",
+			"limit":  limit,
+		},
+	).Scan(&suggestions).Error
+	if err != nil {
+		tx.Rollback()
+		return make([]*dto.SuggestionDTO, 0), repo.translateError(err)
+	}
+
+	return suggestions, tx.Commit().Error
+}
+
+func (repo *SearchRepository) translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &pgErr) && pgErr.Code == queryCanceledCode) {
+		repo.logger.Warn(ErrSuggestTimeout.Error(), "timeout", repo.timeout.String())
+		return ErrSuggestTimeout
+	}
+
+	repo.logger.Error(err.Error())
+	return err
+}
+
+// escapeLike escapes the LIKE wildcards so the query only matches literally.
+// Backslash is the default LIKE escape character in Postgres.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
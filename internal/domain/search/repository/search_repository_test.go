@@ -0,0 +1,19 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"galaxy":   "galaxy",
+		"100%":     `100\%`,
+		"usb_c":    `usb\_c`,
+		`c:\temp`:  `c:\\temp`,
+		`50%_off\`: `50\%\_off\\`,
+	}
+
+	for value, want := range tests {
+		if got := escapeLike(value); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
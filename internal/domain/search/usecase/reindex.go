@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	JobUseCase "ecommerce/internal/domain/job/usecase"
+)
+
+// JobReindex rebuilds the search index of a running API.
+const JobReindex = "search.reindex"
+
+// Reindexer is a domain with documents in the search index.
+type Reindexer interface {
+	Reindex() (int, error)
+	PruneIndex() (int, error)
+}
+
+// RunReindex writes the records of every reindexer to the live index and then
+// deletes the documents of records that are gone, so searches keep working
+// while it runs. The progress counts the finished reindexers and a cancelled
+// job stops between them; the job result holds the indexed and deleted
+// documents.
+func RunReindex(ctx context.Context, run *JobUseCase.Run, reindexers ...Reindexer) error {
+	run.SetTotal(len(reindexers))
+
+	indexed, deleted := 0, 0
+	for i, reindexer := range reindexers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		count, err := reindexer.Reindex()
+		if err != nil {
+			return err
+		}
+		indexed += count
+
+		if count, err = reindexer.PruneIndex(); err != nil {
+			return err
+		}
+		deleted += count
+		run.SetProgress(i + 1)
+	}
+	return run.SetResult(map[string]interface{}{"indexed": indexed, "deleted": deleted})
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/search/dto"
+	"ecommerce/internal/domain/search/repository"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+type ISearchUseCase interface {
+	Suggest(payload *dto.SuggestDTO) ([]*dto.SuggestionDTO, error)
+}
+
+type SearchUseCase struct {
+	repository repository.ISearchRepository
+}
+
+func NewSearchUseCase(repository repository.ISearchRepository) *SearchUseCase {
+	return &SearchUseCase{
+		repository: repository,
+	}
+}
+
+// Suggest looks up suggestions for the search box. Queries shorter than
+// SuggestMinQueryLength and lookups that run out of time yield no
+// suggestions rather than an error, the box just stays empty.
+func (uc *SearchUseCase) Suggest(payload *dto.SuggestDTO) ([]*dto.SuggestionDTO, error) {
+	query := strings.Join(strings.Fields(payload.Query), " ")
+	if utf8.RuneCountInString(query) < constants.SuggestMinQueryLength {
+		return make([]*dto.SuggestionDTO, 0), nil
+	}
+
+	limit := payload.Limit
+	if limit == 0 {
+		limit = constants.DefaultSuggestLimit
+	}
+
+	if limit > constants.MaxSuggestLimit {
+		limit = constants.MaxSuggestLimit
+	}
+
+	suggestions, err := uc.repository.Suggest(query, limit, constants.SuggestSimilarityThreshold)
+	if errors.Is(err, repository.ErrSuggestTimeout) {
+		return make([]*dto.SuggestionDTO, 0), nil
+	}
+	if err != nil {
+		return make([]*dto.SuggestionDTO, 0), err
+	}
+
+	return suggestions, nil
+}
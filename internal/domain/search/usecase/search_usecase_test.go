@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"ecommerce/constants"
+	"ecommerce/internal/domain/search/dto"
+	"ecommerce/internal/domain/search/repository"
+	"errors"
+	"testing"
+)
+
+// suggester answers every lookup with err and remembers what it was asked.
+type suggester struct {
+	calls int
+	query string
+	limit int
+	err   error
+}
+
+func (s *suggester) Suggest(query string, limit int, threshold float64) ([]*dto.SuggestionDTO, error) {
+	s.calls++
+	s.query, s.limit = query, limit
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []*dto.SuggestionDTO{{Type: "product", ID: 1, Name: "Galaxy S24"}}, nil
+}
+
+func TestSuggest(t *testing.T) {
+	failure := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		payload   dto.SuggestDTO
+		err       error
+		wantQuery string
+		wantLimit int
+		wantCount int
+		wantErr   error
+	}{
+		{name: "default limit", payload: dto.SuggestDTO{Query: "gal"}, wantQuery: "gal", wantLimit: constants.DefaultSuggestLimit, wantCount: 1},
+		{name: "limit capped", payload: dto.SuggestDTO{Query: "gal", Limit: 500}, wantQuery: "gal", wantLimit: constants.MaxSuggestLimit, wantCount: 1},
+		{name: "spaces collapsed", payload: dto.SuggestDTO{Query: "  galaxy \t s2 ", Limit: 3}, wantQuery: "galaxy s2", wantLimit: 3, wantCount: 1},
+		{name: "too short", payload: dto.SuggestDTO{Query: " g "}},
+		{name: "timeout is empty", payload: dto.SuggestDTO{Query: "gal"}, err: repository.ErrSuggestTimeout, wantQuery: "gal", wantLimit: constants.DefaultSuggestLimit},
+		{name: "other errors surface", payload: dto.SuggestDTO{Query: "gal"}, err: failure, wantQuery: "gal", wantLimit: constants.DefaultSuggestLimit, wantErr: failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &suggester{err: tt.err}
+			suggestions, err := NewSearchUseCase(repo).Suggest(&tt.payload)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Suggest() error = %v, want %v", err, tt.wantErr)
+			}
+			if suggestions == nil {
+				t.Error("Suggest() returned nil suggestions")
+			}
+			if len(suggestions) != tt.wantCount {
+				t.Errorf("Suggest() returned %d suggestions, want %d", len(suggestions), tt.wantCount)
+			}
+			if tt.wantQuery == "" {
+				if repo.calls != 0 {
+					t.Errorf("looked up %q, want no lookup", repo.query)
+				}
+				return
+			}
+			if repo.query != tt.wantQuery || repo.limit != tt.wantLimit {
+				t.Errorf("looked up %q with limit %d, want %q with limit %d", repo.query, repo.limit, tt.wantQuery, tt.wantLimit)
+			}
+		})
+	}
+}
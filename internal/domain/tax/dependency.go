@@ -0,0 +1,31 @@
+package tax
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/tax/presenter"
+	TaxRepository "ecommerce/internal/domain/tax/repository"
+	TaxUseCase "ecommerce/internal/domain/tax/usecase"
+	"log/slog"
+)
+
+func NewTaxDependency(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) presenter.ITaxPresenter {
+	repository := TaxRepository.NewTaxRepository(ctx, dbProvider, logger)
+	useCase := TaxUseCase.NewTaxUseCase(repository)
+	return presenter.NewTaxPresenter(useCase)
+}
+
+// NewTaxCalculator builds the calculator shared by the domains that render
+// prices, configured from the application settings.
+func NewTaxCalculator(
+	ctx context.Context,
+	dbProvider *config.DatabaseConfiguration,
+	logger *slog.Logger,
+) TaxUseCase.ITaxCalculator {
+	repository := TaxRepository.NewTaxRepository(ctx, dbProvider, logger)
+	return TaxUseCase.NewTaxCalculator(repository, config.AppConfig.TaxDefaultRegion, config.AppConfig.PricesIncludeTax)
+}
@@ -0,0 +1,41 @@
+package dto
+
+type CreateTaxClassDTO struct {
+	Code string `json:"code" validate:"required,max=50"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type UpsertTaxRateDTO struct {
+	TaxClassId int64   `json:"tax_class_id" swaggerignore:"true"`
+	Region     string  `json:"region" swaggerignore:"true" validate:"required,max=10"`
+	Rate       float64 `json:"rate" validate:"gte=0,lt=1"`
+}
+
+type TaxClassWithIdDTO struct {
+	ID int64 `json:"id" form:"id" param:"id" query:"id"`
+}
+
+type FindTaxRateDTO struct {
+	Region string  `json:"region"`
+	Rate   float64 `json:"rate"`
+}
+
+type FindTaxClassDTO struct {
+	ID        int64             `json:"id"`
+	Code      string            `json:"code"`
+	Name      string            `json:"name"`
+	Rates     []*FindTaxRateDTO `json:"rates"`
+	CreatedAt string            `json:"created_at"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+// TaxBreakdownDTO is the result of a tax calculation. Amounts are in the
+// smallest currency unit and always satisfy Net + Tax = Gross.
+type TaxBreakdownDTO struct {
+	Net      int64   `json:"net"`
+	Tax      int64   `json:"tax"`
+	Gross    int64   `json:"gross"`
+	Rate     float64 `json:"rate"`
+	Region   string  `json:"region"`
+	TaxClass string  `json:"tax_class,omitempty"`
+}
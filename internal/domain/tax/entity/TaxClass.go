@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type TaxClass struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+	Code      string
+	Name      string
+	Rates     []TaxRate `gorm:"foreignKey:TaxClassId"`
+}
+
+func (TaxClass) TableName() string {
+	return "tax_classes"
+}
+
+func (t *TaxClass) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	return nil
+}
+
+func (t *TaxClass) BeforeUpdate(tx *gorm.DB) error {
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// TaxRate is the rate of a tax class in one region, stored as a fraction
+// (0.11 for 11%).
+type TaxRate struct {
+	ID         uint `gorm:"primary_key"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	TaxClassId uint
+	Region     string
+	Rate       float64
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+func (t *TaxRate) BeforeCreate(tx *gorm.DB) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	return nil
+}
+
+func (t *TaxRate) BeforeUpdate(tx *gorm.DB) error {
+	t.UpdatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,115 @@
+package presenter
+
+import (
+	"ecommerce/internal/domain/tax/dto"
+	"ecommerce/internal/domain/tax/usecase"
+	"ecommerce/pkg/apperror"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+
+	HttpResponser "ecommerce/pkg/response"
+)
+
+type ITaxPresenter interface {
+	GetAll(c echo.Context) error
+	Create(c echo.Context) error
+	UpsertRate(c echo.Context) error
+}
+
+type TaxPresenter struct {
+	useCase usecase.ITaxUseCase
+}
+
+func NewTaxPresenter(useCase usecase.ITaxUseCase) *TaxPresenter {
+	return &TaxPresenter{
+		useCase: useCase,
+	}
+}
+
+// GetAll godoc
+// @Summary      Get All tax class
+// @Description  Get All tax classes with their regional rates
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  response.SuccessResponse{data=[]dto.FindTaxClassDTO}
+// @Router       /tax-classes [get]
+func (presenter *TaxPresenter) GetAll(c echo.Context) error {
+	taxClasses, err := presenter.useCase.FindAll()
+	if err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Get tax classes success", taxClasses)
+}
+
+// Create godoc
+// @Summary      Create tax class
+// @Description  Create new tax class
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 request body dto.CreateTaxClassDTO true "request body"
+// @Success      201  {object}  response.SuccessResponse{data=nil}
+// @Router       /tax-classes [post]
+func (presenter *TaxPresenter) Create(c echo.Context) error {
+	payload := dto.CreateTaxClassDTO{}
+	if err := c.Bind(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := c.Validate(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := presenter.useCase.CreateTaxClass(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusCreated, "Tax class created", nil)
+}
+
+// UpsertRate godoc
+// @Summary      Set tax rate
+// @Description  Create or replace the rate of a tax class in a region
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path int true "tax class id"
+// @Param 		 region path string true "region code"
+// @Param 		 request body dto.UpsertTaxRateDTO true "request body"
+// @Success      200  {object}  response.SuccessResponse{data=nil}
+// @Router       /tax-classes/{id}/rates/{region} [put]
+func (presenter *TaxPresenter) UpsertRate(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.Logger().Error(err)
+		return apperror.Invalid(err)
+	}
+
+	payload := dto.UpsertTaxRateDTO{}
+	if err := c.Bind(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	payload.TaxClassId = id
+	payload.Region = c.Param("region")
+
+	if err := c.Validate(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	if err := presenter.useCase.UpsertTaxRate(&payload); err != nil {
+		c.Logger().Error(err)
+		return err
+	}
+
+	return HttpResponser.Success(c, http.StatusOK, "Tax rate saved", nil)
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"ecommerce/config"
+	"ecommerce/internal/domain/tax/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log/slog"
+)
+
+//go:generate mockgen -source=tax_repository.go -destination=mocks/tax_repository_mock.go -package=mocks
+type ITaxRepository interface {
+	FindAll() ([]*entity.TaxClass, error)
+	FindById(id uint) (*entity.TaxClass, error)
+	FindByIds(ids []uint) ([]*entity.TaxClass, error)
+	Create(taxClass *entity.TaxClass) error
+	UpsertRate(rate *entity.TaxRate) error
+}
+
+type TaxRepository struct {
+	dbProvider *config.DatabaseConfiguration
+	ctx        context.Context
+	logger     *slog.Logger
+}
+
+func NewTaxRepository(ctx context.Context, dbProvider *config.DatabaseConfiguration, logger *slog.Logger) *TaxRepository {
+	return &TaxRepository{
+		dbProvider: dbProvider,
+		ctx:        ctx,
+		logger:     logger,
+	}
+}
+
+func (repo *TaxRepository) FindAll() ([]*entity.TaxClass, error) {
+	taxClasses := make([]*entity.TaxClass, 0)
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		Preload("Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("region asc")
+		}).
+		Order("code asc").
+		Find(&taxClasses).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return make([]*entity.TaxClass, 0), err
+	}
+	return taxClasses, nil
+}
+
+func (repo *TaxRepository) FindById(id uint) (*entity.TaxClass, error) {
+	var taxClass *entity.TaxClass
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		Preload("Rates", func(db *gorm.DB) *gorm.DB {
+			return db.Order("region asc")
+		}).
+		First(&taxClass, "id = ?", id).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return nil, err
+	}
+	return taxClass, nil
+}
+
+// FindByIds loads the tax classes with the given ids and their rates; ids
+// without a class are left out.
+func (repo *TaxRepository) FindByIds(ids []uint) ([]*entity.TaxClass, error) {
+	taxClasses := make([]*entity.TaxClass, 0, len(ids))
+	if len(ids) == 0 {
+		return taxClasses, nil
+	}
+
+	if err := repo.dbProvider.WithContext(repo.ctx).
+		Preload("Rates").
+		Where("id IN ?", ids).
+		Find(&taxClasses).Error; err != nil {
+		repo.logger.Error(err.Error())
+		return make([]*entity.TaxClass, 0), err
+	}
+	return taxClasses, nil
+}
+
+func (repo *TaxRepository) Create(taxClass *entity.TaxClass) error {
+	tx := repo.dbProvider.Begin()
+	if err := tx.WithContext(repo.ctx).Create(taxClass).Error; err != nil {
+		tx.Rollback()
+		repo.logger.Error(err.Error())
+		return err
+	}
+	tx.Commit()
+	return nil
+}
+
+func (repo *TaxRepository) UpsertRate(rate *entity.TaxRate) error {
+	tx := repo.dbProvider.Begin()
+	if err := tx.WithContext(repo.ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tax_class_id"}, {Name: "region"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error; err != nil {
+		tx.Rollback()
+		repo.logger.Error(err.Error())
+		return err
+	}
+	tx.Commit()
+	return nil
+}
@@ -0,0 +1,5 @@
+package usecase
+
+import "ecommerce/pkg/apperror"
+
+var ErrTaxClassNotFound = apperror.NotFound("tax_class_not_found", "tax class not found")
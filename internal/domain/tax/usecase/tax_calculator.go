@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/tax/dto"
+	"ecommerce/internal/domain/tax/entity"
+	"ecommerce/internal/domain/tax/repository"
+	"math"
+	"strings"
+)
+
+// ITaxCalculator splits an amount into its net, tax and gross parts.
+type ITaxCalculator interface {
+	DefaultRegion() string
+	LoadClasses(taxClassIds []uint) (TaxClasses, error)
+	Calculate(amount int64, taxClassId *uint, region string, classes TaxClasses) *dto.TaxBreakdownDTO
+}
+
+// TaxClasses are the tax classes, with their rates, that amounts are
+// calculated with, keyed by id. Loading the classes of a whole page at once
+// spares a lookup per product.
+type TaxClasses map[uint]*entity.TaxClass
+
+// TaxCalculator resolves the rate of a tax class in a region and applies it to
+// amounts that are either tax inclusive (gross) or exclusive (net), depending
+// on how prices are stored.
+type TaxCalculator struct {
+	repository       repository.ITaxRepository
+	defaultRegion    string
+	pricesIncludeTax bool
+}
+
+func NewTaxCalculator(repository repository.ITaxRepository, defaultRegion string, pricesIncludeTax bool) *TaxCalculator {
+	return &TaxCalculator{
+		repository:       repository,
+		defaultRegion:    strings.ToUpper(defaultRegion),
+		pricesIncludeTax: pricesIncludeTax,
+	}
+}
+
+func (c *TaxCalculator) DefaultRegion() string {
+	return c.defaultRegion
+}
+
+// LoadClasses loads the tax classes with the given ids and all their rates in
+// one query; duplicate ids are fine.
+func (c *TaxCalculator) LoadClasses(taxClassIds []uint) (TaxClasses, error) {
+	unique := make([]uint, 0, len(taxClassIds))
+	seen := make(map[uint]bool, len(taxClassIds))
+	for _, id := range taxClassIds {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	taxClasses, err := c.repository.FindByIds(unique)
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(TaxClasses, len(taxClasses))
+	for _, taxClass := range taxClasses {
+		classes[taxClass.ID] = taxClass
+	}
+	return classes, nil
+}
+
+// Calculate applies the rate for the tax class in region to amount, looking
+// the class up in classes. Products without a tax class, or classes without a
+// rate in the region, are untaxed. The tax part is rounded half up to the
+// smallest currency unit and the other part is derived from it so the three
+// amounts always add up.
+func (c *TaxCalculator) Calculate(amount int64, taxClassId *uint, region string, classes TaxClasses) *dto.TaxBreakdownDTO {
+	if region == "" {
+		region = c.defaultRegion
+	}
+	region = strings.ToUpper(region)
+
+	breakdown := &dto.TaxBreakdownDTO{
+		Net:    amount,
+		Gross:  amount,
+		Region: region,
+	}
+
+	if taxClassId == nil || classes[*taxClassId] == nil {
+		return breakdown
+	}
+
+	taxClass := classes[*taxClassId]
+	breakdown.TaxClass = taxClass.Code
+
+	var rate float64
+	for _, taxRate := range taxClass.Rates {
+		if taxRate.Region == region {
+			rate = taxRate.Rate
+			break
+		}
+	}
+
+	if rate == 0 {
+		return breakdown
+	}
+
+	breakdown.Rate = rate
+	if c.pricesIncludeTax {
+		breakdown.Tax = int64(math.Round(float64(amount) * rate / (1 + rate)))
+		breakdown.Net = amount - breakdown.Tax
+	} else {
+		breakdown.Tax = int64(math.Round(float64(amount) * rate))
+		breakdown.Gross = amount + breakdown.Tax
+	}
+
+	return breakdown
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/tax/dto"
+	"ecommerce/internal/domain/tax/entity"
+	"reflect"
+	"testing"
+)
+
+func TestTaxCalculatorCalculate(t *testing.T) {
+	vat := uint(1)
+	missing := uint(9)
+	classes := TaxClasses{
+		vat: {ID: vat, Code: "VAT", Rates: []entity.TaxRate{{Region: "ID", Rate: 0.11}, {Region: "SG", Rate: 0.09}}},
+	}
+
+	tests := []struct {
+		name             string
+		pricesIncludeTax bool
+		amount           int64
+		taxClassId       *uint
+		region           string
+		want             *dto.TaxBreakdownDTO
+	}{
+		{
+			name:       "net prices",
+			amount:     10000,
+			taxClassId: &vat,
+			want:       &dto.TaxBreakdownDTO{Net: 10000, Tax: 1100, Gross: 11100, Rate: 0.11, Region: "ID", TaxClass: "VAT"},
+		},
+		{
+			name:             "gross prices",
+			pricesIncludeTax: true,
+			amount:           11100,
+			taxClassId:       &vat,
+			want:             &dto.TaxBreakdownDTO{Net: 10000, Tax: 1100, Gross: 11100, Rate: 0.11, Region: "ID", TaxClass: "VAT"},
+		},
+		{
+			name:       "tax rounds half up",
+			amount:     150,
+			taxClassId: &vat,
+			region:     "sg",
+			want:       &dto.TaxBreakdownDTO{Net: 150, Tax: 14, Gross: 164, Rate: 0.09, Region: "SG", TaxClass: "VAT"},
+		},
+		{
+			name:       "no rate in region",
+			amount:     10000,
+			taxClassId: &vat,
+			region:     "MY",
+			want:       &dto.TaxBreakdownDTO{Net: 10000, Gross: 10000, Region: "MY", TaxClass: "VAT"},
+		},
+		{
+			name:   "no tax class",
+			amount: 10000,
+			want:   &dto.TaxBreakdownDTO{Net: 10000, Gross: 10000, Region: "ID"},
+		},
+		{
+			name:       "unknown tax class",
+			amount:     10000,
+			taxClassId: &missing,
+			want:       &dto.TaxBreakdownDTO{Net: 10000, Gross: 10000, Region: "ID"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calculator := NewTaxCalculator(nil, "id", tt.pricesIncludeTax)
+			got := calculator.Calculate(tt.amount, tt.taxClassId, tt.region, classes)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Calculate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
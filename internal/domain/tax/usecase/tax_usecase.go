@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"ecommerce/internal/domain/tax/dto"
+	"ecommerce/internal/domain/tax/entity"
+	"ecommerce/internal/domain/tax/repository"
+	"ecommerce/pkg/apperror"
+	"strings"
+)
+
+type ITaxUseCase interface {
+	FindAll() ([]*dto.FindTaxClassDTO, error)
+	CreateTaxClass(payload *dto.CreateTaxClassDTO) error
+	UpsertTaxRate(payload *dto.UpsertTaxRateDTO) error
+}
+
+type TaxUseCase struct {
+	repository repository.ITaxRepository
+}
+
+func NewTaxUseCase(repository repository.ITaxRepository) *TaxUseCase {
+	return &TaxUseCase{
+		repository: repository,
+	}
+}
+
+func (uc *TaxUseCase) FindAll() ([]*dto.FindTaxClassDTO, error) {
+	taxClasses, err := uc.repository.FindAll()
+	if err != nil {
+		return make([]*dto.FindTaxClassDTO, 0), err
+	}
+
+	taxClassesDto := make([]*dto.FindTaxClassDTO, 0, len(taxClasses))
+	for _, t := range taxClasses {
+		rates := make([]*dto.FindTaxRateDTO, 0, len(t.Rates))
+		for _, r := range t.Rates {
+			rates = append(rates, &dto.FindTaxRateDTO{
+				Region: r.Region,
+				Rate:   r.Rate,
+			})
+		}
+
+		taxClassesDto = append(taxClassesDto, &dto.FindTaxClassDTO{
+			ID:        int64(t.ID),
+			Code:      t.Code,
+			Name:      t.Name,
+			Rates:     rates,
+			CreatedAt: t.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt: t.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return taxClassesDto, nil
+}
+
+func (uc *TaxUseCase) CreateTaxClass(payload *dto.CreateTaxClassDTO) error {
+	taxClass := &entity.TaxClass{
+		Code: strings.ToLower(strings.TrimSpace(payload.Code)),
+		Name: strings.TrimSpace(payload.Name),
+	}
+
+	return uc.repository.Create(taxClass)
+}
+
+func (uc *TaxUseCase) UpsertTaxRate(payload *dto.UpsertTaxRateDTO) error {
+	taxClass, err := uc.repository.FindById(uint(payload.TaxClassId))
+	if err != nil {
+		return apperror.RecordNotFound(err, ErrTaxClassNotFound)
+	}
+
+	rate := &entity.TaxRate{
+		TaxClassId: taxClass.ID,
+		Region:     strings.ToUpper(strings.TrimSpace(payload.Region)),
+		Rate:       payload.Rate,
+	}
+
+	return uc.repository.UpsertRate(rate)
+}
@@ -0,0 +1,161 @@
+// Package apiversion serves several versions of the API side by side: it tells
+// handlers which version a request was routed to, routes unversioned requests
+// by their Accept header and marks the responses of deprecated versions.
+package apiversion
+
+import (
+	"ecommerce/pkg/apperror"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	V1     = "v1"
+	V2     = "v2"
+	Latest = V2
+
+	HeaderAPIVersion  = "API-Version"
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+
+	contextKey = "api_version"
+)
+
+// ErrUnsupportedVersion is returned when Accept asks for a version that is
+// not served.
+var ErrUnsupportedVersion = apperror.New(apperror.KindNotAcceptable, "unsupported_api_version", "requested API version is not supported")
+
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.ecommerce\.(v\d+)\+json$`)
+
+// Use marks the requests of a route group as served by version.
+func Use(version string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(contextKey, version)
+			c.Response().Header().Set(HeaderAPIVersion, version)
+			return next(c)
+		}
+	}
+}
+
+// FromContext returns the version a request was routed to, V1 for routes
+// outside a versioned group.
+func FromContext(c echo.Context) string {
+	if version, ok := c.Get(contextKey).(string); ok {
+		return version
+	}
+	return V1
+}
+
+// FromAccept returns the version an Accept header asks for, either with the
+// vendor media type, e.g. application/vnd.ecommerce.v2+json, or with the
+// version param of application/json, e.g. application/json; version=2. It
+// returns an empty string when the header names no version.
+func FromAccept(accept string) string {
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		if match := vendorMediaType.FindStringSubmatch(mediaType); match != nil {
+			return match[1]
+		}
+		if version := params["version"]; version != "" && mediaType == echo.MIMEApplicationJSON {
+			return "v" + strings.TrimPrefix(version, "v")
+		}
+	}
+	return ""
+}
+
+// Negotiate routes the requests under prefix that do not name a version in
+// their path to the version their Accept header asks for, or to fallback. A
+// version in the path always wins over Accept. It rewrites the path, so it
+// must run before routing, i.e. be registered with Echo.Pre.
+func Negotiate(prefix string, versions []string, fallback string) echo.MiddlewareFunc {
+	served := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		served[version] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			rest, ok := strings.CutPrefix(request.URL.Path, prefix+"/")
+			if !ok || served[strings.SplitN(rest, "/", 2)[0]] {
+				return next(c)
+			}
+
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+			version := FromAccept(request.Header.Get(echo.HeaderAccept))
+			if version == "" {
+				version = fallback
+			} else if !served[version] {
+				return ErrUnsupportedVersion.WithDetails(map[string]interface{}{"supported_versions": versions})
+			}
+
+			request.URL.Path = prefix + "/" + version + "/" + rest
+			if request.URL.RawPath != "" {
+				request.URL.RawPath = prefix + "/" + version + "/" + strings.TrimPrefix(request.URL.RawPath, prefix+"/")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Deprecate marks the responses of a deprecated version with the Deprecation
+// header and, once a removal date is set, the Sunset header. The Link header
+// points at the same resource in successor.
+func Deprecate(version string, successor string, deprecatedAt time.Time, sunset time.Time) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(HeaderDeprecation, fmt.Sprintf("@%d", deprecatedAt.Unix()))
+			if !sunset.IsZero() {
+				header.Set(HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+
+			path := strings.Replace(c.Request().URL.Path, "/"+version+"/", "/"+successor+"/", 1)
+			header.Add(HeaderLink, fmt.Sprintf(`<%s>; rel="successor-version"`, path))
+			return next(c)
+		}
+	}
+}
+
+// RenameParams lets a version keep accepting query params under the names it
+// was published with: each legacy param is copied to its current name unless
+// the request already sends that one, so handlers only read current names.
+func RenameParams(names map[string]string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			request := c.Request()
+			query := request.URL.Query()
+			renamed := false
+			for legacy, current := range names {
+				if values, ok := query[legacy]; ok {
+					if !query.Has(current) {
+						query[current] = values
+					}
+					delete(query, legacy)
+					renamed = true
+				}
+			}
+
+			if renamed {
+				request.URL.RawQuery = query.Encode()
+			}
+			return next(c)
+		}
+	}
+}
+
+// Path returns path under the prefix of the version a request was routed to,
+// e.g. /api/v2/jobs/1 for /jobs/1.
+func Path(c echo.Context, prefix string, path string) string {
+	return prefix + "/" + FromContext(c) + path
+}
@@ -0,0 +1,184 @@
+package apiversion
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFromAccept(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: ""},
+		{accept: "application/json", want: ""},
+		{accept: "application/vnd.ecommerce.v1+json", want: V1},
+		{accept: "application/vnd.ecommerce.v2+json; charset=utf-8", want: V2},
+		{accept: "application/json; version=2", want: V2},
+		{accept: "application/json; version=v1", want: V1},
+		{accept: "text/html, application/vnd.ecommerce.v3+json", want: "v3"},
+		{accept: "text/html; version=2", want: ""},
+		{accept: "application/vnd.other.v2+json", want: ""},
+		{accept: "not a media type;;", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := FromAccept(tt.accept); got != tt.want {
+			t.Errorf("FromAccept(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		wantPath string
+		wantErr  error
+		wantVary bool
+	}{
+		{name: "unversioned falls back", path: "/api/products", wantPath: "/api/v1/products", wantVary: true},
+		{name: "accept picks the version", path: "/api/products/1", accept: "application/vnd.ecommerce.v2+json", wantPath: "/api/v2/products/1", wantVary: true},
+		{name: "path wins over accept", path: "/api/v1/products", accept: "application/vnd.ecommerce.v2+json", wantPath: "/api/v1/products"},
+		{name: "outside the prefix", path: "/health", accept: "application/vnd.ecommerce.v2+json", wantPath: "/health"},
+		{name: "unsupported version", path: "/api/products", accept: "application/json; version=9", wantErr: ErrUnsupportedVersion, wantVary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				request.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			recorder := httptest.NewRecorder()
+			c := echo.New().NewContext(request, recorder)
+
+			gotPath := ""
+			err := Negotiate("/api", []string{V1, V2}, V1)(func(c echo.Context) error {
+				gotPath = c.Request().URL.Path
+				return nil
+			})(c)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Negotiate() error = %v, want %v", err, tt.wantErr)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("path = %q, want %q", gotPath, tt.wantPath)
+			}
+			if got := recorder.Header().Get(echo.HeaderVary) == echo.HeaderAccept; got != tt.wantVary {
+				t.Errorf("Vary: Accept = %v, want %v", got, tt.wantVary)
+			}
+		})
+	}
+}
+
+func TestDeprecate(t *testing.T) {
+	deprecatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		sunset     time.Time
+		wantSunset string
+	}{
+		{name: "without sunset"},
+		{name: "with sunset", sunset: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), wantSunset: "Wed, 31 Dec 2025 00:00:00 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/products/1", nil)
+			recorder := httptest.NewRecorder()
+			c := echo.New().NewContext(request, recorder)
+
+			err := Deprecate(V1, V2, deprecatedAt, tt.sunset)(func(c echo.Context) error { return nil })(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			header := recorder.Header()
+			if got := header.Get(HeaderDeprecation); got != "@1735689600" {
+				t.Errorf("%s = %q, want @1735689600", HeaderDeprecation, got)
+			}
+			if got := header.Get(HeaderSunset); got != tt.wantSunset {
+				t.Errorf("%s = %q, want %q", HeaderSunset, got, tt.wantSunset)
+			}
+			if got := header.Get(HeaderLink); got != `</api/v2/products/1>; rel="successor-version"` {
+				t.Errorf("%s = %q", HeaderLink, got)
+			}
+		})
+	}
+}
+
+func TestRenameParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "legacy name", query: "PerPage=10&page=1", want: "page=1&per_page=10"},
+		{name: "current name wins", query: "PerPage=10&per_page=20", want: "per_page=20"},
+		{name: "nothing to rename", query: "page=1", want: "page=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/products?"+tt.query, nil)
+			c := echo.New().NewContext(request, httptest.NewRecorder())
+
+			got := ""
+			err := RenameParams(map[string]string{"PerPage": "per_page"})(func(c echo.Context) error {
+				got = c.Request().URL.RawQuery
+				return nil
+			})(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("query = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    string
+	}{
+		{name: "unversioned route", want: V1},
+		{name: "versioned route", version: V2, want: V2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), recorder)
+
+			handler := func(c echo.Context) error {
+				if got := FromContext(c); got != tt.want {
+					t.Errorf("FromContext() = %q, want %q", got, tt.want)
+				}
+				if got := Path(c, "/api", "/jobs/1"); got != "/api/"+tt.want+"/jobs/1" {
+					t.Errorf("Path() = %q", got)
+				}
+				return nil
+			}
+
+			if tt.version != "" {
+				handler = Use(tt.version)(handler)
+			}
+			if err := handler(c); err != nil {
+				t.Fatal(err)
+			}
+			if tt.version != "" && recorder.Header().Get(HeaderAPIVersion) != tt.version {
+				t.Errorf("%s = %q, want %q", HeaderAPIVersion, recorder.Header().Get(HeaderAPIVersion), tt.version)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Package apperror is the error taxonomy shared by the usecases and the HTTP
+// layer. An Error has a Kind, which decides the response status, and a stable
+// Code clients can branch on instead of the message.
+package apperror
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindGone
+	KindPreconditionFailed
+	KindUnprocessable
+	KindPreconditionRequired
+	KindUnavailable
+	KindNotAcceptable
+)
+
+// Codes of the errors that are not specific to one resource.
+const (
+	CodeInternal          = "internal_error"
+	CodeInvalidRequest    = "invalid_request"
+	CodeValidationFailed  = "validation_failed"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeReferenceNotFound = "reference_not_found"
+	CodeStillReferenced   = "still_referenced"
+	CodeInvalidValue      = "invalid_value"
+	CodeConcurrentUpdate  = "concurrent_update"
+)
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	details map[string]interface{}
+	err     error
+}
+
+func New(kind Kind, code string, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func Validation(code string, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Forbidden(code string, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code string, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code string, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Gone(code string, message string) *Error {
+	return New(KindGone, code, message)
+}
+
+func Unprocessable(code string, message string) *Error {
+	return New(KindUnprocessable, code, message)
+}
+
+func Unavailable(code string, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+// Invalid reports a malformed request, such as a param that does not parse.
+func Invalid(err error) *Error {
+	return Validation(CodeInvalidRequest, err.Error()).Wrap(err)
+}
+
+// InvalidFields reports a request that failed validation; errors holds the
+// messages keyed by field or param.
+func InvalidFields(errors interface{}) *Error {
+	return Validation(CodeValidationFailed, "request validation failed").WithErrors(errors)
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Is matches errors of the same kind and code, so that a sentinel matches
+// the copies WithDetails and Wrap make of it.
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+	return ok && other.Kind == e.Kind && other.Code == e.Code
+}
+
+// Details returns the members rendered next to the message, such as the id
+// of the conflicting resource.
+func (e *Error) Details() map[string]interface{} {
+	return e.details
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	clone := *e
+	clone.details = details
+	return &clone
+}
+
+// WithErrors returns a copy of e carrying field errors.
+func (e *Error) WithErrors(errors interface{}) *Error {
+	return e.WithDetails(map[string]interface{}{"errors": errors})
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.err = err
+	return &clone
+}
@@ -0,0 +1,61 @@
+package apperror
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// SQLSTATE codes of the constraint and data errors translated to client
+// errors.
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	notNullViolation     = "23502"
+	checkViolation       = "23514"
+	stringTooLong        = "22001"
+	numericOutOfRange    = "22003"
+	invalidTextValue     = "22P02"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// RecordNotFound returns notFound in place of a missing record error, so
+// that the usecase names what was not found, and err otherwise.
+func RecordNotFound(err error, notFound *Error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound.Wrap(err)
+	}
+	return err
+}
+
+// FromDatabase translates a missing record or a Postgres constraint or data
+// error into an Error. The messages never quote the SQL or the row; nil is
+// returned for other errors.
+func FromDatabase(err error) *Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound(CodeNotFound, "resource not found").Wrap(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation:
+		return Conflict(CodeAlreadyExists, "resource already exists").Wrap(err)
+	case foreignKeyViolation:
+		// the detail tells a missing referenced row from a row still in use
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return Conflict(CodeStillReferenced, "resource is still referenced by other resources").Wrap(err)
+		}
+		return Unprocessable(CodeReferenceNotFound, "a referenced resource does not exist").Wrap(err)
+	case notNullViolation, checkViolation, stringTooLong, numericOutOfRange, invalidTextValue:
+		return Validation(CodeInvalidValue, "a value is missing, out of range or malformed").Wrap(err)
+	case serializationFailure, deadlockDetected:
+		return Conflict(CodeConcurrentUpdate, "resource was changed by a concurrent request, retry").Wrap(err)
+	}
+	return nil
+}
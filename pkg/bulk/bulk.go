@@ -0,0 +1,143 @@
+package bulk
+
+import (
+	"ecommerce/pkg/apperror"
+	"encoding/json"
+	"errors"
+)
+
+// Modes a bulk request runs in. Atomic applies every operation or none of
+// them; best effort applies each operation that succeeds on its own.
+const (
+	ModeAtomic     = "atomic"
+	ModeBestEffort = "best_effort"
+)
+
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Statuses of an operation. Rolled back operations succeeded but were undone
+// with the rest of an atomic request; skipped operations never ran.
+const (
+	StatusSucceeded  = "succeeded"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back"
+	StatusSkipped    = "skipped"
+	// StatusValid marks an operation that passed validation in a dry run.
+	StatusValid = "valid"
+)
+
+// Request is the body of a bulk endpoint. Data carries the same payload as
+// the single create or update endpoint and is decoded per resource; each
+// operation is validated on its own so errors are reported per item.
+type Request struct {
+	Mode       string       `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []*Operation `json:"operations" validate:"required,min=1,max=1000"`
+}
+
+type Operation struct {
+	Op   string          `json:"op" validate:"required,oneof=create update delete"`
+	ID   int64           `json:"id" validate:"required_unless=Op create"`
+	Data json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// Result is the outcome of one operation, in request order. ID is the id of
+// the created, updated or deleted record and Line the source line of an
+// imported row.
+type Result struct {
+	Index  int         `json:"index"`
+	Line   int         `json:"line,omitempty"`
+	Op     string      `json:"op"`
+	ID     *int64      `json:"id"`
+	Status string      `json:"status"`
+	Errors interface{} `json:"errors,omitempty"`
+}
+
+type Report struct {
+	Mode      string    `json:"mode"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+	Results   []*Result `json:"results"`
+}
+
+// NewReport starts a report with every operation skipped.
+func NewReport(request *Request) *Report {
+	report := &Report{
+		Mode:    request.Mode,
+		Results: make([]*Result, 0, len(request.Operations)),
+	}
+
+	for i, op := range request.Operations {
+		result := &Result{Index: i, Op: op.Op, Status: StatusSkipped}
+		if op.ID != 0 {
+			id := op.ID
+			result.ID = &id
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Reject marks an operation failed with validation errors before it ran.
+func (r *Report) Reject(index int, errs interface{}) {
+	r.Results[index].Status = StatusFailed
+	r.Results[index].Errors = errs
+	r.Failed++
+}
+
+// Record stores the outcome of an operation that ran.
+func (r *Report) Record(index int, id int64, err error) {
+	result := r.Results[index]
+	if id != 0 {
+		result.ID = &id
+	}
+
+	if err != nil {
+		result.Status = StatusFailed
+		result.Errors = operationErrors(err)
+		r.Failed++
+		return
+	}
+
+	result.Status = StatusSucceeded
+	r.Succeeded++
+}
+
+// RollBack reports an atomic request that was undone: operations that
+// succeeded are rolled back and the ones that did not run stay skipped.
+func (r *Report) RollBack() {
+	for _, result := range r.Results {
+		if result.Status == StatusSucceeded {
+			result.Status = StatusRolledBack
+		}
+	}
+	r.Succeeded = 0
+}
+
+// operationErrors renders the error of an operation the way the error
+// handler renders the error of a request: the code and message of a domain
+// or database error and its details, never the text of an unknown error.
+func operationErrors(err error) map[string]interface{} {
+	var appErr *apperror.Error
+	var message string
+	if errors.As(err, &appErr) {
+		// a domain error may be wrapped with more context for the client
+		message = err.Error()
+		if appErr.Kind == apperror.KindInternal {
+			message = appErr.Message
+		}
+	} else if appErr = apperror.FromDatabase(err); appErr != nil {
+		message = appErr.Message
+	} else {
+		return map[string]interface{}{"code": apperror.CodeInternal, "message": "operation failed"}
+	}
+
+	errs := map[string]interface{}{"code": appErr.Code, "message": message}
+	for key, value := range appErr.Details() {
+		errs[key] = value
+	}
+	return errs
+}
@@ -0,0 +1,65 @@
+package bulk
+
+import (
+	"ecommerce/pkg/apperror"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"reflect"
+	"testing"
+)
+
+func TestRecordErrors(t *testing.T) {
+	foreignKey := &pgconn.PgError{
+		Code:    "23503",
+		Message: `insert or update on table "products" violates foreign key constraint "fk_products_brand"`,
+		Detail:  `Key (brand_id)=(99) is not present in table "brands".`,
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want map[string]interface{}
+	}{
+		{
+			name: "database error",
+			err:  foreignKey,
+			want: map[string]interface{}{"code": apperror.CodeReferenceNotFound, "message": "a referenced resource does not exist"},
+		},
+		{
+			name: "wrapped database error",
+			err:  fmt.Errorf("create product: %w", foreignKey),
+			want: map[string]interface{}{"code": apperror.CodeReferenceNotFound, "message": "a referenced resource does not exist"},
+		},
+		{
+			name: "domain error with details",
+			err:  apperror.Conflict("barcode_taken", "product barcode already exists").WithDetails(map[string]interface{}{"existing_id": 7}),
+			want: map[string]interface{}{"code": "barcode_taken", "message": "product barcode already exists", "existing_id": 7},
+		},
+		{
+			name: "domain error wrapped with context",
+			err:  fmt.Errorf("%w: path %q does not exist", apperror.Unprocessable("patch_not_applicable", "patch cannot be applied"), "/tags/3"),
+			want: map[string]interface{}{"code": "patch_not_applicable", "message": `patch cannot be applied: path "/tags/3" does not exist`},
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			want: map[string]interface{}{"code": apperror.CodeInternal, "message": "operation failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewReport(&Request{Operations: []*Operation{{Op: OpCreate}}})
+			report.Record(0, 0, tt.err)
+
+			result := report.Results[0]
+			if result.Status != StatusFailed || report.Failed != 1 {
+				t.Fatalf("status = %q, failed = %d, want one failed operation", result.Status, report.Failed)
+			}
+			if !reflect.DeepEqual(result.Errors, tt.want) {
+				t.Errorf("errors = %v, want %v", result.Errors, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package cursor
+
+import (
+	"ecommerce/pkg/apperror"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+// MaxLimit bounds the size of a cursor page.
+const MaxLimit int64 = 100
+
+var (
+	ErrInvalid      = apperror.Validation("invalid_cursor", "invalid cursor")
+	ErrSortMismatch = apperror.Validation("cursor_sort_mismatch", "cursor was issued for a different sort order")
+	ErrInvalidLimit = apperror.Validation("invalid_limit", "limit must be a number between 1 and "+strconv.FormatInt(MaxLimit, 10))
+)
+
+// Cursor points at the row a page continues from: the page holds the rows
+// after it in sort order, or before it when Backward. Sort records the sort
+// order the cursor was issued for.
+type Cursor struct {
+	ID       uint   `json:"id"`
+	Backward bool   `json:"b,omitempty"`
+	Sort     string `json:"s"`
+}
+
+// Page links a cursor page to its neighbours; an empty cursor means there is
+// no page in that direction.
+type Page struct {
+	Next  string
+	Prev  string
+	Total *int
+}
+
+func (c *Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func Decode(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(raw, c); err != nil || c.ID == 0 {
+		return nil, ErrInvalid
+	}
+	return c, nil
+}
+
+// ParseLimit reads the limit param of a cursor page, which is fallback when
+// the param is empty.
+func ParseLimit(value string, fallback int64) (int64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 1 || limit > MaxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return limit, nil
+}
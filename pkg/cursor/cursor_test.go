@@ -0,0 +1,78 @@
+package cursor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor *Cursor
+	}{
+		{name: "forward", cursor: &Cursor{ID: 42, Sort: "-created_at"}},
+		{name: "backward", cursor: &Cursor{ID: 7, Backward: true, Sort: "name,id"}},
+		{name: "no sort", cursor: &Cursor{ID: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.cursor.Encode())
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if *got != *tt.cursor {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not base64", value: "!!!"},
+		{name: "not json", value: "bm90IGpzb24"},
+		{name: "zero id", value: (&Cursor{Sort: "id"}).Encode()},
+		{name: "empty", value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.value); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.value, err, ErrInvalid)
+			}
+		})
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		wantErr error
+	}{
+		{name: "empty uses fallback", value: "", want: 10},
+		{name: "lowest", value: "1", want: 1},
+		{name: "highest", value: "100", want: 100},
+		{name: "zero", value: "0", wantErr: ErrInvalidLimit},
+		{name: "negative", value: "-5", wantErr: ErrInvalidLimit},
+		{name: "too large", value: "101", wantErr: ErrInvalidLimit},
+		{name: "not a number", value: "ten", wantErr: ErrInvalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLimit(tt.value, 10)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseLimit(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLimit(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package etag renders row versions as entity tags and evaluates If-Match
+// preconditions against them.
+package etag
+
+import (
+	"ecommerce/pkg/apperror"
+	"strconv"
+	"strings"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+var (
+	// ErrPreconditionFailed is returned when If-Match names none of the
+	// current version, i.e. the resource changed since it was read.
+	ErrPreconditionFailed = apperror.New(apperror.KindPreconditionFailed, "precondition_failed", "resource was modified since it was read, fetch it again and retry")
+	// ErrPreconditionRequired is returned when a write without If-Match is
+	// refused.
+	ErrPreconditionRequired = apperror.New(apperror.KindPreconditionRequired, "precondition_required", "If-Match header is required")
+)
+
+// Format renders version as a strong entity tag.
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Match reports whether an If-Match header value holds for version: it is
+// empty, "*" or lists the tag of version. Weak tags never match, as If-Match
+// uses the strong comparison.
+func Match(ifMatch string, version int) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	current := Format(version)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package etag
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{version: 0, want: `"0"`},
+		{version: 1, want: `"1"`},
+		{version: 42, want: `"42"`},
+	}
+
+	for _, tt := range tests {
+		if got := Format(tt.version); got != tt.want {
+			t.Errorf("Format(%d) = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		version int
+		want    bool
+	}{
+		{name: "absent", ifMatch: "", version: 3, want: true},
+		{name: "any", ifMatch: "*", version: 3, want: true},
+		{name: "current", ifMatch: `"3"`, version: 3, want: true},
+		{name: "stale", ifMatch: `"2"`, version: 3, want: false},
+		{name: "list holding the current", ifMatch: `"1", "3"`, version: 3, want: true},
+		{name: "list without the current", ifMatch: `"1","2"`, version: 3, want: false},
+		{name: "weak tag", ifMatch: `W/"3"`, version: 3, want: false},
+		{name: "unquoted", ifMatch: "3", version: 3, want: false},
+		{name: "surrounding spaces", ifMatch: `  "3" `, version: 3, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.ifMatch, tt.version); got != tt.want {
+				t.Errorf("Match(%q, %d) = %v, want %v", tt.ifMatch, tt.version, got, tt.want)
+			}
+		})
+	}
+}
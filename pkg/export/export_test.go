@@ -0,0 +1,131 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	created := time.Date(2025, 1, 31, 10, 30, 0, 0, time.UTC)
+	columns := []string{"id", "name", "description", "weight", "qty", "created_at"}
+	rows := [][]interface{}{
+		{1, "Shirt, red", nil, 0.25, int64(3), created},
+		{2, `Mug "<large>"`, "a\nb", 1.0, int64(0), created},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatCSV,
+			want: "id,name,description,weight,qty,created_at\n" +
+				"1,\"Shirt, red\",,0.25,3,2025-01-31T10:30:00Z\n" +
+				"2,\"Mug \"\"<large>\"\"\",\"a\nb\",1,0,2025-01-31T10:30:00Z\n",
+		},
+		{
+			format: FormatNDJSON,
+			want: `{"id":1,"name":"Shirt, red","description":null,"weight":0.25,"qty":3,"created_at":"2025-01-31T10:30:00Z"}` + "\n" +
+				`{"id":2,"name":"Mug \"\u003clarge\u003e\"","description":"a\nb","weight":1,"qty":0,"created_at":"2025-01-31T10:30:00Z"}` + "\n",
+		},
+		{
+			format: FormatXLSX,
+			want: xlsxSheetStart +
+				`<row><c t="inlineStr"><is><t xml:space="preserve">id</t></is></c><c t="inlineStr"><is><t xml:space="preserve">name</t></is></c><c t="inlineStr"><is><t xml:space="preserve">description</t></is></c><c t="inlineStr"><is><t xml:space="preserve">weight</t></is></c><c t="inlineStr"><is><t xml:space="preserve">qty</t></is></c><c t="inlineStr"><is><t xml:space="preserve">created_at</t></is></c></row>` +
+				`<row><c><v>1</v></c><c t="inlineStr"><is><t xml:space="preserve">Shirt, red</t></is></c><c/><c><v>0.25</v></c><c><v>3</v></c><c t="inlineStr"><is><t xml:space="preserve">2025-01-31T10:30:00Z</t></is></c></row>` +
+				`<row><c><v>2</v></c><c t="inlineStr"><is><t xml:space="preserve">Mug &#34;&lt;large&gt;&#34;</t></is></c><c t="inlineStr"><is><t xml:space="preserve">a&#xA;b</t></is></c><c><v>1</v></c><c><v>0</v></c><c t="inlineStr"><is><t xml:space="preserve">2025-01-31T10:30:00Z</t></is></c></row>` +
+				xlsxSheetEnd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewWriter(tt.format, &out)
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+
+			if err := writer.WriteHeader(columns); err != nil {
+				t.Fatalf("WriteHeader() error = %v", err)
+			}
+			for _, row := range rows {
+				if err := writer.Write(row); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			got := out.String()
+			if tt.format == FormatXLSX {
+				got = readSheet(t, out.Bytes())
+			}
+			if got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// readSheet returns the sheet of a workbook and checks the package holds the
+// parts a spreadsheet application needs to open it.
+func readSheet(t *testing.T, workbook []byte) string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		parts[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook has no %s", name)
+		}
+	}
+	return parts["xl/worksheets/sheet1.xml"]
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	for _, format := range []string{"", "pdf", "CSV"} {
+		if _, err := NewWriter(format, io.Discard); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("NewWriter(%q) error = %v, want %v", format, err, ErrUnknownFormat)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: FormatCSV, want: "text/csv; charset=utf-8"},
+		{format: FormatNDJSON, want: "application/x-ndjson"},
+		{format: FormatXLSX, want: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	}
+
+	for _, tt := range tests {
+		if got := ContentType(tt.format); got != tt.want {
+			t.Errorf("ContentType(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
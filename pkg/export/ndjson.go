@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// ndjsonWriter writes each row as a JSON object keyed by the header columns
+// in column order, one object per line.
+type ndjsonWriter struct {
+	writer  *bufio.Writer
+	columns [][]byte
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{writer: bufio.NewWriter(w)}
+}
+
+func (w *ndjsonWriter) WriteHeader(columns []string) error {
+	w.columns = make([][]byte, 0, len(columns))
+	for _, column := range columns {
+		key, err := json.Marshal(column)
+		if err != nil {
+			return err
+		}
+		w.columns = append(w.columns, key)
+	}
+	return nil
+}
+
+func (w *ndjsonWriter) Write(values []interface{}) error {
+	w.writer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			w.writer.WriteByte(',')
+		}
+
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339)
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		w.writer.Write(w.columns[i])
+		w.writer.WriteByte(':')
+		w.writer.Write(encoded)
+	}
+
+	// bufio keeps the first write error, so the last write reports it
+	_, err := w.writer.WriteString("}\n")
+	return err
+}
+
+func (w *ndjsonWriter) Close() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,172 @@
+package fields
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Registry maps the response fields, or relations, a resource lets clients
+// select to the columns they are rendered from. Names match the JSON keys of
+// the response.
+type Registry map[string][]string
+
+// Error lists the rejected names of one param together with the accepted
+// ones.
+type Error struct {
+	Invalid []string `json:"invalid"`
+	Allowed []string `json:"allowed"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cannot select %s, allowed: %s", strings.Join(e.Invalid, ", "), strings.Join(e.Allowed, ", "))
+}
+
+// Errors maps the fields and include params to their rejected names.
+type Errors map[string]*Error
+
+func (e Errors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(e))
+	for _, key := range keys {
+		messages = append(messages, key+": "+e[key].Error())
+	}
+	return "invalid selection: " + strings.Join(messages, "; ")
+}
+
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parse reads a comma separated list of names. Unknown names are rejected
+// with an *Error; repeated names are harmless and kept once.
+func (r Registry) parse(raw string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	invalid := make([]string, 0)
+
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if _, ok := r[name]; !ok {
+			invalid = append(invalid, name)
+			continue
+		}
+		selected[name] = true
+	}
+
+	if len(invalid) > 0 {
+		return nil, &Error{Invalid: invalid, Allowed: r.Names()}
+	}
+	return selected, nil
+}
+
+// Selection is the part of a resource a client asked for. A nil Selection
+// selects every field and relation.
+type Selection struct {
+	fields    Registry
+	relations Registry
+	selected  map[string]bool
+	included  map[string]bool
+}
+
+// FromQuery reads the fields and include params, e.g.
+// ?fields=id,name,price&include=brand. Without either param it returns nil so
+// the full resource is rendered. Without fields every field is selected, and
+// without include no relation is, so ?fields=id,name renders no relations.
+func FromQuery(values url.Values, fields Registry, relations Registry) (*Selection, error) {
+	if !values.Has("fields") && !values.Has("include") {
+		return nil, nil
+	}
+
+	s := &Selection{fields: fields, relations: relations, included: make(map[string]bool)}
+	errs := make(Errors)
+
+	if values.Has("fields") {
+		selected, err := fields.parse(values.Get("fields"))
+		if err != nil {
+			errs["fields"] = err.(*Error)
+		}
+		s.selected = selected
+	}
+
+	if values.Has("include") {
+		included, err := relations.parse(values.Get("include"))
+		if err != nil {
+			errs["include"] = err.(*Error)
+		}
+		s.included = included
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return s, nil
+}
+
+// Field reports whether the field is selected.
+func (s *Selection) Field(name string) bool {
+	return s == nil || s.selected == nil || s.selected[name]
+}
+
+// Include reports whether the relation is selected.
+func (s *Selection) Include(name string) bool {
+	return s == nil || s.included[name]
+}
+
+// Selects reports whether the member of a response named name is rendered:
+// a registered field when it is selected, anything else when it is an
+// included relation.
+func (s *Selection) Selects(name string) bool {
+	if s == nil {
+		return true
+	}
+	if _, isField := s.fields[name]; isField {
+		return s.Field(name)
+	}
+	return s.Include(name)
+}
+
+// Columns returns the table qualified columns the selection is rendered from,
+// always including the id, or nil to select every column.
+func (s *Selection) Columns(table string) []string {
+	if s == nil {
+		return nil
+	}
+
+	seen := map[string]bool{"id": true}
+	columns := []string{table + ".id"}
+	add := func(registry Registry, name string) {
+		for _, column := range registry[name] {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, table+"."+column)
+			}
+		}
+	}
+
+	for _, name := range s.fields.Names() {
+		if s.Field(name) {
+			add(s.fields, name)
+		}
+	}
+	for _, name := range s.relations.Names() {
+		if s.Include(name) {
+			add(s.relations, name)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,123 @@
+package fields
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+var (
+	testFields = Registry{
+		"id":         {"id"},
+		"name":       {"name"},
+		"price":      {"price", "tax_class_id"},
+		"pricing":    {"price", "tax_class_id"},
+		"created_at": {"created_at"},
+	}
+	testRelations = Registry{
+		"brand": {"brand_id"},
+		"tags":  {},
+	}
+)
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		wantNil     bool
+		selects     map[string]bool
+		wantColumns []string
+	}{
+		{
+			name:    "no params selects everything",
+			query:   "page=1",
+			wantNil: true,
+			selects: map[string]bool{"name": true, "price": true, "brand": true, "anything": true},
+		},
+		{
+			name:        "fields without include renders no relations",
+			query:       "fields=name,price",
+			selects:     map[string]bool{"id": false, "name": true, "price": true, "pricing": false, "brand": false, "tags": false},
+			wantColumns: []string{"products.id", "products.name", "products.price", "products.tax_class_id"},
+		},
+		{
+			name:        "include without fields keeps every field",
+			query:       "include=brand",
+			selects:     map[string]bool{"name": true, "created_at": true, "brand": true, "tags": false},
+			wantColumns: []string{"products.id", "products.created_at", "products.name", "products.price", "products.tax_class_id", "products.brand_id"},
+		},
+		{
+			name:        "repeated and blank names are kept once",
+			query:       "fields=name,, name&include=tags",
+			selects:     map[string]bool{"name": true, "price": false, "tags": true},
+			wantColumns: []string{"products.id", "products.name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			selection, err := FromQuery(values, testFields, testRelations)
+			if err != nil {
+				t.Fatalf("FromQuery(%q) error = %v", tt.query, err)
+			}
+			if (selection == nil) != tt.wantNil {
+				t.Fatalf("FromQuery(%q) = %v, want nil %v", tt.query, selection, tt.wantNil)
+			}
+
+			for name, want := range tt.selects {
+				if got := selection.Selects(name); got != want {
+					t.Errorf("Selects(%q) = %v, want %v", name, got, want)
+				}
+			}
+			if got := selection.Columns("products"); !reflect.DeepEqual(got, tt.wantColumns) {
+				t.Errorf("Columns() = %v, want %v", got, tt.wantColumns)
+			}
+		})
+	}
+}
+
+func TestFromQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Errors
+	}{
+		{
+			name:  "unknown field",
+			query: "fields=name,secret",
+			want:  Errors{"fields": {Invalid: []string{"secret"}, Allowed: testFields.Names()}},
+		},
+		{
+			name:  "unknown field and relation",
+			query: "fields=cost&include=brand,owner",
+			want: Errors{
+				"fields":  {Invalid: []string{"cost"}, Allowed: testFields.Names()},
+				"include": {Invalid: []string{"owner"}, Allowed: testRelations.Names()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = FromQuery(values, testFields, testRelations)
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("FromQuery(%q) error = %v, want Errors", tt.query, err)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("FromQuery(%q) error = %v, want %v", tt.query, errs, tt.want)
+			}
+		})
+	}
+}
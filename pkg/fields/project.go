@@ -0,0 +1,104 @@
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// object is a projected struct that keeps the field order of the struct when
+// encoded.
+type object struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Project drops the unselected fields and relations from a struct, or from
+// each struct of a slice, keyed by their JSON names. A nil Selection returns v
+// unchanged.
+func (s *Selection) Project(v interface{}) interface{} {
+	if s == nil {
+		return v
+	}
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Slice {
+		items := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, s.project(value.Index(i)))
+		}
+		return items
+	}
+	return s.project(value)
+}
+
+func (s *Selection) project(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return value.Interface()
+	}
+
+	o := &object{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if !s.Selects(name) {
+			continue
+		}
+
+		if strings.Contains(opts, "omitempty") && isEmpty(value.Field(i)) {
+			continue
+		}
+
+		o.keys = append(o.keys, name)
+		o.values = append(o.values, value.Field(i).Interface())
+	}
+	return o
+}
+
+// isEmpty mirrors the values encoding/json drops for omitempty.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
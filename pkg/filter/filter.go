@@ -0,0 +1,160 @@
+package filter
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	In   Operator = "in"
+	Nin  Operator = "nin"
+	Null Operator = "null"
+)
+
+var (
+	// Comparable suits numbers and times, Equality suits identifiers and
+	// enumerations.
+	Comparable = []Operator{Eq, Ne, Gt, Gte, Lt, Lte, In, Nin}
+	Equality   = []Operator{Eq, Ne, In, Nin}
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Bool
+	Time
+)
+
+// Field whitelists a filterable query param name, the column it maps to and
+// the operators allowed on it.
+type Field struct {
+	Table     string
+	Column    string
+	Type      Type
+	Operators []Operator
+}
+
+// Whitelist maps query param names to the fields of one resource.
+type Whitelist map[string]Field
+
+// Condition is a leaf of the filter tree: Field Operator Values. Values are
+// already converted to the field type; only In and Nin carry more than one.
+type Condition struct {
+	Field    Field
+	Operator Operator
+	Values   []interface{}
+}
+
+// Day is the value of a bare date compared with eq, ne, in or nin; it stands
+// for every instant of the day starting at Start.
+type Day struct {
+	Start time.Time
+}
+
+func (d Day) end() time.Time {
+	return d.Start.AddDate(0, 0, 1)
+}
+
+// Expression is the root of the filter tree, the conjunction of its
+// conditions.
+type Expression struct {
+	Conditions []*Condition
+}
+
+func (e *Expression) IsEmpty() bool {
+	return e == nil || len(e.Conditions) == 0
+}
+
+// Apply adds the conditions of the expression to the query.
+func Apply(db *gorm.DB, expression *Expression) *gorm.DB {
+	if expression.IsEmpty() {
+		return db
+	}
+
+	exprs := make([]clause.Expression, 0, len(expression.Conditions))
+	for _, condition := range expression.Conditions {
+		exprs = append(exprs, condition.clause())
+	}
+	return db.Where(clause.And(exprs...))
+}
+
+func (c *Condition) clause() clause.Expression {
+	column := clause.Column{Table: c.Field.Table, Name: c.Field.Column}
+
+	switch c.Operator {
+	case Ne:
+		return notEqual(column, c.Values[0])
+	case Gt:
+		return clause.Gt{Column: column, Value: c.Values[0]}
+	case Gte:
+		return clause.Gte{Column: column, Value: c.Values[0]}
+	case Lt:
+		return clause.Lt{Column: column, Value: c.Values[0]}
+	case Lte:
+		return clause.Lte{Column: column, Value: c.Values[0]}
+	case In:
+		if !hasDay(c.Values) {
+			return clause.IN{Column: column, Values: c.Values}
+		}
+
+		exprs := make([]clause.Expression, 0, len(c.Values))
+		for _, value := range c.Values {
+			exprs = append(exprs, equal(column, value))
+		}
+		return clause.Or(exprs...)
+	case Nin:
+		if !hasDay(c.Values) {
+			return clause.Not(clause.IN{Column: column, Values: c.Values})
+		}
+
+		exprs := make([]clause.Expression, 0, len(c.Values))
+		for _, value := range c.Values {
+			exprs = append(exprs, notEqual(column, value))
+		}
+		return clause.And(exprs...)
+	case Null:
+		if c.Values[0].(bool) {
+			return clause.Eq{Column: column, Value: nil}
+		}
+		return clause.Neq{Column: column, Value: nil}
+	default:
+		return equal(column, c.Values[0])
+	}
+}
+
+// equal matches a value, or any instant of a Day.
+func equal(column clause.Column, value interface{}) clause.Expression {
+	if day, ok := value.(Day); ok {
+		return clause.And(clause.Gte{Column: column, Value: day.Start}, clause.Lt{Column: column, Value: day.end()})
+	}
+	return clause.Eq{Column: column, Value: value}
+}
+
+// notEqual is the negation of equal; it is spelled out because clause.Not
+// negates the parts of a conjunction one by one.
+func notEqual(column clause.Column, value interface{}) clause.Expression {
+	if day, ok := value.(Day); ok {
+		return clause.Or(clause.Lt{Column: column, Value: day.Start}, clause.Gte{Column: column, Value: day.end()})
+	}
+	return clause.Neq{Column: column, Value: value}
+}
+
+func hasDay(values []interface{}) bool {
+	for _, value := range values {
+		if _, ok := value.(Day); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type product struct {
+	ID uint
+}
+
+func TestApply(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := Day{Start: date(2025, 1, 31)}
+	column := Field{Table: "products", Column: "created_at", Type: Time}
+
+	tests := []struct {
+		name       string
+		conditions []*Condition
+		want       string
+	}{
+		{
+			name:       "day eq",
+			conditions: []*Condition{{Field: column, Operator: Eq, Values: []interface{}{day}}},
+			want:       `SELECT * FROM "products" WHERE "products"."created_at" >= $1 AND "products"."created_at" < $2`,
+		},
+		{
+			name:       "day ne",
+			conditions: []*Condition{{Field: column, Operator: Ne, Values: []interface{}{day}}},
+			want:       `SELECT * FROM "products" WHERE ("products"."created_at" < $1 OR "products"."created_at" >= $2)`,
+		},
+		{
+			name:       "days in",
+			conditions: []*Condition{{Field: column, Operator: In, Values: []interface{}{day, date(2025, 2, 1)}}},
+			want:       `SELECT * FROM "products" WHERE (("products"."created_at" >= $1 AND "products"."created_at" < $2) OR "products"."created_at" = $3)`,
+		},
+		{
+			name:       "days nin",
+			conditions: []*Condition{{Field: column, Operator: Nin, Values: []interface{}{day, date(2025, 2, 1)}}},
+			want:       `SELECT * FROM "products" WHERE ("products"."created_at" < $1 OR "products"."created_at" >= $2) AND "products"."created_at" <> $3`,
+		},
+		{
+			name: "values",
+			conditions: []*Condition{
+				{Field: Field{Table: "products", Column: "price"}, Operator: Gte, Values: []interface{}{int64(10)}},
+				{Field: Field{Table: "products", Column: "brand_id"}, Operator: Nin, Values: []interface{}{int64(1), int64(2)}},
+				{Field: Field{Table: "products", Column: "tax_class_id"}, Operator: Null, Values: []interface{}{false}},
+			},
+			want: `SELECT * FROM "products" WHERE "products"."price" >= $1 AND "products"."brand_id" NOT IN ($2,$3) AND "products"."tax_class_id" IS NOT NULL`,
+		},
+		{
+			name: "empty",
+			want: `SELECT * FROM "products"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var products []product
+			statement := Apply(db.Table("products"), &Expression{Conditions: tt.conditions}).Find(&products).Statement
+			if got := statement.SQL.String(); got != tt.want {
+				t.Errorf("Apply() SQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
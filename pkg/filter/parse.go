@@ -0,0 +1,162 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+var paramPattern = regexp.MustCompile(`^([a-z_]+)\[([a-z]+)\]$`)
+
+// Errors maps an invalid filter param to the reason it was rejected.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	keys := make([]string, 0, len(e))
+	for key := range e {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(e))
+	for _, key := range keys {
+		messages = append(messages, key+": "+e[key])
+	}
+	return "invalid filter: " + strings.Join(messages, ", ")
+}
+
+// Parse builds the filter expression from the params shaped field[operator],
+// e.g. price[gte]=1000 or brand_id[in]=1,2. Params without brackets are left
+// to the caller. Every field and operator must be whitelisted and given once;
+// all invalid params are reported at once as Errors.
+//
+// A date without a time matches the whole local day, so
+// created_at[lte]=2025-01-31 includes the 31st and created_at[eq]=2025-01-31
+// matches any time on it.
+func Parse(values url.Values, whitelist Whitelist) (*Expression, error) {
+	expression := &Expression{Conditions: make([]*Condition, 0)}
+	errs := make(Errors)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := paramPattern.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+
+		name, operator := match[1], Operator(match[2])
+		field, ok := whitelist[name]
+		if !ok {
+			errs[key] = fmt.Sprintf("%s cannot be filtered on", name)
+			continue
+		}
+
+		if !slices.Contains(field.Operators, operator) {
+			errs[key] = fmt.Sprintf("operator %s is not supported on %s", operator, name)
+			continue
+		}
+
+		if len(values[key]) > 1 {
+			errs[key] = fmt.Sprintf("%s is given more than once", key)
+			continue
+		}
+
+		condition, err := parseCondition(field, operator, values.Get(key))
+		if err != nil {
+			errs[key] = err.Error()
+			continue
+		}
+		expression.Conditions = append(expression.Conditions, condition)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return expression, nil
+}
+
+func parseCondition(field Field, operator Operator, raw string) (*Condition, error) {
+	condition := &Condition{Field: field, Operator: operator}
+
+	if operator == Null {
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", raw)
+		}
+		condition.Values = []interface{}{isNull}
+		return condition, nil
+	}
+
+	rawValues := []string{raw}
+	if operator == In || operator == Nin {
+		rawValues = strings.Split(raw, ",")
+	}
+
+	for _, rawValue := range rawValues {
+		value, dateOnly, err := parseValue(field.Type, strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, err
+		}
+
+		// a bare date is a whole day: lte/gt compare against the next day,
+		// eq/ne/in/nin against the span of the day
+		if dateOnly {
+			switch operator {
+			case Lte:
+				value = value.(time.Time).AddDate(0, 0, 1)
+				condition.Operator = Lt
+			case Gt:
+				value = value.(time.Time).AddDate(0, 0, 1)
+				condition.Operator = Gte
+			case Eq, Ne, In, Nin:
+				value = Day{Start: value.(time.Time)}
+			}
+		}
+		condition.Values = append(condition.Values, value)
+	}
+
+	return condition, nil
+}
+
+func parseValue(fieldType Type, raw string) (interface{}, bool, error) {
+	switch fieldType {
+	case Int:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("%q is not an integer", raw)
+		}
+		return value, false, nil
+	case Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("%q is not a boolean", raw)
+		}
+		return value, false, nil
+	case Time:
+		if value, err := time.ParseInLocation(dateLayout, raw, time.Local); err == nil {
+			return value, true, nil
+		}
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("%q is not a date (2006-01-02) or RFC 3339 time", raw)
+		}
+		return value, false, nil
+	default:
+		if raw == "" {
+			return nil, false, fmt.Errorf("value is empty")
+		}
+		return raw, false, nil
+	}
+}
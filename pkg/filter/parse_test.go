@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testWhitelist = Whitelist{
+	"price":      {Table: "products", Column: "price", Type: Int, Operators: Comparable},
+	"type":       {Table: "products", Column: "type", Type: String, Operators: Equality},
+	"active":     {Table: "products", Column: "active", Type: Bool, Operators: []Operator{Eq}},
+	"parent_id":  {Table: "products", Column: "parent_id", Type: Int, Operators: []Operator{Null}},
+	"created_at": {Table: "products", Column: "created_at", Type: Time, Operators: Comparable},
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+func TestParse(t *testing.T) {
+	instant := time.Date(2025, 1, 31, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		query     string
+		operator  Operator
+		values    []interface{}
+		wantEmpty bool
+	}{
+		{name: "int", query: "price[gte]=1000", operator: Gte, values: []interface{}{int64(1000)}},
+		{name: "list", query: "price[in]=1, 2", operator: In, values: []interface{}{int64(1), int64(2)}},
+		{name: "string", query: "type[ne]=digital", operator: Ne, values: []interface{}{"digital"}},
+		{name: "bool", query: "active[eq]=true", operator: Eq, values: []interface{}{true}},
+		{name: "null", query: "parent_id[null]=false", operator: Null, values: []interface{}{false}},
+		{name: "time", query: "created_at[gte]=2025-01-31T10:30:00Z", operator: Gte, values: []interface{}{instant}},
+		{name: "time eq stays exact", query: "created_at[eq]=2025-01-31T10:30:00Z", operator: Eq, values: []interface{}{instant}},
+		{name: "date gte starts the day", query: "created_at[gte]=2025-01-31", operator: Gte, values: []interface{}{date(2025, 1, 31)}},
+		{name: "date lte includes the day", query: "created_at[lte]=2025-01-31", operator: Lt, values: []interface{}{date(2025, 2, 1)}},
+		{name: "date gt skips the day", query: "created_at[gt]=2025-01-31", operator: Gte, values: []interface{}{date(2025, 2, 1)}},
+		{name: "date eq is the day", query: "created_at[eq]=2025-01-31", operator: Eq, values: []interface{}{Day{Start: date(2025, 1, 31)}}},
+		{name: "date ne is the day", query: "created_at[ne]=2025-01-31", operator: Ne, values: []interface{}{Day{Start: date(2025, 1, 31)}}},
+		{name: "date in mixes days and times", query: "created_at[in]=2025-01-30,2025-01-31T10:30:00Z", operator: In, values: []interface{}{Day{Start: date(2025, 1, 30)}, instant}},
+		{name: "params without brackets are left alone", query: "page=1&brand_id=3", wantEmpty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expression, err := Parse(values, testWhitelist)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+
+			if tt.wantEmpty {
+				if !expression.IsEmpty() {
+					t.Errorf("Parse(%q) = %d conditions, want none", tt.query, len(expression.Conditions))
+				}
+				return
+			}
+
+			if len(expression.Conditions) != 1 {
+				t.Fatalf("Parse(%q) = %d conditions, want 1", tt.query, len(expression.Conditions))
+			}
+			condition := expression.Conditions[0]
+			if condition.Operator != tt.operator {
+				t.Errorf("Operator = %s, want %s", condition.Operator, tt.operator)
+			}
+			if !reflect.DeepEqual(condition.Values, tt.values) {
+				t.Errorf("Values = %v, want %v", condition.Values, tt.values)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  Errors
+	}{
+		{
+			name:  "unknown field",
+			query: "secret[eq]=1",
+			want:  Errors{"secret[eq]": "secret cannot be filtered on"},
+		},
+		{
+			name:  "unsupported operator",
+			query: "type[gt]=a",
+			want:  Errors{"type[gt]": "operator gt is not supported on type"},
+		},
+		{
+			name:  "repeated param",
+			query: "price[gte]=10&price[gte]=20",
+			want:  Errors{"price[gte]": "price[gte] is given more than once"},
+		},
+		{
+			name:  "bad values",
+			query: "price[gt]=x&active[eq]=maybe&created_at[lt]=yesterday&type[eq]=",
+			want: Errors{
+				"price[gt]":      `"x" is not an integer`,
+				"active[eq]":     `"maybe" is not a boolean`,
+				"created_at[lt]": `"yesterday" is not a date (2006-01-02) or RFC 3339 time`,
+				"type[eq]":       "value is empty",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Parse(values, testWhitelist)
+			errs, ok := err.(Errors)
+			if !ok {
+				t.Fatalf("Parse(%q) error = %v, want Errors", tt.query, err)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.query, errs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Config is the text search configuration the search_vector columns are
+// built with.
+const Config = "simple"
+
+// PrefixQuery turns free text into a to_tsquery expression in which every
+// word must match as a prefix, e.g. "gal s2" becomes "gal:* & s2:*". Any
+// character that is not a letter or digit separates words, so user input can
+// never inject tsquery operators. It returns an empty string when nothing
+// searchable remains.
+func PrefixQuery(input string) string {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,27 @@
+package fulltext
+
+import "testing"
+
+func TestPrefixQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "Galaxy", want: "galaxy:*"},
+		{input: "  gal  s2 ", want: "gal:* & s2:*"},
+		{input: "usb-c cable", want: "usb:* & c:* & cable:*"},
+		{input: "tv & !radio | (x)", want: "tv:* & radio:* & x:*"},
+		{input: "o'neill:*", want: "o:* & neill:*"},
+		{input: "Äpfel", want: "äpfel:*"},
+		{input: "", want: ""},
+		{input: "&|!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := PrefixQuery(tt.input); got != tt.want {
+				t.Errorf("PrefixQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package jsonapi
+
+// ErrorDocument carries the errors of a failed request instead of data.
+type ErrorDocument struct {
+	Errors  []*Error        `json:"errors"`
+	JSONAPI *Implementation `json:"jsonapi"`
+}
+
+// Error is a JSON:API error object; Meta holds the details of the failure,
+// such as the field errors of a validation error.
+type Error struct {
+	Status string                 `json:"status"`
+	Code   string                 `json:"code"`
+	Title  string                 `json:"title"`
+	Detail string                 `json:"detail,omitempty"`
+	Meta   map[string]interface{} `json:"meta,omitempty"`
+}
+
+func NewErrorDocument(errors ...*Error) *ErrorDocument {
+	return &ErrorDocument{Errors: errors, JSONAPI: &Implementation{Version: Version}}
+}
@@ -0,0 +1,228 @@
+// Package jsonapi renders the plain JSON representation of a resource as a
+// JSON:API document. A Schema tells which members of the plain representation
+// are relationships, so handlers keep producing their DTOs and only wrap them
+// in Resources.
+package jsonapi
+
+import (
+	"bytes"
+	"ecommerce/pkg/fields"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	MediaType = "application/vnd.api+json"
+	Version   = "1.1"
+)
+
+// Schema describes the resources of one type.
+type Schema struct {
+	Type      string
+	Relations []*Relation
+}
+
+// Relation is a member of the plain representation rendered as a
+// relationship called Name. An Embedded relation holds the related resources,
+// which are moved to the included member of the document and described by
+// Relations; any other holds the id of the related resource.
+type Relation struct {
+	Name      string
+	Key       string
+	Type      string
+	Embedded  bool
+	Relations []*Relation
+}
+
+type Document struct {
+	Data     interface{}        `json:"data"`
+	Included []*Resource        `json:"included,omitempty"`
+	Links    map[string]*string `json:"links,omitempty"`
+	Meta     interface{}        `json:"meta,omitempty"`
+	JSONAPI  *Implementation    `json:"jsonapi"`
+}
+
+type Implementation struct {
+	Version string `json:"version"`
+}
+
+type Resource struct {
+	Type          string                   `json:"type"`
+	ID            string                   `json:"id"`
+	Attributes    map[string]interface{}   `json:"attributes,omitempty"`
+	Relationships map[string]*Relationship `json:"relationships,omitempty"`
+}
+
+type Identifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Relationship holds the identifier, a list of identifiers or null.
+type Relationship struct {
+	Data interface{} `json:"data"`
+}
+
+// Resources is the data of a response that can also be rendered as JSON:API.
+// As plain JSON it renders the selection of data, like Selection.Project.
+type Resources struct {
+	schema    *Schema
+	selection *fields.Selection
+	data      interface{}
+}
+
+// Of describes data, a DTO or a slice of them, as resources of schema. The
+// selection narrows the attributes and relationships; the id is always kept.
+func Of(schema *Schema, selection *fields.Selection, data interface{}) *Resources {
+	return &Resources{schema: schema, selection: selection, data: data}
+}
+
+func (r *Resources) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.selection.Project(r.data))
+}
+
+// Document renders the resources as the primary data of a document, with the
+// embedded related resources included once each.
+func (r *Resources) Document() (*Document, error) {
+	raw, err := json.Marshal(r.data)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	b := &builder{}
+	document := &Document{JSONAPI: &Implementation{Version: Version}}
+	primary := make(map[Identifier]bool)
+	switch value := value.(type) {
+	case nil:
+		// a nil slice is an empty list, not a missing resource
+		if r.data != nil && reflect.TypeOf(r.data).Kind() == reflect.Slice {
+			document.Data = []*Resource{}
+		}
+	case []interface{}:
+		resources := make([]*Resource, 0, len(value))
+		for _, item := range value {
+			resource, err := b.resource(r.schema.Type, r.schema.Relations, r.selection, item)
+			if err != nil {
+				return nil, err
+			}
+			primary[Identifier{Type: resource.Type, ID: resource.ID}] = true
+			resources = append(resources, resource)
+		}
+		document.Data = resources
+	default:
+		resource, err := b.resource(r.schema.Type, r.schema.Relations, r.selection, value)
+		if err != nil {
+			return nil, err
+		}
+		primary[Identifier{Type: resource.Type, ID: resource.ID}] = true
+		document.Data = resource
+	}
+
+	// a resource appears once in a document, primary data first
+	for _, resource := range b.included {
+		id := Identifier{Type: resource.Type, ID: resource.ID}
+		if !primary[id] {
+			primary[id] = true
+			document.Included = append(document.Included, resource)
+		}
+	}
+	return document, nil
+}
+
+type builder struct {
+	included []*Resource
+}
+
+func (b *builder) resource(resourceType string, relations []*Relation, selection *fields.Selection, value interface{}) (*Resource, error) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jsonapi: %s resource is not an object", resourceType)
+	}
+	if object["id"] == nil {
+		return nil, fmt.Errorf("jsonapi: %s resource has no id", resourceType)
+	}
+
+	byKey := make(map[string]*Relation, len(relations))
+	for _, relation := range relations {
+		byKey[relation.Key] = relation
+	}
+
+	resource := &Resource{
+		Type:          resourceType,
+		ID:            fmt.Sprint(object["id"]),
+		Attributes:    make(map[string]interface{}),
+		Relationships: make(map[string]*Relationship),
+	}
+	// members are visited in key order so included resources come out in
+	// the same order on every request
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		member := object[key]
+		if key == "id" || !selection.Selects(key) {
+			continue
+		}
+
+		relation, ok := byKey[key]
+		if !ok {
+			resource.Attributes[key] = member
+			continue
+		}
+
+		relationship, err := b.relationship(relation, member)
+		if err != nil {
+			return nil, err
+		}
+		resource.Relationships[relation.Name] = relationship
+	}
+	return resource, nil
+}
+
+func (b *builder) relationship(relation *Relation, member interface{}) (*Relationship, error) {
+	if member == nil {
+		return &Relationship{}, nil
+	}
+	if !relation.Embedded {
+		return &Relationship{Data: &Identifier{Type: relation.Type, ID: fmt.Sprint(member)}}, nil
+	}
+
+	if items, ok := member.([]interface{}); ok {
+		identifiers := make([]*Identifier, 0, len(items))
+		for _, item := range items {
+			identifier, err := b.include(relation, item)
+			if err != nil {
+				return nil, err
+			}
+			identifiers = append(identifiers, identifier)
+		}
+		return &Relationship{Data: identifiers}, nil
+	}
+
+	identifier, err := b.include(relation, member)
+	if err != nil {
+		return nil, err
+	}
+	return &Relationship{Data: identifier}, nil
+}
+
+func (b *builder) include(relation *Relation, value interface{}) (*Identifier, error) {
+	resource, err := b.resource(relation.Type, relation.Relations, nil, value)
+	if err != nil {
+		return nil, err
+	}
+	b.included = append(b.included, resource)
+	return &Identifier{Type: resource.Type, ID: resource.ID}, nil
+}
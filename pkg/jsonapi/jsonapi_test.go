@@ -0,0 +1,157 @@
+package jsonapi
+
+import (
+	"ecommerce/pkg/fields"
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+type testBrand struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testTag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testProduct struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Price      int        `json:"price"`
+	TaxClassId *int       `json:"tax_class_id"`
+	Brand      *testBrand `json:"brand"`
+	Tags       []*testTag `json:"tags,omitempty"`
+}
+
+var testProductSchema = &Schema{
+	Type: "products",
+	Relations: []*Relation{
+		{Name: "tax-class", Key: "tax_class_id", Type: "tax-classes"},
+		{Name: "brand", Key: "brand", Type: "brands", Embedded: true},
+		{Name: "tags", Key: "tags", Type: "tags", Embedded: true},
+	},
+}
+
+var (
+	testProductFields    = fields.Registry{"id": {"id"}, "name": {"name"}, "price": {"price"}}
+	testProductRelations = fields.Registry{"brand": {"brand_id"}, "tags": {}, "tax_class_id": {"tax_class_id"}}
+)
+
+func TestDocument(t *testing.T) {
+	taxClass := 4
+	brand := &testBrand{ID: 7, Name: "Acme"}
+	shirt := &testProduct{ID: 1, Name: "Shirt", Price: 1000, TaxClassId: &taxClass, Brand: brand, Tags: []*testTag{{ID: 2, Name: "sale"}}}
+	mug := &testProduct{ID: 3, Name: "Mug", Price: 500, Brand: brand}
+
+	tests := []struct {
+		name      string
+		selection string
+		data      interface{}
+		want      string
+	}{
+		{
+			name: "single resource",
+			data: mug,
+			want: `{"data":{"type":"products","id":"3","attributes":{"name":"Mug","price":500},"relationships":{"brand":{"data":{"type":"brands","id":"7"}},"tax-class":{"data":null}}},"included":[{"type":"brands","id":"7","attributes":{"name":"Acme"}}],"jsonapi":{"version":"1.1"}}`,
+		},
+		{
+			name: "related resources are included once",
+			data: []*testProduct{shirt, mug},
+			want: `{"data":[{"type":"products","id":"1","attributes":{"name":"Shirt","price":1000},"relationships":{"brand":{"data":{"type":"brands","id":"7"}},"tags":{"data":[{"type":"tags","id":"2"}]},"tax-class":{"data":{"type":"tax-classes","id":"4"}}}},{"type":"products","id":"3","attributes":{"name":"Mug","price":500},"relationships":{"brand":{"data":{"type":"brands","id":"7"}},"tax-class":{"data":null}}}],"included":[{"type":"brands","id":"7","attributes":{"name":"Acme"}},{"type":"tags","id":"2","attributes":{"name":"sale"}}],"jsonapi":{"version":"1.1"}}`,
+		},
+		{
+			name:      "selection narrows attributes and relationships",
+			selection: "fields=name&include=tags",
+			data:      shirt,
+			want:      `{"data":{"type":"products","id":"1","attributes":{"name":"Shirt"},"relationships":{"tags":{"data":[{"type":"tags","id":"2"}]}}},"included":[{"type":"tags","id":"2","attributes":{"name":"sale"}}],"jsonapi":{"version":"1.1"}}`,
+		},
+		{
+			name: "nil slice is an empty list",
+			data: []*testProduct(nil),
+			want: `{"data":[],"jsonapi":{"version":"1.1"}}`,
+		},
+		{
+			name: "nil resource is null",
+			data: (*testProduct)(nil),
+			want: `{"data":null,"jsonapi":{"version":"1.1"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := Of(testProductSchema, parseSelection(t, tt.selection), tt.data).Document()
+			if err != nil {
+				t.Fatalf("Document() error = %v", err)
+			}
+
+			got, err := json.Marshal(document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Document() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data interface{}
+	}{
+		{name: "no id", data: map[string]interface{}{"name": "Shirt"}},
+		{name: "not an object", data: []string{"Shirt"}},
+		{name: "embedded resource without id", data: map[string]interface{}{"id": 1, "brand": map[string]interface{}{"name": "Acme"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Of(testProductSchema, nil, tt.data).Document(); err == nil {
+				t.Error("Document() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestResourcesMarshalJSON(t *testing.T) {
+	product := &testProduct{ID: 1, Name: "Shirt", Price: 1000, Brand: &testBrand{ID: 7, Name: "Acme"}}
+
+	tests := []struct {
+		name      string
+		selection string
+		want      string
+	}{
+		{name: "everything", want: `{"id":1,"name":"Shirt","price":1000,"tax_class_id":null,"brand":{"id":7,"name":"Acme"}}`},
+		{name: "selected fields", selection: "fields=id,price", want: `{"id":1,"price":1000}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(Of(testProductSchema, parseSelection(t, tt.selection), product))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func parseSelection(t *testing.T, query string) *fields.Selection {
+	t.Helper()
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selection, err := fields.FromQuery(values, testProductFields, testProductRelations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return selection
+}
@@ -0,0 +1,93 @@
+package jsonapi
+
+import (
+	"ecommerce/pkg/apperror"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrNotAcceptable is returned when Accept only names the JSON:API media type
+// with params the spec does not define.
+var ErrNotAcceptable = apperror.New(apperror.KindNotAcceptable, "unsupported_media_type_params", "JSON:API is only served without media type params other than ext and profile")
+
+// Accepts reports whether an Accept header asks for JSON:API. As the spec
+// requires, a header whose every JSON:API media type carries params other
+// than ext and profile is refused with ErrNotAcceptable.
+func Accepts(accept string) (bool, error) {
+	named := false
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err != nil || mediaType != MediaType {
+			continue
+		}
+
+		named = true
+		if onlyKnownParams(params) {
+			return true, nil
+		}
+	}
+
+	if named {
+		return false, ErrNotAcceptable
+	}
+	return false, nil
+}
+
+func onlyKnownParams(params map[string]string) bool {
+	for name := range params {
+		if name != "ext" && name != "profile" && name != "q" {
+			return false
+		}
+	}
+	return true
+}
+
+// PageLinks links the first, last, previous and next pages of a list paged by
+// page and per_page, keeping the other params of the request.
+func PageLinks(requestURL *url.URL, page int, perPage int, totalPage int) map[string]*string {
+	link := func(page int) *string {
+		return withParams(requestURL, map[string]string{"page": strconv.Itoa(page), "per_page": strconv.Itoa(perPage)})
+	}
+
+	links := map[string]*string{
+		"self":  link(page),
+		"first": link(1),
+		"last":  link(max(totalPage, 1)),
+		"prev":  nil,
+		"next":  nil,
+	}
+	if page > 1 {
+		links["prev"] = link(min(page-1, max(totalPage, 1)))
+	}
+	if page < totalPage {
+		links["next"] = link(page + 1)
+	}
+	return links
+}
+
+// CursorLinks links the previous and next pages of a list paged by cursor.
+func CursorLinks(requestURL *url.URL, nextCursor string, prevCursor string) map[string]*string {
+	self := requestURL.String()
+	links := map[string]*string{"self": &self, "prev": nil, "next": nil}
+	if prevCursor != "" {
+		links["prev"] = withParams(requestURL, map[string]string{"cursor": prevCursor})
+	}
+	if nextCursor != "" {
+		links["next"] = withParams(requestURL, map[string]string{"cursor": nextCursor})
+	}
+	return links
+}
+
+func withParams(requestURL *url.URL, params map[string]string) *string {
+	query := requestURL.Query()
+	for name, value := range params {
+		query.Set(name, value)
+	}
+
+	link := *requestURL
+	link.RawQuery = query.Encode()
+	rendered := link.String()
+	return &rendered
+}
@@ -0,0 +1,148 @@
+package jsonapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		accept  string
+		want    bool
+		wantErr error
+	}{
+		{accept: "", want: false},
+		{accept: "application/json", want: false},
+		{accept: "application/vnd.api+json", want: true},
+		{accept: `application/vnd.api+json; ext="https://jsonapi.org/ext/atomic"`, want: true},
+		{accept: "application/vnd.api+json; q=0.9, application/json", want: true},
+		{accept: "application/vnd.api+json; charset=utf-8", wantErr: ErrNotAcceptable},
+		{accept: "application/vnd.api+json; charset=utf-8, application/vnd.api+json", want: true},
+		{accept: "application/vnd.api+json; version=2, application/json", wantErr: ErrNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		got, err := Accepts(tt.accept)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Accepts(%q) error = %v, want %v", tt.accept, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("Accepts(%q) = %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func link(value string) *string {
+	return &value
+}
+
+func TestPageLinks(t *testing.T) {
+	requestURL, _ := url.Parse("/api/v2/products?page=2&per_page=10&sort=-price")
+
+	tests := []struct {
+		name      string
+		page      int
+		totalPage int
+		want      map[string]*string
+	}{
+		{
+			name:      "middle page",
+			page:      2,
+			totalPage: 3,
+			want: map[string]*string{
+				"self":  link("/api/v2/products?page=2&per_page=10&sort=-price"),
+				"first": link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"last":  link("/api/v2/products?page=3&per_page=10&sort=-price"),
+				"prev":  link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"next":  link("/api/v2/products?page=3&per_page=10&sort=-price"),
+			},
+		},
+		{
+			name:      "empty list",
+			page:      1,
+			totalPage: 0,
+			want: map[string]*string{
+				"self":  link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"first": link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"last":  link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"prev":  nil,
+				"next":  nil,
+			},
+		},
+		{
+			name:      "past the last page",
+			page:      5,
+			totalPage: 3,
+			want: map[string]*string{
+				"self":  link("/api/v2/products?page=5&per_page=10&sort=-price"),
+				"first": link("/api/v2/products?page=1&per_page=10&sort=-price"),
+				"last":  link("/api/v2/products?page=3&per_page=10&sort=-price"),
+				"prev":  link("/api/v2/products?page=3&per_page=10&sort=-price"),
+				"next":  nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PageLinks(requestURL, tt.page, 10, tt.totalPage)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PageLinks() = %v, want %v", render(got), render(tt.want))
+			}
+		})
+	}
+}
+
+func TestCursorLinks(t *testing.T) {
+	requestURL, _ := url.Parse("/api/v2/products?limit=10&cursor=abc")
+
+	tests := []struct {
+		name string
+		next string
+		prev string
+		want map[string]*string
+	}{
+		{
+			name: "both directions",
+			next: "def",
+			prev: "xyz",
+			want: map[string]*string{
+				"self": link("/api/v2/products?limit=10&cursor=abc"),
+				"next": link("/api/v2/products?cursor=def&limit=10"),
+				"prev": link("/api/v2/products?cursor=xyz&limit=10"),
+			},
+		},
+		{
+			name: "last page",
+			prev: "xyz",
+			want: map[string]*string{
+				"self": link("/api/v2/products?limit=10&cursor=abc"),
+				"next": nil,
+				"prev": link("/api/v2/products?cursor=xyz&limit=10"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CursorLinks(requestURL, tt.next, tt.prev)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CursorLinks() = %v, want %v", render(got), render(tt.want))
+			}
+		})
+	}
+}
+
+func render(links map[string]*string) map[string]string {
+	rendered := make(map[string]string, len(links))
+	for name, link := range links {
+		if link == nil {
+			rendered[name] = "null"
+		} else {
+			rendered[name] = *link
+		}
+	}
+	return rendered
+}
@@ -0,0 +1,271 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pointer is a parsed RFC 6901 JSON pointer; the empty pointer addresses the
+// whole document.
+type pointer []string
+
+func parsePointer(raw string) (pointer, error) {
+	if raw == "" {
+		return pointer{}, nil
+	}
+
+	if !strings.HasPrefix(raw, "/") {
+		return nil, fmt.Errorf("%w: path %q does not start with /", ErrInvalidPatch, raw)
+	}
+
+	tokens := strings.Split(raw[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func (p pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteByte('/')
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+func isPrefix(prefix pointer, p pointer) bool {
+	if len(prefix) > len(p) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// update replaces the parent of the location p points to with what fn makes
+// of it, and returns the document.
+func update(doc interface{}, p pointer, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(p) == 1 {
+		return fn(doc, p[0])
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[p[0]]
+		if !ok {
+			return nil, notFound(p)
+		}
+
+		child, err := update(child, p[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[p[0]] = child
+		return node, nil
+	case []interface{}:
+		i, err := index(p[0], len(node)-1)
+		if err != nil {
+			return nil, notFound(p)
+		}
+
+		child, err := update(node[i], p[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[i] = child
+		return node, nil
+	}
+	return nil, notFound(p)
+}
+
+func get(doc interface{}, p pointer) (interface{}, error) {
+	node := doc
+	for _, token := range p {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, notFound(p)
+			}
+			node = child
+		case []interface{}:
+			i, err := index(token, len(n)-1)
+			if err != nil {
+				return nil, notFound(p)
+			}
+			node = n[i]
+		default:
+			return nil, notFound(p)
+		}
+	}
+	return node, nil
+}
+
+func add(doc interface{}, p pointer, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+
+	return update(doc, p, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			if token == "-" {
+				return append(node, value), nil
+			}
+
+			i, err := index(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("%w: index %q out of range at %q", ErrUnprocessable, token, p)
+			}
+
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		}
+		return nil, notFound(p)
+	})
+}
+
+func remove(doc interface{}, p pointer) (interface{}, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrUnprocessable)
+	}
+
+	return update(doc, p, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, notFound(p)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, notFound(p)
+			}
+			return append(node[:i], node[i+1:]...), nil
+		}
+		return nil, notFound(p)
+	})
+}
+
+func replace(doc interface{}, p pointer, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+
+	return update(doc, p, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, notFound(p)
+			}
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, notFound(p)
+			}
+			node[i] = value
+			return node, nil
+		}
+		return nil, notFound(p)
+	})
+}
+
+// index parses an array index token that is at most max.
+func index(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, strconv.ErrSyntax
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, err
+	}
+
+	if i < 0 || i > max {
+		return 0, strconv.ErrRange
+	}
+	return i, nil
+}
+
+func notFound(p pointer) error {
+	return fmt.Errorf("%w: path %q does not exist", ErrUnprocessable, p)
+}
+
+// equal compares JSON values, numbers by their value.
+func equal(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		u, _, errU := big.ParseFloat(string(x), 10, 256, big.ToNearestEven)
+		v, _, errV := big.ParseFloat(string(y), 10, 256, big.ToNearestEven)
+		if errU != nil || errV != nil {
+			return x == y
+		}
+		return u.Cmp(v) == 0
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for name, value := range x {
+			other, ok := y[name]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			object[name] = deepCopy(member)
+		}
+		return object
+	case []interface{}:
+		array := make([]interface{}, len(v))
+		for i := range v {
+			array[i] = deepCopy(v[i])
+		}
+		return array
+	}
+	return value
+}
@@ -0,0 +1,207 @@
+package ordering
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// Key is one ORDER BY term.
+type Key struct {
+	Name   string
+	Column clause.Column
+	Desc   bool
+}
+
+// Registry maps the sort names a resource accepts to their columns. A zero
+// column marks a computed key, such as a search rank, whose expression the
+// repository supplies to OrderBy.
+type Registry map[string]clause.Column
+
+// Error lists the rejected sort names together with the accepted ones.
+type Error struct {
+	Invalid []string `json:"invalid"`
+	Allowed []string `json:"allowed"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("cannot sort by %s, allowed: %s", strings.Join(e.Invalid, ", "), strings.Join(e.Allowed, ", "))
+}
+
+func (r Registry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Key returns the registered key name; it is meant for defaults known to be
+// registered.
+func (r Registry) Key(name string, desc bool) Key {
+	return Key{Name: name, Column: r[name], Desc: desc}
+}
+
+// Parse reads a comma separated list of sort names, each descending when
+// prefixed by "-", e.g. "-price,name". Unknown and repeated names are
+// rejected with an *Error.
+func (r Registry) Parse(raw string) ([]Key, error) {
+	keys := make([]Key, 0)
+	invalid := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, term := range strings.Split(raw, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		name := strings.TrimPrefix(term, "-")
+		column, ok := r[name]
+		if !ok || seen[name] {
+			invalid = append(invalid, term)
+			continue
+		}
+
+		seen[name] = true
+		keys = append(keys, Key{Name: name, Column: column, Desc: strings.HasPrefix(term, "-")})
+	}
+
+	if len(invalid) > 0 {
+		return nil, &Error{Invalid: invalid, Allowed: r.Names()}
+	}
+	return keys, nil
+}
+
+// FromQuery returns the sort param, falling back to the older SortBy and Sort
+// (asc, desc; default desc) pair when it is absent.
+func FromQuery(values url.Values) string {
+	if raw := values.Get("sort"); raw != "" {
+		return raw
+	}
+
+	sortBy := values.Get("SortBy")
+	if sortBy == "" || strings.Contains(sortBy, ",") {
+		return sortBy
+	}
+
+	if values.Get("Sort") == "asc" {
+		return sortBy
+	}
+	return "-" + sortBy
+}
+
+// Format renders keys back into the sort param syntax, e.g. "-price,name".
+func Format(keys []Key) string {
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			terms = append(terms, "-"+key.Name)
+		} else {
+			terms = append(terms, key.Name)
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+// WithTieBreaker appends tieBreaker, ascending, unless a key already orders by
+// it.
+func WithTieBreaker(keys []Key, name string, tieBreaker clause.Column) []Key {
+	for _, key := range keys {
+		if key.Column == tieBreaker {
+			return keys
+		}
+	}
+	return append(append(make([]Key, 0, len(keys)+1), keys...), Key{Name: name, Column: tieBreaker})
+}
+
+// Reverse flips the direction of every key.
+func Reverse(keys []Key) []Key {
+	reversed := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		key.Desc = !key.Desc
+		reversed = append(reversed, key)
+	}
+	return reversed
+}
+
+// OrderBy builds the ORDER BY clause of the keys, ending with tieBreaker
+// ascending unless a key already orders by it so pages are stable. computed
+// holds the expressions of computed keys; those without one are skipped.
+func OrderBy(keys []Key, tieBreaker clause.Column, computed map[string]clause.Expr) clause.Expression {
+	terms := make([]string, 0, len(keys)+1)
+	vars := make([]interface{}, 0, len(keys)+1)
+
+	for _, term := range resolve(WithTieBreaker(keys, "", tieBreaker), computed) {
+		direction := " ASC"
+		if term.desc {
+			direction = " DESC"
+		}
+		terms = append(terms, term.expr.SQL+direction)
+		vars = append(vars, term.expr.Vars...)
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                strings.Join(terms, ", "),
+		Vars:               vars,
+		WithoutParentheses: true,
+	}}
+}
+
+// Keyset keeps the rows that come after the row with the given id in the
+// order of keys, which must end with a unique key. The values of that row are
+// read by subqueries on table, so the condition only needs its id.
+func Keyset(keys []Key, computed map[string]clause.Expr, table string, id uint) clause.Expression {
+	terms := resolve(keys, computed)
+	idColumn := clause.Column{Table: table, Name: "id"}
+
+	disjuncts := make([]string, 0, len(terms))
+	vars := make([]interface{}, 0)
+	for i, term := range terms {
+		conjuncts := make([]string, 0, i+1)
+		for _, equal := range terms[:i] {
+			conjuncts = append(conjuncts, equal.expr.SQL+" = (SELECT "+equal.expr.SQL+" FROM ? WHERE ? = ?)")
+			vars = append(vars, equal.expr.Vars...)
+			vars = append(vars, equal.expr.Vars...)
+			vars = append(vars, clause.Table{Name: table}, idColumn, id)
+		}
+
+		operator := " > "
+		if term.desc {
+			operator = " < "
+		}
+		conjuncts = append(conjuncts, term.expr.SQL+operator+"(SELECT "+term.expr.SQL+" FROM ? WHERE ? = ?)")
+		vars = append(vars, term.expr.Vars...)
+		vars = append(vars, term.expr.Vars...)
+		vars = append(vars, clause.Table{Name: table}, idColumn, id)
+
+		disjuncts = append(disjuncts, "("+strings.Join(conjuncts, " AND ")+")")
+	}
+
+	return clause.Expr{SQL: "(" + strings.Join(disjuncts, " OR ") + ")", Vars: vars}
+}
+
+type term struct {
+	expr clause.Expr
+	desc bool
+}
+
+func resolve(keys []Key, computed map[string]clause.Expr) []term {
+	terms := make([]term, 0, len(keys))
+	for _, key := range keys {
+		if key.Column == (clause.Column{}) {
+			expr, ok := computed[key.Name]
+			if !ok {
+				continue
+			}
+			terms = append(terms, term{expr: expr, desc: key.Desc})
+			continue
+		}
+		terms = append(terms, term{expr: clause.Expr{SQL: "?", Vars: []interface{}{key.Column}}, desc: key.Desc})
+	}
+	return terms
+}
@@ -0,0 +1,190 @@
+package ordering
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	idColumn    = clause.Column{Table: "products", Name: "id"}
+	priceColumn = clause.Column{Table: "products", Name: "price"}
+	nameColumn  = clause.Column{Table: "products", Name: "name"}
+	testFields  = Registry{"id": idColumn, "price": priceColumn, "name": nameColumn, "relevance": {}}
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []Key
+		invalid []string
+	}{
+		{name: "empty", raw: "", want: []Key{}},
+		{name: "ascending", raw: "name", want: []Key{{Name: "name", Column: nameColumn}}},
+		{
+			name: "mixed directions and spaces",
+			raw:  "-price, name,",
+			want: []Key{{Name: "price", Column: priceColumn, Desc: true}, {Name: "name", Column: nameColumn}},
+		},
+		{name: "computed key", raw: "-relevance", want: []Key{{Name: "relevance", Desc: true}}},
+		{name: "unknown", raw: "price,secret", invalid: []string{"secret"}},
+		{name: "repeated", raw: "price,-price", invalid: []string{"-price"}},
+		{name: "injection", raw: "name;drop table", invalid: []string{"name;drop table"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testFields.Parse(tt.raw)
+			if tt.invalid != nil {
+				var sortErr *Error
+				if !errors.As(err, &sortErr) {
+					t.Fatalf("Parse(%q) error = %v, want *Error", tt.raw, err)
+				}
+				if !reflect.DeepEqual(sortErr.Invalid, tt.invalid) {
+					t.Errorf("Invalid = %v, want %v", sortErr.Invalid, tt.invalid)
+				}
+				if want := []string{"id", "name", "price", "relevance"}; !reflect.DeepEqual(sortErr.Allowed, want) {
+					t.Errorf("Allowed = %v, want %v", sortErr.Allowed, want)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			if tt.raw != "" && Format(got) != Format(tt.want) {
+				t.Errorf("Format() = %q, want %q", Format(got), Format(tt.want))
+			}
+		})
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "sort", query: "sort=-price,name", want: "-price,name"},
+		{name: "sort wins over SortBy", query: "sort=name&SortBy=price", want: "name"},
+		{name: "SortBy defaults to descending", query: "SortBy=price", want: "-price"},
+		{name: "SortBy ascending", query: "SortBy=price&Sort=asc", want: "price"},
+		{name: "SortBy list is passed through", query: "SortBy=price,name", want: "price,name"},
+		{name: "none", query: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := FromQuery(values); got != tt.want {
+				t.Errorf("FromQuery(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTieBreakerAndReverse(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []Key
+		want     []Key
+		reversed []Key
+	}{
+		{
+			name:     "appends the tie breaker",
+			keys:     []Key{{Name: "price", Column: priceColumn, Desc: true}},
+			want:     []Key{{Name: "price", Column: priceColumn, Desc: true}, {Name: "id", Column: idColumn}},
+			reversed: []Key{{Name: "price", Column: priceColumn}, {Name: "id", Column: idColumn, Desc: true}},
+		},
+		{
+			name:     "keeps an existing tie breaker",
+			keys:     []Key{{Name: "id", Column: idColumn, Desc: true}},
+			want:     []Key{{Name: "id", Column: idColumn, Desc: true}},
+			reversed: []Key{{Name: "id", Column: idColumn}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithTieBreaker(tt.keys, "id", idColumn)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithTieBreaker() = %v, want %v", got, tt.want)
+			}
+			if reversed := Reverse(got); !reflect.DeepEqual(reversed, tt.reversed) {
+				t.Errorf("Reverse() = %v, want %v", reversed, tt.reversed)
+			}
+		})
+	}
+}
+
+func TestOrderByAndKeyset(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rank := map[string]clause.Expr{"relevance": {SQL: "rank(?)", Vars: []interface{}{"q"}}}
+
+	tests := []struct {
+		name        string
+		keys        []Key
+		computed    map[string]clause.Expr
+		wantOrder   string
+		wantKeyset  string
+		wantVarsLen int
+	}{
+		{
+			name:        "column",
+			keys:        []Key{{Name: "price", Column: priceColumn, Desc: true}},
+			wantOrder:   `ORDER BY "products"."price" DESC, "products"."id" ASC`,
+			wantKeyset:  `WHERE (("products"."price" < (SELECT "products"."price" FROM "products" WHERE "products"."id" = $1)) OR ("products"."price" = (SELECT "products"."price" FROM "products" WHERE "products"."id" = $2) AND "products"."id" > (SELECT "products"."id" FROM "products" WHERE "products"."id" = $3)))`,
+			wantVarsLen: 3,
+		},
+		{
+			name:        "computed key",
+			keys:        []Key{{Name: "relevance", Desc: true}},
+			computed:    rank,
+			wantOrder:   `ORDER BY rank($1) DESC, "products"."id" ASC`,
+			wantKeyset:  `WHERE ((rank($1) < (SELECT rank($2) FROM "products" WHERE "products"."id" = $3)) OR (rank($4) = (SELECT rank($5) FROM "products" WHERE "products"."id" = $6) AND "products"."id" > (SELECT "products"."id" FROM "products" WHERE "products"."id" = $7)))`,
+			wantVarsLen: 7,
+		},
+		{
+			name:        "computed key without expression is skipped",
+			keys:        []Key{{Name: "relevance", Desc: true}},
+			wantOrder:   `ORDER BY "products"."id" ASC`,
+			wantKeyset:  `WHERE (("products"."id" > (SELECT "products"."id" FROM "products" WHERE "products"."id" = $1)))`,
+			wantVarsLen: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]interface{}
+			statement := db.Table("products").Clauses(OrderBy(tt.keys, idColumn, tt.computed)).Find(&rows).Statement
+			if got, want := statement.SQL.String(), `SELECT * FROM "products" `+tt.wantOrder; got != want {
+				t.Errorf("OrderBy() SQL = %s, want %s", got, want)
+			}
+
+			keys := WithTieBreaker(tt.keys, "id", idColumn)
+			statement = db.Table("products").Where(Keyset(keys, tt.computed, "products", 9)).Find(&rows).Statement
+			if got, want := statement.SQL.String(), `SELECT * FROM "products" `+tt.wantKeyset; got != want {
+				t.Errorf("Keyset() SQL = %s, want %s", got, want)
+			}
+			if len(statement.Vars) != tt.wantVarsLen {
+				t.Errorf("Keyset() vars = %v, want %d", statement.Vars, tt.wantVarsLen)
+			}
+		})
+	}
+}
@@ -9,14 +9,20 @@ type PaginationResponse struct {
 	Data      interface{} `json:"data"`
 }
 
-type SuccessResponse struct {
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+// CursorPaginationResponse pages with opaque cursors; Total is only present
+// when the count was requested.
+type CursorPaginationResponse struct {
+	Message    string      `json:"message"`
+	Limit      int         `json:"limit"`
+	NextCursor *string     `json:"next_cursor"`
+	PrevCursor *string     `json:"prev_cursor"`
+	Total      *int        `json:"total_items,omitempty"`
+	Data       interface{} `json:"data"`
 }
 
-type ErrorResponse struct {
+type SuccessResponse struct {
 	Message string      `json:"message"`
-	Data    interface{} `json:"errors"`
+	Data    interface{} `json:"data"`
 }
 
 func NewSuccessResponse(message string, data interface{}) *SuccessResponse {
@@ -26,13 +32,6 @@ func NewSuccessResponse(message string, data interface{}) *SuccessResponse {
 	}
 }
 
-func NewErrorResponse(message string, error ...interface{}) *ErrorResponse {
-	return &ErrorResponse{
-		Message: message,
-		Data:    error,
-	}
-}
-
 func NewPaginationResponse(total int, totalPage int, perPage int, pageSize int, data interface{}) *PaginationResponse {
 	return &PaginationResponse{
 		Message:   "success",
@@ -43,3 +42,20 @@ func NewPaginationResponse(total int, totalPage int, perPage int, pageSize int,
 		Data:      data,
 	}
 }
+
+func NewCursorPaginationResponse(nextCursor string, prevCursor string, limit int, total *int, data interface{}) *CursorPaginationResponse {
+	response := &CursorPaginationResponse{
+		Message: "success",
+		Limit:   limit,
+		Total:   total,
+		Data:    data,
+	}
+
+	if nextCursor != "" {
+		response.NextCursor = &nextCursor
+	}
+	if prevCursor != "" {
+		response.PrevCursor = &prevCursor
+	}
+	return response
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 problem details.
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem is an RFC 7807 problem details body. Code is the stable error code
+// of the failure and Details are extension members, such as the field errors
+// of a validation problem.
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Code     string                 `json:"code"`
+	Details  map[string]interface{} `json:"-"`
+}
+
+// NewProblem returns a problem of the generic about:blank type, titled after
+// the status.
+func NewProblem(status int, code string, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// MarshalJSON renders Details as members of the problem next to the standard
+// ones, which they cannot override.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	standard, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Details) == 0 {
+		return standard, err
+	}
+
+	members := make(map[string]interface{}, len(p.Details)+6)
+	for key, value := range p.Details {
+		members[key] = value
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(standard, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range fields {
+		members[key] = value
+	}
+	return json.Marshal(members)
+}
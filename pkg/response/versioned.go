@@ -0,0 +1,133 @@
+package response
+
+import (
+	"ecommerce/pkg/apiversion"
+	"ecommerce/pkg/jsonapi"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// Document is the v2 envelope: the payload under data and, for lists, how it
+// was paged under meta. Messages are gone; the status tells the outcome.
+type Document struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+type PageMeta struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	TotalPages int `json:"total_pages"`
+	TotalItems int `json:"total_items"`
+}
+
+type CursorMeta struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+	TotalItems *int    `json:"total_items,omitempty"`
+}
+
+// Success writes data in the envelope of the API version the request was
+// routed to. In v2 a write that returns nothing answers without a body.
+// Data wrapped in jsonapi.Resources is written as a JSON:API document instead
+// when the client asks for one, as is the data of the paginated helpers.
+func Success(c echo.Context, status int, message string, data interface{}) error {
+	resources, err := negotiateJSONAPI(c, data)
+	if err != nil {
+		return err
+	}
+	if resources != nil {
+		return writeDocument(c, status, resources, nil, nil)
+	}
+
+	if apiversion.FromContext(c) == apiversion.V1 {
+		return c.JSON(status, NewSuccessResponse(message, data))
+	}
+
+	if data == nil {
+		if status == http.StatusOK {
+			status = http.StatusNoContent
+		}
+		return c.NoContent(status)
+	}
+	return c.JSON(status, &Document{Data: data})
+}
+
+// Pagination writes a page of data in the envelope of the API version the
+// request was routed to.
+func Pagination(c echo.Context, total int, totalPage int, perPage int, page int, data interface{}) error {
+	resources, err := negotiateJSONAPI(c, data)
+	if err != nil {
+		return err
+	}
+	if resources != nil {
+		meta := &PageMeta{Page: page, PerPage: perPage, TotalPages: totalPage, TotalItems: total}
+		return writeDocument(c, http.StatusOK, resources, meta, jsonapi.PageLinks(c.Request().URL, page, perPage, totalPage))
+	}
+
+	if apiversion.FromContext(c) == apiversion.V1 {
+		return c.JSON(http.StatusOK, NewPaginationResponse(total, totalPage, perPage, page, data))
+	}
+
+	return c.JSON(http.StatusOK, &Document{
+		Data: data,
+		Meta: &PageMeta{Page: page, PerPage: perPage, TotalPages: totalPage, TotalItems: total},
+	})
+}
+
+// CursorPagination writes a cursor page of data in the envelope of the API
+// version the request was routed to.
+func CursorPagination(c echo.Context, nextCursor string, prevCursor string, limit int, total *int, data interface{}) error {
+	page := NewCursorPaginationResponse(nextCursor, prevCursor, limit, total, data)
+	resources, err := negotiateJSONAPI(c, data)
+	if err != nil {
+		return err
+	}
+	if resources != nil {
+		meta := &CursorMeta{Limit: limit, NextCursor: page.NextCursor, PrevCursor: page.PrevCursor, TotalItems: total}
+		return writeDocument(c, http.StatusOK, resources, meta, jsonapi.CursorLinks(c.Request().URL, nextCursor, prevCursor))
+	}
+
+	if apiversion.FromContext(c) == apiversion.V1 {
+		return c.JSON(http.StatusOK, page)
+	}
+
+	return c.JSON(http.StatusOK, &Document{
+		Data: data,
+		Meta: &CursorMeta{Limit: limit, NextCursor: page.NextCursor, PrevCursor: page.PrevCursor, TotalItems: total},
+	})
+}
+
+// negotiateJSONAPI returns the resources of data when the client asked for
+// JSON:API, whatever the API version, and nil when it did not or data cannot
+// be rendered as JSON:API.
+func negotiateJSONAPI(c echo.Context, data interface{}) (*jsonapi.Resources, error) {
+	resources, ok := data.(*jsonapi.Resources)
+	if !ok {
+		return nil, nil
+	}
+
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	accepted, err := jsonapi.Accepts(c.Request().Header.Get(echo.HeaderAccept))
+	if !accepted {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func writeDocument(c echo.Context, status int, resources *jsonapi.Resources, meta interface{}, links map[string]*string) error {
+	document, err := resources.Document()
+	if err != nil {
+		return err
+	}
+	document.Meta = meta
+	document.Links = links
+	if document.Links == nil {
+		self := c.Request().URL.String()
+		document.Links = map[string]*string{"self": &self}
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, jsonapi.MediaType)
+	return c.JSON(status, document)
+}
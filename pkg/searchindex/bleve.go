@@ -0,0 +1,202 @@
+package searchindex
+
+import (
+	"errors"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	typeField    = "doc_type"
+	textPrefix   = "text."
+	kwPrefix     = "kw."
+	numPrefix    = "num."
+	openTimeout  = "1s"
+	defaultSize  = 10
+	defaultFacet = 10
+)
+
+// BleveIndex is an embedded index stored on disk. Bleve holds an exclusive
+// lock on the index directory, so only one process may open it at a time.
+type BleveIndex struct {
+	index  bleve.Index
+	logger *slog.Logger
+}
+
+// OpenBleveIndex opens the index at path, creating it when it does not exist.
+func OpenBleveIndex(path string, logger *slog.Logger) (*BleveIndex, error) {
+	index, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": openTimeout})
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		index, err = bleve.New(path, newMapping())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BleveIndex{index: index, logger: logger}, nil
+}
+
+// CreateBleveIndex creates an empty index at path, replacing any index
+// stored there.
+func CreateBleveIndex(path string, logger *slog.Logger) (*BleveIndex, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+
+	index, err := bleve.New(path, newMapping())
+	if err != nil {
+		return nil, err
+	}
+
+	return &BleveIndex{index: index, logger: logger}, nil
+}
+
+// newMapping indexes Document.Text under "text" with the standard analyzer,
+// Document.Keywords under "kw" unanalyzed and Document.Numbers under "num".
+func newMapping() mapping.IndexMapping {
+	textMapping := bleve.NewDocumentMapping()
+	textMapping.DefaultAnalyzer = standard.Name
+
+	kwMapping := bleve.NewDocumentMapping()
+	kwMapping.DefaultAnalyzer = keyword.Name
+
+	docMapping := bleve.NewDocumentMapping()
+	docMapping.AddFieldMappingsAt(typeField, bleve.NewKeywordFieldMapping())
+	docMapping.AddSubDocumentMapping("text", textMapping)
+	docMapping.AddSubDocumentMapping("kw", kwMapping)
+	docMapping.AddSubDocumentMapping("num", bleve.NewDocumentMapping())
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = docMapping
+	indexMapping.StoreDynamic = false
+	return indexMapping
+}
+
+func (b *BleveIndex) Index(docs ...*Document) error {
+	batch := b.index.NewBatch()
+	for _, doc := range docs {
+		err := batch.Index(documentId(doc.Type, doc.ID), map[string]interface{}{
+			typeField: doc.Type,
+			"text":    doc.Text,
+			"kw":      doc.Keywords,
+			"num":     doc.Numbers,
+		})
+		if err != nil {
+			b.logger.Error(err.Error())
+			return err
+		}
+	}
+
+	if err := b.index.Batch(batch); err != nil {
+		b.logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (b *BleveIndex) Delete(docType string, id string) error {
+	if err := b.index.Delete(documentId(docType, id)); err != nil {
+		b.logger.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+func (b *BleveIndex) Search(q *Query) (*Result, error) {
+	typeQuery := bleve.NewTermQuery(q.Type)
+	typeQuery.SetField(typeField)
+	conjuncts := []query.Query{typeQuery}
+
+	if q.Text != "" {
+		if len(q.TextFields) == 0 {
+			return nil, ErrNoTextFields
+		}
+
+		matches := make([]query.Query, 0, len(q.TextFields))
+		for field, boost := range q.TextFields {
+			match := bleve.NewMatchQuery(q.Text)
+			match.SetField(textPrefix + field)
+			match.SetOperator(query.MatchQueryOperatorAnd)
+			match.SetBoost(boost)
+			matches = append(matches, match)
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(matches...))
+	}
+
+	for field, values := range q.Terms {
+		terms := make([]query.Query, 0, len(values))
+		for _, value := range values {
+			term := bleve.NewTermQuery(value)
+			term.SetField(kwPrefix + field)
+			terms = append(terms, term)
+		}
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(terms...))
+	}
+
+	inclusive := true
+	for field, r := range q.Ranges {
+		numeric := bleve.NewNumericRangeInclusiveQuery(r.Min, r.Max, &inclusive, &inclusive)
+		numeric.SetField(numPrefix + field)
+		conjuncts = append(conjuncts, numeric)
+	}
+
+	size := q.Size
+	if size <= 0 {
+		size = defaultSize
+	}
+
+	request := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(conjuncts...), size, q.From, false)
+
+	facetSize := q.FacetSize
+	if facetSize <= 0 {
+		facetSize = defaultFacet
+	}
+	for _, field := range q.Facets {
+		request.AddFacet(field, bleve.NewFacetRequest(kwPrefix+field, facetSize))
+	}
+
+	response, err := b.index.Search(request)
+	if err != nil {
+		b.logger.Error(err.Error())
+		return nil, err
+	}
+
+	result := &Result{
+		Total:  response.Total,
+		Hits:   make([]*Hit, 0, len(response.Hits)),
+		Facets: make(map[string][]*FacetCount, len(response.Facets)),
+	}
+
+	for _, hit := range response.Hits {
+		result.Hits = append(result.Hits, &Hit{
+			ID:    strings.TrimPrefix(hit.ID, q.Type+"/"),
+			Score: hit.Score,
+		})
+	}
+
+	for field, facet := range response.Facets {
+		counts := make([]*FacetCount, 0)
+		if facet.Terms != nil {
+			for _, term := range facet.Terms.Terms() {
+				counts = append(counts, &FacetCount{Term: term.Term, Count: term.Count})
+			}
+		}
+		result.Facets[field] = counts
+	}
+
+	return result, nil
+}
+
+func (b *BleveIndex) Close() error {
+	return b.index.Close()
+}
+
+func documentId(docType string, id string) string {
+	return docType + "/" + id
+}
@@ -0,0 +1,151 @@
+package searchindex
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func newTestIndex(t *testing.T, docs ...*Document) *BleveIndex {
+	t.Helper()
+
+	index, err := CreateBleveIndex(filepath.Join(t.TempDir(), "index.bleve"), slog.Default())
+	if err != nil {
+		t.Fatalf("CreateBleveIndex: %v", err)
+	}
+	t.Cleanup(func() { _ = index.Close() })
+
+	if err := index.Index(docs...); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	return index
+}
+
+func TestBleveSearch(t *testing.T) {
+	index := newTestIndex(t,
+		&Document{ID: "1", Type: "product", Text: map[string]string{"name": "red shirt"}, Keywords: map[string][]string{"type": {"physical"}}},
+		&Document{ID: "2", Type: "product", Text: map[string]string{"name": "blue shirt"}, Keywords: map[string][]string{"type": {"digital"}}},
+		&Document{ID: "3", Type: "brand", Text: map[string]string{"name": "shirt co"}},
+	)
+
+	tests := []struct {
+		name    string
+		query   *Query
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "text",
+			query: &Query{Type: "product", Text: "shirt", TextFields: map[string]float64{"name": 1}, Size: 10},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "text and terms",
+			query: &Query{Type: "product", Text: "shirt", TextFields: map[string]float64{"name": 1}, Terms: map[string][]string{"type": {"digital"}}, Size: 10},
+			want:  []string{"2"},
+		},
+		{
+			name:  "no text",
+			query: &Query{Type: "brand", Size: 10},
+			want:  []string{"3"},
+		},
+		{
+			name:    "text without text fields",
+			query:   &Query{Type: "product", Text: "shirt", Size: 10},
+			wantErr: ErrNoTextFields,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := index.Search(tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Search() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			got := make([]string, 0, len(result.Hits))
+			for _, hit := range result.Hits {
+				got = append(got, hit.ID)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  []uint
+		batchSize int
+		want      int
+		wantIds   []string
+	}{
+		{name: "all exist", existing: []uint{1, 2, 3}, batchSize: 2, want: 0, wantIds: []string{"1", "2", "3"}},
+		{name: "some gone", existing: []uint{2}, batchSize: 2, want: 2, wantIds: []string{"2"}},
+		{name: "all gone", existing: nil, batchSize: 10, want: 3, wantIds: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := newTestIndex(t,
+				&Document{ID: "1", Type: "product", Text: map[string]string{"name": "a"}},
+				&Document{ID: "2", Type: "product", Text: map[string]string{"name": "b"}},
+				&Document{ID: "3", Type: "product", Text: map[string]string{"name": "c"}},
+				&Document{ID: "1", Type: "brand", Text: map[string]string{"name": "d"}},
+			)
+
+			exists := make(map[uint]bool)
+			for _, id := range tt.existing {
+				exists[id] = true
+			}
+
+			deleted, err := Prune(index, "product", tt.batchSize, func(ids []uint) ([]uint, error) {
+				found := make([]uint, 0, len(ids))
+				for _, id := range ids {
+					if exists[id] {
+						found = append(found, id)
+					}
+				}
+				return found, nil
+			})
+			if err != nil {
+				t.Fatalf("Prune() error = %v", err)
+			}
+			if deleted != tt.want {
+				t.Errorf("Prune() = %d, want %d", deleted, tt.want)
+			}
+
+			ids, err := IDs(index, "product")
+			if err != nil {
+				t.Fatalf("IDs() error = %v", err)
+			}
+			sort.Strings(ids)
+			if len(ids) != len(tt.wantIds) {
+				t.Fatalf("IDs() = %v, want %v", ids, tt.wantIds)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIds[i] {
+					t.Fatalf("IDs() = %v, want %v", ids, tt.wantIds)
+				}
+			}
+
+			brands, err := IDs(index, "brand")
+			if err != nil || len(brands) != 1 {
+				t.Errorf("IDs(brand) = %v, %v, want the brand kept", brands, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package searchindex
+
+import (
+	"ecommerce/pkg/apperror"
+	"errors"
+	"strconv"
+)
+
+var (
+	// ErrDisabled is returned by the features that need a search index when
+	// none is configured.
+	ErrDisabled = apperror.Unavailable("search_index_disabled", "the search index is disabled, set SEARCH_INDEX_PATH")
+
+	// ErrNoTextFields is returned by Search for a query with Text but no
+	// TextFields to match it against.
+	ErrNoTextFields = errors.New("searchindex: query text without text fields")
+)
+
+// Document is a searchable record. Text fields are analyzed for full text
+// queries, keyword fields are matched exactly and can be faceted on, number
+// fields can be filtered by range.
+type Document struct {
+	ID       string
+	Type     string
+	Text     map[string]string
+	Keywords map[string][]string
+	Numbers  map[string]float64
+}
+
+// Range bounds a number field, both ends inclusive; a nil end is open.
+type Range struct {
+	Min *float64
+	Max *float64
+}
+
+// Query searches the documents of one type. Text is matched against
+// TextFields, weighted by their boost, and requires at least one of them.
+// Terms keep documents having any of the values of every listed keyword
+// field. Facets lists the keyword fields to count values of.
+type Query struct {
+	Type       string
+	Text       string
+	TextFields map[string]float64
+	Terms      map[string][]string
+	Ranges     map[string]Range
+	Facets     []string
+	FacetSize  int
+	From       int
+	Size       int
+}
+
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+type FacetCount struct {
+	Term  string
+	Count int
+}
+
+type Result struct {
+	Total  uint64
+	Hits   []*Hit
+	Facets map[string][]*FacetCount
+}
+
+// IIndex keeps a search index of documents next to the database, which stays
+// the source of truth.
+type IIndex interface {
+	Index(docs ...*Document) error
+	Delete(docType string, id string) error
+	Search(query *Query) (*Result, error)
+	Close() error
+}
+
+// Enabled reports whether index is a real index rather than the NopIndex of
+// a service running without one.
+func Enabled(index IIndex) bool {
+	_, nop := index.(NopIndex)
+	return !nop
+}
+
+// IDs returns the ids of every indexed document of docType.
+func IDs(index IIndex, docType string) ([]string, error) {
+	count, err := index.Search(&Query{Type: docType, Size: 1})
+	if err != nil || count.Total == 0 {
+		return nil, err
+	}
+
+	result, err := index.Search(&Query{Type: docType, Size: int(count.Total)})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids, nil
+}
+
+// Prune deletes the documents of docType whose record is gone, e.g. deleted
+// while the index was not written. existing returns which of a batch of ids
+// still exist. It returns the number of deleted documents.
+func Prune(index IIndex, docType string, batchSize int, existing func(ids []uint) ([]uint, error)) (int, error) {
+	ids, err := IDs(index, docType)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for start := 0; start < len(ids); start += batchSize {
+		batch := ids[start:min(start+batchSize, len(ids))]
+		numeric := make([]uint, 0, len(batch))
+		for _, id := range batch {
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				numeric = append(numeric, uint(n))
+			}
+		}
+
+		found, err := existing(numeric)
+		if err != nil {
+			return deleted, err
+		}
+
+		keep := make(map[string]bool, len(found))
+		for _, id := range found {
+			keep[strconv.FormatUint(uint64(id), 10)] = true
+		}
+
+		for _, id := range batch {
+			if keep[id] {
+				continue
+			}
+			if err := index.Delete(docType, id); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
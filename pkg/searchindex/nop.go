@@ -0,0 +1,21 @@
+package searchindex
+
+// NopIndex is used when no search index is configured; it drops writes and
+// finds nothing.
+type NopIndex struct{}
+
+func (NopIndex) Index(docs ...*Document) error {
+	return nil
+}
+
+func (NopIndex) Delete(docType string, id string) error {
+	return nil
+}
+
+func (NopIndex) Search(query *Query) (*Result, error) {
+	return &Result{Hits: make([]*Hit, 0), Facets: make(map[string][]*FacetCount)}, nil
+}
+
+func (NopIndex) Close() error {
+	return nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "nested key", key: "products/1/manual.pdf"},
+		{name: "flat key", key: "export.csv"},
+		{name: "escaping key", key: "../outside", wantErr: true},
+		{name: "root key", key: ".", wantErr: true},
+		{name: "escaping nested key", key: "products/../../outside", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			written, err := storage.Put(tt.key, strings.NewReader("content"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Put(%q) error = %v, want error %v", tt.key, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if written != int64(len("content")) {
+				t.Errorf("Put(%q) = %d bytes, want %d", tt.key, written, len("content"))
+			}
+
+			file, err := storage.Open(tt.key)
+			if err != nil {
+				t.Fatalf("Open(%q) error = %v", tt.key, err)
+			}
+			content, err := io.ReadAll(file)
+			file.Close()
+			if err != nil || string(content) != "content" {
+				t.Errorf("Open(%q) read %q, %v, want content", tt.key, content, err)
+			}
+
+			if err := storage.Delete(tt.key); err != nil {
+				t.Fatalf("Delete(%q) error = %v", tt.key, err)
+			}
+			if _, err := storage.Open(tt.key); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Open(%q) after Delete error = %v, want %v", tt.key, err, ErrNotFound)
+			}
+			if err := storage.Delete(tt.key); err != nil {
+				t.Errorf("Delete(%q) of a missing object error = %v, want nil", tt.key, err)
+			}
+		})
+	}
+}
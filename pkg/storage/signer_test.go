@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer := NewURLSigner("secret", time.Hour)
+	expires, signature := signer.Sign("products/1/files/2")
+
+	if until := time.Until(expires); until <= 59*time.Minute || until > time.Hour {
+		t.Fatalf("Sign() expires in %s, want about an hour", until)
+	}
+
+	past := time.Now().Add(-time.Minute).Unix()
+	pastSignature := signer.signature("products/1/files/2", past)
+
+	tests := []struct {
+		name      string
+		signer    *URLSigner
+		resource  string
+		expires   int64
+		signature string
+		wantErr   error
+	}{
+		{name: "valid", signer: signer, resource: "products/1/files/2", expires: expires.Unix(), signature: signature},
+		{name: "other resource", signer: signer, resource: "products/1/files/3", expires: expires.Unix(), signature: signature, wantErr: ErrLinkSignature},
+		{name: "extended expiry", signer: signer, resource: "products/1/files/2", expires: expires.Unix() + 3600, signature: signature, wantErr: ErrLinkSignature},
+		{name: "tampered signature", signer: signer, resource: "products/1/files/2", expires: expires.Unix(), signature: signature[:len(signature)-1] + "0", wantErr: ErrLinkSignature},
+		{name: "other secret", signer: NewURLSigner("other", time.Hour), resource: "products/1/files/2", expires: expires.Unix(), signature: signature, wantErr: ErrLinkSignature},
+		{name: "expired", signer: signer, resource: "products/1/files/2", expires: past, signature: pastSignature, wantErr: ErrLinkExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.signer.Verify(tt.resource, tt.expires, tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify(%q, %d) error = %v, want %v", tt.resource, tt.expires, err, tt.wantErr)
+			}
+		})
+	}
+}
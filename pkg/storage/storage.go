@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"ecommerce/pkg/apperror"
+	"io"
+)
+
+var ErrNotFound = apperror.NotFound("stored_object_not_found", "stored object not found")
+
+// IStorage keeps binary objects under opaque keys.
+type IStorage interface {
+	Put(key string, reader io.Reader) (int64, error)
+	Open(key string) (io.ReadSeekCloser, error)
+	Delete(key string) error
+}
@@ -0,0 +1,93 @@
+package units
+
+import (
+	"fmt"
+	"math"
+)
+
+type Dimension string
+
+const (
+	Mass   Dimension = "mass"
+	Length Dimension = "length"
+	Volume Dimension = "volume"
+)
+
+// Unit converts between a supplier facing unit and the canonical base unit of
+// its dimension: grams for mass, centimeters for length and milliliters for
+// volume.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	// Factor is the number of base units in one of this unit.
+	Factor float64
+}
+
+var registry = map[string]Unit{
+	"g":  {Symbol: "g", Dimension: Mass, Factor: 1},
+	"kg": {Symbol: "kg", Dimension: Mass, Factor: 1000},
+	"lb": {Symbol: "lb", Dimension: Mass, Factor: 453.59237},
+	"oz": {Symbol: "oz", Dimension: Mass, Factor: 28.349523125},
+
+	"mm": {Symbol: "mm", Dimension: Length, Factor: 0.1},
+	"cm": {Symbol: "cm", Dimension: Length, Factor: 1},
+	"m":  {Symbol: "m", Dimension: Length, Factor: 100},
+	"in": {Symbol: "in", Dimension: Length, Factor: 2.54},
+
+	"ml": {Symbol: "ml", Dimension: Volume, Factor: 1},
+	"l":  {Symbol: "l", Dimension: Volume, Factor: 1000},
+}
+
+var baseUnits = map[Dimension]string{
+	Mass:   "g",
+	Length: "cm",
+	Volume: "ml",
+}
+
+// Lookup returns the unit registered under symbol.
+func Lookup(symbol string) (Unit, error) {
+	unit, ok := registry[symbol]
+	if !ok {
+		return Unit{}, fmt.Errorf("unknown unit %q", symbol)
+	}
+	return unit, nil
+}
+
+// Base returns the canonical unit values of the dimension are stored in.
+func Base(dimension Dimension) Unit {
+	return registry[baseUnits[dimension]]
+}
+
+// ToBase converts value expressed in symbol into the base unit of dimension.
+func ToBase(value float64, symbol string, dimension Dimension) (float64, error) {
+	unit, err := Lookup(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	if unit.Dimension != dimension {
+		return 0, fmt.Errorf("unit %q is not a %s unit", symbol, dimension)
+	}
+
+	return value * unit.Factor, nil
+}
+
+// FromBase converts a base unit value into symbol.
+func FromBase(value float64, symbol string, dimension Dimension) (float64, error) {
+	unit, err := Lookup(symbol)
+	if err != nil {
+		return 0, err
+	}
+
+	if unit.Dimension != dimension {
+		return 0, fmt.Errorf("unit %q is not a %s unit", symbol, dimension)
+	}
+
+	return value / unit.Factor, nil
+}
+
+// Round rounds value to the given number of decimal places.
+func Round(value float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(value*p) / p
+}
@@ -0,0 +1,58 @@
+package units
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value     float64
+		from, to  string
+		dimension Dimension
+		want      float64
+	}{
+		{value: 2.5, from: "kg", to: "g", dimension: Mass, want: 2500},
+		{value: 1, from: "lb", to: "kg", dimension: Mass, want: 0.454},
+		{value: 16, from: "oz", to: "lb", dimension: Mass, want: 1},
+		{value: 10, from: "in", to: "cm", dimension: Length, want: 25.4},
+		{value: 1250, from: "mm", to: "m", dimension: Length, want: 1.25},
+		{value: 750, from: "ml", to: "l", dimension: Volume, want: 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			base, err := ToBase(tt.value, tt.from, tt.dimension)
+			if err != nil {
+				t.Fatalf("ToBase() error = %v", err)
+			}
+			got, err := FromBase(base, tt.to, tt.dimension)
+			if err != nil {
+				t.Fatalf("FromBase() error = %v", err)
+			}
+			if got = Round(got, 3); got != tt.want {
+				t.Errorf("%v %s = %v %s, want %v", tt.value, tt.from, got, tt.to, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertRejectsUnit(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbol    string
+		dimension Dimension
+	}{
+		{name: "unknown", symbol: "stone", dimension: Mass},
+		{name: "length as mass", symbol: "cm", dimension: Mass},
+		{name: "volume as length", symbol: "l", dimension: Length},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ToBase(1, tt.symbol, tt.dimension); err == nil {
+				t.Errorf("ToBase(1, %q, %s) succeeded", tt.symbol, tt.dimension)
+			}
+			if _, err := FromBase(1, tt.symbol, tt.dimension); err == nil {
+				t.Errorf("FromBase(1, %q, %s) succeeded", tt.symbol, tt.dimension)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package validator
+
+import "github.com/go-playground/validator/v10"
+
+// IsValidGTIN reports whether code is a GTIN-8, GTIN-12 (UPC-A), GTIN-13
+// (EAN-13) or GTIN-14 with a correct check digit.
+func IsValidGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(code)-1; i++ {
+		c := code[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		// weights alternate 3,1,3,... counting from the digit next to the check digit
+		digit := int(c - '0')
+		if (len(code)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	check := code[len(code)-1]
+	if check < '0' || check > '9' {
+		return false
+	}
+
+	return (10-sum%10)%10 == int(check-'0')
+}
+
+// NormalizeGTIN left-pads a GTIN with zeros to its 14 digit form so that the
+// same item scanned as UPC-A or EAN-13 resolves to one value.
+func NormalizeGTIN(code string) string {
+	for len(code) < 14 {
+		code = "0" + code
+	}
+	return code
+}
+
+// validateGTIN accepts an empty value, which clears an optional barcode;
+// pair the tag with required where a barcode must be given.
+func validateGTIN(fl validator.FieldLevel) bool {
+	code := fl.Field().String()
+	return code == "" || IsValidGTIN(code)
+}
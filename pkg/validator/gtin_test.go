@@ -0,0 +1,95 @@
+package validator
+
+import "testing"
+
+func TestIsValidGTIN(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "GTIN-8", code: "96385074", want: true},
+		{name: "GTIN-12", code: "036000291452", want: true},
+		{name: "GTIN-13", code: "4006381333931", want: true},
+		{name: "GTIN-14", code: "10012345678902", want: true},
+		{name: "wrong check digit", code: "4006381333932", want: false},
+		{name: "unsupported length", code: "123456789", want: false},
+		{name: "letters", code: "40063813339A1", want: false},
+		{name: "letter check digit", code: "400638133393X", want: false},
+		{name: "empty", code: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidGTIN(tt.code); got != tt.want {
+				t.Errorf("IsValidGTIN(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeGTIN(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "96385074", want: "00000096385074"},
+		{code: "036000291452", want: "00036000291452"},
+		{code: "4006381333931", want: "04006381333931"},
+		{code: "10012345678902", want: "10012345678902"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeGTIN(tt.code); got != tt.want {
+			t.Errorf("NormalizeGTIN(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGTINTag(t *testing.T) {
+	type barcodes struct {
+		Optional *string `json:"optional" validate:"omitempty,gtin"`
+		Required string  `json:"required" validate:"required,gtin"`
+	}
+
+	empty, valid, invalid := "", "4006381333931", "4006381333932"
+	tests := []struct {
+		name  string
+		input barcodes
+		want  map[string]string
+	}{
+		{
+			name:  "empty optional barcode clears it",
+			input: barcodes{Optional: &empty, Required: valid},
+		},
+		{
+			name:  "missing optional barcode",
+			input: barcodes{Required: valid},
+		},
+		{
+			name:  "invalid optional barcode",
+			input: barcodes{Optional: &invalid, Required: valid},
+			want:  map[string]string{"optional": "Optional must be a valid GTIN-8, GTIN-12, GTIN-13 or GTIN-14 barcode"},
+		},
+		{
+			name:  "empty required barcode",
+			input: barcodes{Required: empty},
+			want:  map[string]string{"required": "Required is required"},
+		},
+	}
+
+	v := NewRequestValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := v.Fields(&tt.input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Fields() = %v, want %v", got, tt.want)
+			}
+			for field, message := range tt.want {
+				if got[field] != message {
+					t.Errorf("Fields()[%q] = %q, want %q", field, got[field], message)
+				}
+			}
+		})
+	}
+}
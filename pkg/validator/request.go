@@ -1,10 +1,9 @@
 package validator
 
 import (
+	"ecommerce/pkg/apperror"
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"github.com/labstack/echo/v4"
-	"net/http"
 	"reflect"
 	"strings"
 )
@@ -13,7 +12,26 @@ type RequestValidator struct {
 	Validator *validator.Validate
 }
 
+// NewRequestValidator returns a RequestValidator with the custom tags of this
+// service registered.
+func NewRequestValidator() *RequestValidator {
+	v := validator.New()
+	_ = v.RegisterValidation("gtin", validateGTIN)
+	return &RequestValidator{Validator: v}
+}
+
 func (cv *RequestValidator) Validate(i interface{}) error {
+	errors := cv.Fields(i)
+	if errors == nil {
+		return nil
+	}
+
+	return apperror.InvalidFields(errors)
+}
+
+// Fields validates i outside of a request and returns the messages keyed by
+// JSON field name, or nil when i is valid.
+func (cv *RequestValidator) Fields(i interface{}) map[string]string {
 	err := cv.Validator.Struct(i)
 	if err == nil {
 		return nil
@@ -29,10 +47,7 @@ func (cv *RequestValidator) Validate(i interface{}) error {
 		jsonField := getJSONFieldName(t, fe.StructField())
 		errors[jsonField] = getErrorMessage(jsonField, fe)
 	}
-
-	return echo.NewHTTPError(http.StatusBadRequest, map[string]interface{}{
-		"errors": errors,
-	})
+	return errors
 }
 
 // Get JSON tag from struct field
@@ -50,7 +65,7 @@ func getErrorMessage(field string, fe validator.FieldError) string {
 	fieldTitle := strings.Title(field)
 
 	switch fe.Tag() {
-	case "required", "required_if", "required_unless", "required_with", "required_without":
+	case "required", "required_if", "required_unless", "required_with", "required_without", "required_without_all":
 		return fmt.Sprintf("%s is required", fieldTitle)
 	case "min":
 		return fmt.Sprintf("%s must be at least %s characters", fieldTitle, fe.Param())
@@ -110,6 +125,8 @@ func getErrorMessage(field string, fe validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid %s", fieldTitle, fe.Tag())
 	case "unique":
 		return fmt.Sprintf("%s must be unique", fieldTitle)
+	case "gtin":
+		return fmt.Sprintf("%s must be a valid GTIN-8, GTIN-12, GTIN-13 or GTIN-14 barcode", fieldTitle)
 	default:
 		return fmt.Sprintf("%s is invalid", fieldTitle)
 	}